@@ -15,6 +15,7 @@ import (
 	"flamingo.me/flamingo/v3/framework/flamingo"
 	"flamingo.me/flamingo/v3/framework/opencensus"
 	"flamingo.me/flamingo/v3/framework/web"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"go.opencensus.io/plugin/ochttp"
 )
@@ -47,26 +48,28 @@ func (a *appmodule) Inject(
 func (a *appmodule) Configure(injector *dingo.Injector) {
 	flamingo.BindEventSubscriber(injector).ToInstance(a)
 
-	injector.BindMulti(new(cobra.Command)).ToProvider(func() *cobra.Command {
-		return serveProvider(a, a.logger)
+	injector.BindMulti(new(cobra.Command)).ToProvider(func(eventRouter flamingo.EventRouter) *cobra.Command {
+		return serveProvider(a, eventRouter, a.logger)
 	})
 }
 
-func serveProvider(a *appmodule, logger flamingo.Logger) *cobra.Command {
+func serveProvider(a *appmodule, eventRouter flamingo.EventRouter, logger flamingo.Logger) *cobra.Command {
 	serveCmd := &cobra.Command{
 		Use:   "serve",
 		Short: "Default serve command - starts on Port 3322",
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			logger.Info(fmt.Sprintf("Starting HTTP Server at %s .....", a.server.Addr))
 			a.server.Handler = &ochttp.Handler{IsPublicEndpoint: true, Handler: a.router.Handler(), GetStartOptions: a.configuredSampler.GetStartOptions()}
+
+			eventRouter.Dispatch(context.Background(), &flamingo.ServerStartEvent{})
+			defer eventRouter.Dispatch(context.Background(), &flamingo.ServerShutdownEvent{})
+
 			err := a.server.ListenAndServe()
-			if err != nil {
-				if err == http.ErrServerClosed {
-					logger.Error(err)
-				} else {
-					logger.Fatal("unexpected error in serving:", err)
-				}
+			if err == http.ErrServerClosed {
+				logger.Info(err)
+				return nil
 			}
+			return errors.Wrap(err, "unexpected error in serving")
 		},
 	}
 	serveCmd.Flags().StringVarP(&a.server.Addr, "addr", "a", ":3322", "addr on which flamingo runs")
@@ -104,13 +107,19 @@ func ChildAreas(areas ...*config.Area) func(config *appconfig) {
 	}
 }
 
+// ConfigProviders adds providers which are consulted for configuration after the config files
+func ConfigProviders(providers ...config.Provider) func(config *appconfig) {
+	return func(config *appconfig) {
+		config.providers = append(config.providers, providers...)
+	}
+}
+
 type appconfig struct {
 	configDir  string
 	childAreas []*config.Area
+	providers  []config.Provider
 }
 
-type eventRouterProvider func() flamingo.EventRouter
-
 // App is a simple app-runner for flamingo
 func App(modules []dingo.Module, options ...option) {
 	cfg := &appconfig{
@@ -130,13 +139,13 @@ func App(modules []dingo.Module, options ...option) {
 	}, root.Modules...)
 
 	root.Modules = append(root.Modules, app)
-	config.Load(root, cfg.configDir)
-
-	rootCmd := root.Injector.GetAnnotatedInstance(new(cobra.Command), "flamingo").(*cobra.Command)
-	root.Injector.GetInstance(new(eventRouterProvider)).(eventRouterProvider)().Dispatch(context.Background(), new(flamingo.StartupEvent))
+	if err := config.Load(root, cfg.configDir, cfg.providers...); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
 
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Println(err)
-		os.Exit(-1)
+	if err := cmd.Run(root.Injector); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(cmd.ExitCode(err))
 	}
 }
@@ -0,0 +1,97 @@
+package flamingo
+
+import (
+	"context"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"flamingo.me/dingo"
+	"flamingo.me/flamingo/v3/core/scheduler"
+	schedulerDomain "flamingo.me/flamingo/v3/core/scheduler/domain"
+	"flamingo.me/flamingo/v3/framework"
+	"flamingo.me/flamingo/v3/framework/cmd"
+	"flamingo.me/flamingo/v3/framework/config"
+	"flamingo.me/flamingo/v3/framework/flamingo"
+	"github.com/stretchr/testify/assert"
+)
+
+type (
+	testJob struct {
+		runs int32
+	}
+
+	testJobModule struct {
+		job *testJob
+	}
+)
+
+func (j *testJob) Name() string {
+	return "test"
+}
+
+func (j *testJob) Run(context.Context) error {
+	atomic.AddInt32(&j.runs, 1)
+	return nil
+}
+
+func (j *testJob) count() int {
+	return int(atomic.LoadInt32(&j.runs))
+}
+
+func (m *testJobModule) Configure(injector *dingo.Injector) {
+	injector.Bind(new(flamingo.Logger)).To(flamingo.NullLogger{})
+	injector.BindMulti(new(schedulerDomain.Job)).ToInstance(m.job)
+}
+
+func TestServe_Scheduler(t *testing.T) {
+	job := new(testJob)
+
+	root := config.NewArea("root", []dingo.Module{
+		new(framework.InitModule),
+		new(flamingo.SessionModule),
+		new(cmd.Module),
+		new(scheduler.Module),
+		&testJobModule{job: job},
+		new(appmodule),
+	})
+	root.LoadedConfig = make(config.Map)
+	assert.NoError(t, root.LoadedConfig.Add(config.Map{
+		"scheduler.jobs.test.interval":                float64(5),
+		"opencensus.tracing.sampler.whitelist":        config.Slice{},
+		"opencensus.tracing.sampler.blacklist":        config.Slice{},
+		"opencensus.tracing.sampler.allowParentTrace": true,
+	}))
+
+	injector, err := root.GetInitializedInjector()
+	if !assert.NoError(t, err) {
+		return
+	}
+	root.Injector = injector
+
+	done := make(chan error)
+	go func() {
+		done <- cmd.Run(injector, cmd.Args("serve", "--addr", "127.0.0.1:0"))
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for job.count() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	assert.NotZero(t, job.count(), "the scheduler is started by serve")
+
+	assert.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGTERM))
+
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("serve is not shut down on SIGTERM")
+	}
+
+	runs := job.count()
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, runs, job.count(), "the scheduler is stopped on shutdown")
+}
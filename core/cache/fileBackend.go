@@ -1,86 +1,308 @@
 package cache
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/gob"
+	"fmt"
 	"io/ioutil"
+	"math/rand"
 	"os"
 	"path/filepath"
 	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"flamingo.me/flamingo/v3/framework/flamingo"
 )
 
 type (
 	// FileBackend is a cache backend which saves the data in files
 	FileBackend struct {
 		baseDir string
+		codec   flamingo.Codec
+		mutex   sync.Mutex
+	}
+
+	// fileEntry keeps the meta data which is not exported by Meta, entries written as Entry are still read
+	fileEntry struct {
+		Meta           Meta
+		LifetimeUntil  time.Time
+		GracetimeUntil time.Time
+		Data           interface{}
 	}
 )
 
-const defaultBaseDir = "/tmp/cache"
+const (
+	defaultBaseDir = "/tmp/cache"
+
+	// fileTagsDir and fileNamespacesDir can not clash with escaped keys, as underscores are escaped
+	fileTagsDir       = "_tags"
+	fileNamespacesDir = "_namespaces"
+)
 
 var (
 	escape = regexp.MustCompile(`[^a-zA-Z0-9.]`)
 )
 
-// NewFileBackend returns a FileBackend operating in the given baseDir
+// NewFileBackend returns a FileBackend operating in the given baseDir, the entries are gob encoded
 func NewFileBackend(baseDir string) *FileBackend {
+	return NewFileBackendWithCodec(baseDir, nil)
+}
+
+// NewFileBackendWithCodec returns a FileBackend operating in the given baseDir, the entries are encoded with the codec, gob if it is nil
+func NewFileBackendWithCodec(baseDir string, codec flamingo.Codec) *FileBackend {
 	if baseDir == "" {
 		baseDir = defaultBaseDir
 	}
+	if codec == nil {
+		codec = flamingo.GobCodec{}
+	}
 
 	return &FileBackend{
 		baseDir: baseDir,
+		codec:   codec,
 	}
 }
 
+// Namespace returns a FileBackend operating in a sub directory, which can be flushed on its own
+func (fb *FileBackend) Namespace(namespace string) *FileBackend {
+	return NewFileBackendWithCodec(filepath.Join(fb.baseDir, fileNamespacesDir, escape.ReplaceAllString(namespace, ".")), fb.codec)
+}
+
 // Get reads a cache entry
 func (fb *FileBackend) Get(key string) (entry *Entry, found bool) {
-	key = escape.ReplaceAllString(key, ".")
+	return fb.read(escape.ReplaceAllString(key, "."))
+}
 
+// read the entry of an escaped key
+func (fb *FileBackend) read(key string) (entry *Entry, found bool) {
 	b, err := ioutil.ReadFile(filepath.Join(fb.baseDir, key))
 	if err != nil {
 		return nil, false
 	}
 
-	bb := bytes.NewBuffer(b)
-	d := gob.NewDecoder(bb)
-	entry = new(Entry)
-	err = d.Decode(&entry)
-	if err != nil {
+	stored := new(fileEntry)
+	if err := fb.codec.Decode(b, stored); err != nil {
 		return nil, false
 	}
 
-	return entry, true
+	stored.Meta.lifetime = stored.LifetimeUntil
+	stored.Meta.gracetime = stored.GracetimeUntil
+	return &Entry{Meta: stored.Meta, Data: stored.Data}, true
 }
 
-// Set writes a cache entry
+// Set writes a cache entry, and adds it to the index of its tags instead of the tags of a previous entry
 func (fb *FileBackend) Set(key string, entry *Entry) error {
 	key = escape.ReplaceAllString(key, ".")
 
 	gob.Register(entry)
 	gob.Register(entry.Data)
 
-	b := new(bytes.Buffer)
-	err := gob.NewEncoder(b).Encode(entry)
+	b, err := fb.codec.Encode(&fileEntry{
+		Meta:           entry.Meta,
+		LifetimeUntil:  entry.Meta.lifetime,
+		GracetimeUntil: entry.Meta.gracetime,
+		Data:           entry.Data,
+	})
 	if err != nil {
 		return err
 	}
 
-	ioutil.WriteFile(filepath.Join(fb.baseDir, key), b.Bytes(), os.ModePerm)
+	fb.mutex.Lock()
+	defer fb.mutex.Unlock()
+
+	if err := os.MkdirAll(fb.baseDir, os.ModePerm); err != nil {
+		return err
+	}
+	if err := fb.untag(key, entry.Meta.Tags...); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(filepath.Join(fb.baseDir, key), b, os.ModePerm); err != nil {
+		return err
+	}
+
+	for _, tag := range entry.Meta.Tags {
+		if err := fb.indexTag(tag, key); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
 
-// Purge deletes a cache entry
+// Purge deletes a cache entry, and removes it from the index of its tags
 func (fb *FileBackend) Purge(key string) error {
 	key = escape.ReplaceAllString(key, ".")
-	os.Remove(filepath.Join(fb.baseDir, key))
+
+	fb.mutex.Lock()
+	defer fb.mutex.Unlock()
+
+	if err := fb.untag(key); err != nil {
+		return err
+	}
+	if err := os.Remove(filepath.Join(fb.baseDir, key)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+// PurgeTags deletes all entries with one of the tags
+func (fb *FileBackend) PurgeTags(tags []string) error {
+	fb.mutex.Lock()
+	defer fb.mutex.Unlock()
+
+	for _, tag := range tags {
+		index := fb.tagIndex(tag)
+
+		keys, err := fb.readTagIndex(tag)
+		if err != nil {
+			return err
+		}
+
+		for _, key := range keys {
+			// the entry is removed from its other tags, so their indexes do not grow with purged keys
+			if err := fb.untag(key, tag); err != nil {
+				return err
+			}
+			if err := os.Remove(filepath.Join(fb.baseDir, key)); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+
+		if err := os.Remove(index); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Flush deletes all entries, tag indexes and namespaces of the backend.
+// The directory is moved away first, so concurrent requests do not see a partially flushed cache.
+func (fb *FileBackend) Flush() error {
+	fb.mutex.Lock()
+	defer fb.mutex.Unlock()
+
+	flushed := fmt.Sprintf("%s.flush-%d-%d", strings.TrimRight(fb.baseDir, string(filepath.Separator)), time.Now().UnixNano(), rand.Int())
+	if err := os.Rename(fb.baseDir, flushed); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	if err := os.MkdirAll(fb.baseDir, os.ModePerm); err != nil {
+		return err
+	}
+
+	return os.RemoveAll(flushed)
+}
+
+// indexTag adds the escaped key to the tag index if it is not indexed yet, the mutex must be held
+func (fb *FileBackend) indexTag(tag, key string) error {
+	if err := os.MkdirAll(filepath.Join(fb.baseDir, fileTagsDir), os.ModePerm); err != nil {
+		return err
+	}
+
+	keys, err := fb.readTagIndex(tag)
+	if err != nil {
+		return err
+	}
+	for _, indexed := range keys {
+		if indexed == key {
+			return nil
+		}
+	}
+
+	file, err := os.OpenFile(fb.tagIndex(tag), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = fmt.Fprintln(file, key)
+	return err
+}
+
+// untag removes the escaped key from the indexes of the tags of its current entry, except the kept tags.
+// The mutex must be held
+func (fb *FileBackend) untag(key string, keep ...string) error {
+	entry, found := fb.read(key)
+	if !found {
+		return nil
+	}
+
+tags:
+	for _, tag := range entry.Meta.Tags {
+		for _, kept := range keep {
+			if tag == kept {
+				continue tags
+			}
+		}
+
+		if err := fb.unindexTag(tag, key); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
 
-// PurgeTags is not supported by FileBackend and does nothing
-func (*FileBackend) PurgeTags(tags []string) error { return nil }
+// unindexTag removes the escaped key from the tag index, the index is rewritten without duplicates, the mutex must be held
+func (fb *FileBackend) unindexTag(tag, key string) error {
+	keys, err := fb.readTagIndex(tag)
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]struct{}, len(keys))
+	var index bytes.Buffer
+	for _, indexed := range keys {
+		if _, ok := seen[indexed]; ok || indexed == key {
+			continue
+		}
+		seen[indexed] = struct{}{}
+		fmt.Fprintln(&index, indexed)
+	}
+
+	if index.Len() == 0 {
+		if err := os.Remove(fb.tagIndex(tag)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	// the index is replaced at once, so a crash does not leave a partially written index
+	tmp := fmt.Sprintf("%s.%d-%d", fb.tagIndex(tag), time.Now().UnixNano(), rand.Int())
+	if err := ioutil.WriteFile(tmp, index.Bytes(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, fb.tagIndex(tag))
+}
+
+// readTagIndex returns the escaped keys of the tag index, the mutex must be held
+func (fb *FileBackend) readTagIndex(tag string) ([]string, error) {
+	file, err := os.Open(fb.tagIndex(tag))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
 
-// Flush is not supported by FileBackend and does nothing
-func (*FileBackend) Flush() error { return nil }
+	var keys []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		keys = append(keys, scanner.Text())
+	}
+
+	return keys, scanner.Err()
+}
+
+func (fb *FileBackend) tagIndex(tag string) string {
+	return filepath.Join(fb.baseDir, fileTagsDir, escape.ReplaceAllString(tag, "."))
+}
@@ -3,6 +3,7 @@ package cache_test
 import (
 	"bytes"
 	"encoding/gob"
+	"encoding/json"
 	"flag"
 	"io/ioutil"
 	"os"
@@ -11,6 +12,7 @@ import (
 	"testing"
 
 	"flamingo.me/flamingo/v3/core/cache"
+	"flamingo.me/flamingo/v3/framework/flamingo"
 )
 
 type (
@@ -206,3 +208,130 @@ func TestFileBackendPurge(t *testing.T) {
 		})
 	}
 }
+
+func TestFileBackendPurgeTags(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	f := cache.NewFileBackend(dir)
+	f.Set("product-1", &cache.Entry{Meta: cache.Meta{Tags: []string{"product-1", "category-1"}}, Data: "product 1"})
+	f.Set("product-2", &cache.Entry{Meta: cache.Meta{Tags: []string{"product-2", "category-1"}}, Data: "product 2"})
+	f.Set("product-3", &cache.Entry{Meta: cache.Meta{Tags: []string{"product-3", "category-2"}}, Data: "product 3"})
+
+	if err := f.PurgeTags([]string{"category-1", "unknown"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for key, want := range map[string]bool{"product-1": false, "product-2": false, "product-3": true} {
+		if _, found := f.Get(key); found != want {
+			t.Errorf("FileBackend.Get(%q) found = %v, want %v", key, found, want)
+		}
+	}
+}
+
+func TestFileBackendFlush(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	f := cache.NewFileBackend(dir)
+	products := f.Namespace("products")
+	f.Set("home", &cache.Entry{Data: "home"})
+	products.Set("product-1", &cache.Entry{Meta: cache.Meta{Tags: []string{"product-1"}}, Data: "product 1"})
+
+	if err := products.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if _, found := products.Get("product-1"); found {
+		t.Error("namespace was not flushed")
+	}
+	if _, found := f.Get("home"); !found {
+		t.Error("entries outside the namespace were flushed")
+	}
+
+	products.Set("product-2", &cache.Entry{Data: "product 2"})
+	if err := f.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if _, found := f.Get("home"); found {
+		t.Error("cache was not flushed")
+	}
+	if _, found := products.Get("product-2"); found {
+		t.Error("namespaces are flushed with their parent")
+	}
+
+	f.Set("home", &cache.Entry{Data: "home"})
+	if _, found := f.Get("home"); !found {
+		t.Error("cache can not be used after a flush")
+	}
+}
+
+func TestFileBackendTagIndex(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	index := func(tag string) string {
+		b, _ := ioutil.ReadFile(filepath.Join(dir, "_tags", tag))
+		return string(b)
+	}
+
+	f := cache.NewFileBackend(dir)
+	f.Set("product-1", &cache.Entry{Meta: cache.Meta{Tags: []string{"category-1"}}, Data: "product 1"})
+	f.Set("product-1", &cache.Entry{Meta: cache.Meta{Tags: []string{"category-1"}}, Data: "product 1"})
+	f.Set("product-2", &cache.Entry{Meta: cache.Meta{Tags: []string{"category-1", "category-2"}}, Data: "product 2"})
+	if got := index("category.1"); got != "product.1\nproduct.2\n" {
+		t.Errorf("overwritten entries are indexed once, got %q", got)
+	}
+
+	f.Set("product-1", &cache.Entry{Meta: cache.Meta{Tags: []string{"category-2"}}, Data: "product 1"})
+	if got := index("category.1"); got != "product.2\n" {
+		t.Errorf("entries are removed from their previous tags, got %q", got)
+	}
+
+	if err := f.Purge("product-1"); err != nil {
+		t.Fatal(err)
+	}
+	if got := index("category.2"); got != "product.2\n" {
+		t.Errorf("purged entries are removed from their tags, got %q", got)
+	}
+
+	if err := f.PurgeTags([]string{"category-2"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "_tags", "category.1")); !os.IsNotExist(err) {
+		t.Errorf("entries purged by a tag are removed from their other tags, err = %v", err)
+	}
+}
+
+func TestFileBackendCodec(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	f := cache.NewFileBackendWithCodec(dir, flamingo.JSONCodec{})
+	products := f.Namespace("products")
+	products.Set("product-1", &cache.Entry{Meta: cache.Meta{Tags: []string{"product-1"}}, Data: "product 1"})
+
+	entry, found := products.Get("product-1")
+	if !found {
+		t.Fatal("entry was not found")
+	}
+	if entry.Data != "product 1" || !reflect.DeepEqual(entry.Meta.Tags, []string{"product-1"}) {
+		t.Errorf("FileBackend.Get() = %#v", entry)
+	}
+
+	b, _ := ioutil.ReadFile(filepath.Join(dir, "_namespaces", "products", "product.1"))
+	if !json.Valid(b) {
+		t.Errorf("namespaces use the codec of their parent, got %q", b)
+	}
+}
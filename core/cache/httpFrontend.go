@@ -3,6 +3,7 @@ package cache
 import (
 	"bytes"
 	"context"
+	"encoding/gob"
 	"io"
 	"io/ioutil"
 	"net/http"
@@ -33,8 +34,27 @@ type (
 		orig *http.Response
 		body []byte
 	}
+
+	// cachedResponseWire is the gob encoded form of a cachedResponse, so it can be stored by the file and redis backends
+	cachedResponseWire struct {
+		Status           string
+		StatusCode       int
+		Proto            string
+		ProtoMajor       int
+		ProtoMinor       int
+		Header           http.Header
+		Trailer          http.Header
+		ContentLength    int64
+		TransferEncoding []string
+		Uncompressed     bool
+		Body             []byte
+	}
 )
 
+func init() {
+	gob.Register(cachedResponse{})
+}
+
 // Inject HTTPFrontend dependencies
 func (hf *HTTPFrontend) Inject(backend Backend, logger flamingo.Logger) {
 	hf.backend = backend
@@ -52,6 +72,52 @@ func GetHTTPFrontendCacheWithNullBackend() *HTTPFrontend {
 // Close the nopCloser to implement io.Closer
 func (nopCloser) Close() error { return nil }
 
+// GobEncode encodes the response without its body reader, request and tls state
+func (c cachedResponse) GobEncode() ([]byte, error) {
+	wire := cachedResponseWire{Body: c.body}
+	if c.orig != nil {
+		wire.Status = c.orig.Status
+		wire.StatusCode = c.orig.StatusCode
+		wire.Proto = c.orig.Proto
+		wire.ProtoMajor = c.orig.ProtoMajor
+		wire.ProtoMinor = c.orig.ProtoMinor
+		wire.Header = c.orig.Header
+		wire.Trailer = c.orig.Trailer
+		wire.ContentLength = c.orig.ContentLength
+		wire.TransferEncoding = c.orig.TransferEncoding
+		wire.Uncompressed = c.orig.Uncompressed
+	}
+
+	buf := new(bytes.Buffer)
+	if err := gob.NewEncoder(buf).Encode(wire); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// GobDecode decodes a response encoded by GobEncode
+func (c *cachedResponse) GobDecode(data []byte) error {
+	var wire cachedResponseWire
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&wire); err != nil {
+		return err
+	}
+
+	c.body = wire.Body
+	c.orig = &http.Response{
+		Status:           wire.Status,
+		StatusCode:       wire.StatusCode,
+		Proto:            wire.Proto,
+		ProtoMajor:       wire.ProtoMajor,
+		ProtoMinor:       wire.ProtoMinor,
+		Header:           wire.Header,
+		Trailer:          wire.Trailer,
+		ContentLength:    wire.ContentLength,
+		TransferEncoding: wire.TransferEncoding,
+		Uncompressed:     wire.Uncompressed,
+	}
+	return nil
+}
+
 func copyResponse(response cachedResponse, err error) (*http.Response, error) {
 	if err != nil {
 		return nil, err
@@ -1,6 +1,7 @@
 package cache
 
 import (
+	"sync"
 	"time"
 
 	lru "github.com/hashicorp/golang-lru"
@@ -10,7 +11,9 @@ const lurkerPeriod = 1 * time.Minute
 
 type (
 	inMemoryCache struct {
-		pool *lru.TwoQueueCache
+		pool  *lru.TwoQueueCache
+		mutex sync.Mutex
+		tags  map[string]map[string]struct{}
 	}
 
 	inMemoryCacheEntry struct {
@@ -19,12 +22,13 @@ type (
 	}
 )
 
-// NewInMemoryCache creates a new lru TwoQueue backed cache backend
+// NewInMemoryCache creates a new lru TwoQueue backed cache backend, the entries are stored by reference and not copied
 func NewInMemoryCache() Backend {
 	cache, _ := lru.New2Q(100)
 
 	m := &inMemoryCache{
 		pool: cache,
+		tags: make(map[string]map[string]struct{}),
 	}
 	go m.lurker()
 	return m
@@ -39,18 +43,33 @@ func (m *inMemoryCache) Get(key string) (*Entry, bool) {
 	return entry.(inMemoryCacheEntry).data.(*Entry), ok
 }
 
-// Set a cache entry with a key
+// Set a cache entry with a key, and add it to the index of its tags instead of the tags of a previous entry
 func (m *inMemoryCache) Set(key string, entry *Entry) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.untag(key)
 	m.pool.Add(key, inMemoryCacheEntry{
 		data:  entry,
 		valid: entry.Meta.gracetime,
 	})
 
+	for _, tag := range entry.Meta.Tags {
+		if m.tags[tag] == nil {
+			m.tags[tag] = make(map[string]struct{})
+		}
+		m.tags[tag][key] = struct{}{}
+	}
+
 	return nil
 }
 
-// Purge a cache key
+// Purge a cache key, and remove it from the index of its tags
 func (m *inMemoryCache) Purge(key string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.untag(key)
 	m.pool.Remove(key)
 
 	return nil
@@ -58,16 +77,63 @@ func (m *inMemoryCache) Purge(key string) error {
 
 // PurgeTags purges all entries with matching tags from the cache
 func (m *inMemoryCache) PurgeTags(tags []string) error {
-	panic("implement me")
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, tag := range tags {
+		for key := range m.tags[tag] {
+			m.untag(key)
+			m.pool.Remove(key)
+		}
+		delete(m.tags, tag)
+	}
+
+	return nil
 }
 
 // Flush purges all entries in the cache
 func (m *inMemoryCache) Flush() error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
 	m.pool.Purge()
+	m.tags = make(map[string]map[string]struct{})
 
 	return nil
 }
 
+// untag removes the key from the index of the tags of its current entry, the mutex must be held
+func (m *inMemoryCache) untag(key string) {
+	item, ok := m.pool.Peek(key)
+	if !ok {
+		return
+	}
+
+	for _, tag := range item.(inMemoryCacheEntry).data.(*Entry).Meta.Tags {
+		delete(m.tags[tag], key)
+		if len(m.tags[tag]) == 0 {
+			delete(m.tags, tag)
+		}
+	}
+}
+
+// cleanupTags removes keys from the tag index which are not cached anymore, e.g. because they have been evicted
+func (m *inMemoryCache) cleanupTags() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for tag, keys := range m.tags {
+		for key := range keys {
+			if !m.pool.Contains(key) {
+				delete(keys, key)
+			}
+		}
+		if len(keys) == 0 {
+			delete(m.tags, tag)
+		}
+	}
+}
+
 func (m *inMemoryCache) lurker() {
 	for range time.Tick(lurkerPeriod) {
 		for _, key := range m.pool.Keys() {
@@ -77,5 +143,6 @@ func (m *inMemoryCache) lurker() {
 				break
 			}
 		}
+		m.cleanupTags()
 	}
 }
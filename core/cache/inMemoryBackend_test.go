@@ -0,0 +1,45 @@
+package cache_test
+
+import (
+	"testing"
+
+	"flamingo.me/flamingo/v3/core/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInMemoryCache_PurgeTags(t *testing.T) {
+	backend := cache.NewInMemoryCache()
+	backend.Set("product-1", &cache.Entry{Meta: cache.Meta{Tags: []string{"product-1", "category-1"}}, Data: "product 1"})
+	backend.Set("product-2", &cache.Entry{Meta: cache.Meta{Tags: []string{"product-2", "category-1"}}, Data: "product 2"})
+	backend.Set("product-3", &cache.Entry{Meta: cache.Meta{Tags: []string{"product-3", "category-2"}}, Data: "product 3"})
+
+	assert.NoError(t, backend.PurgeTags([]string{"category-1", "unknown"}))
+
+	_, found := backend.Get("product-1")
+	assert.False(t, found)
+	_, found = backend.Get("product-2")
+	assert.False(t, found)
+	_, found = backend.Get("product-3")
+	assert.True(t, found)
+
+	assert.NoError(t, backend.Flush())
+	_, found = backend.Get("product-3")
+	assert.False(t, found)
+	assert.NoError(t, backend.PurgeTags([]string{"category-2"}), "the tag index is flushed")
+}
+
+func TestInMemoryCache_TagIndex(t *testing.T) {
+	backend := cache.NewInMemoryCache()
+	backend.Set("product-1", &cache.Entry{Meta: cache.Meta{Tags: []string{"category-1"}}, Data: "product 1"})
+	backend.Set("product-1", &cache.Entry{Meta: cache.Meta{Tags: []string{"category-2"}}, Data: "product 1"})
+
+	assert.NoError(t, backend.PurgeTags([]string{"category-1"}))
+	_, found := backend.Get("product-1")
+	assert.True(t, found, "entries are removed from their previous tags")
+
+	assert.NoError(t, backend.Purge("product-1"))
+	backend.Set("product-1", &cache.Entry{Data: "product 1"})
+	assert.NoError(t, backend.PurgeTags([]string{"category-2"}))
+	_, found = backend.Get("product-1")
+	assert.True(t, found, "purged entries are removed from their tags")
+}
@@ -0,0 +1,48 @@
+package cache
+
+import (
+	"flamingo.me/dingo"
+	"flamingo.me/flamingo/v3/framework/config"
+	"flamingo.me/flamingo/v3/framework/flamingo"
+	"flamingo.me/flamingo/v3/framework/systemendpoint"
+	"flamingo.me/flamingo/v3/framework/systemendpoint/domain"
+)
+
+type (
+	// Module registers the purge handler on the system endpoint.
+	// Cache backends are purgeable if they are registered by their name:
+	// injector.BindMap(new(cache.Backend), "products").ToInstance(backend)
+	Module struct {
+		purgePath string
+	}
+)
+
+// Inject dependencies
+func (m *Module) Inject(config *struct {
+	PurgePath string `inject:"config:cache.purgePath"`
+}) {
+	m.purgePath = config.PurgePath
+}
+
+// Configure DI
+func (m *Module) Configure(injector *dingo.Injector) {
+	injector.BindMap((*domain.Handler)(nil), m.purgePath).To(&PurgeHandler{})
+
+	flamingo.BindCodecs(injector)
+}
+
+// DefaultConfig for the module
+func (m *Module) DefaultConfig() config.Map {
+	return config.Map{
+		"cache.purgePath":   "/cache/purge",
+		"cache.codec":       "",
+		"cache.negativeTTL": 5.0,
+	}
+}
+
+// Depends on other modules
+func (m *Module) Depends() []dingo.Module {
+	return []dingo.Module{
+		new(systemendpoint.Module),
+	}
+}
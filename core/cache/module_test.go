@@ -0,0 +1,19 @@
+package cache_test
+
+import (
+	"testing"
+
+	"flamingo.me/dingo"
+	"flamingo.me/flamingo/v3/core/cache"
+	"flamingo.me/flamingo/v3/framework/config"
+)
+
+func TestModule_Configure(t *testing.T) {
+	cfgModule := &config.Module{
+		Map: new(cache.Module).DefaultConfig(),
+	}
+
+	if err := dingo.TryModule(cfgModule, new(cache.Module)); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,228 @@
+package cache
+
+import (
+	"context"
+	"encoding/gob"
+	"fmt"
+	"reflect"
+	"time"
+
+	"flamingo.me/flamingo/v3/framework/flamingo"
+	"github.com/golang/groupcache/singleflight"
+	"github.com/pkg/errors"
+	"go.opencensus.io/trace"
+)
+
+type (
+	// ObjectLoader loads an object for the ObjectFrontend, errors are cached for the negative ttl
+	ObjectLoader func(context.Context) (interface{}, *Meta, error)
+
+	// ObjectFrontend caches arbitrary objects, which are returned typed by decoding them into a given pointer.
+	// If a codec is set the objects are stored encoded, so serializing backends such as the file or redis backend
+	// do not need the types to be registered with gob. Without a codec the in memory backend returns the cached object itself,
+	// so pointers, maps and slices are shared by all callers and must not be changed.
+	ObjectFrontend struct {
+		singleflight.Group
+		backend     Backend
+		logger      flamingo.Logger
+		codec       flamingo.Codec
+		negativeTTL time.Duration
+	}
+
+	// objectEntry is stored in the backend, either the (encoded) data or the error of a failed load
+	objectEntry struct {
+		Data    interface{}
+		Encoded []byte
+		Error   string
+	}
+)
+
+func init() {
+	gob.Register(objectEntry{})
+}
+
+// NewObjectFrontend creates an ObjectFrontend without dependency injection, the codec is optional
+func NewObjectFrontend(backend Backend, codec flamingo.Codec, negativeTTL time.Duration) *ObjectFrontend {
+	return &ObjectFrontend{
+		backend:     backend,
+		logger:      flamingo.NullLogger{},
+		codec:       codec,
+		negativeTTL: negativeTTL,
+	}
+}
+
+// Inject ObjectFrontend dependencies
+func (of *ObjectFrontend) Inject(backend Backend, logger flamingo.Logger, cfg *struct {
+	Codec  string                    `inject:"config:cache.codec,optional"`
+	Codecs map[string]flamingo.Codec `inject:",optional"`
+	// float64 is used due to the injection as config from json - int is not possible on this
+	NegativeTTL float64 `inject:"config:cache.negativeTTL,optional"`
+}) *ObjectFrontend {
+	of.backend = backend
+	of.logger = logger.WithField(flamingo.LogKeyModule, "cache").WithField("category", "objectFrontendCache")
+	if cfg != nil {
+		of.negativeTTL = time.Duration(cfg.NegativeTTL * float64(time.Second))
+		if cfg.Codec != "" {
+			codec, ok := cfg.Codecs[cfg.Codec]
+			if !ok {
+				of.logger.Warn(fmt.Sprintf("cache codec %q is not registered, objects are stored unencoded", cfg.Codec))
+			}
+			of.codec = codec
+		}
+	}
+	return of
+}
+
+// Get an object and decode it into the value, which has to be a pointer to the type returned by the loader.
+// A cached loader error is returned until the negative ttl is over.
+func (of *ObjectFrontend) Get(ctx context.Context, key string, loader ObjectLoader, value interface{}) error {
+	if of.backend == nil {
+		return errors.New("NO backend in Cache")
+	}
+
+	ctx, span := trace.StartSpan(ctx, "flamingo/cache/objectFrontend/Get")
+	span.Annotate(nil, key)
+	defer span.End()
+
+	if entry, ok := of.backend.Get(key); ok {
+		if object, ok := entry.Data.(objectEntry); ok {
+			if entry.Meta.lifetime.After(time.Now()) {
+				of.logger.Debug("Serving from cache", key)
+				return of.decode(object, value)
+			}
+
+			if entry.Meta.gracetime.After(time.Now()) {
+				go of.load(context.Background(), key, loader)
+				of.logger.Debug("Gracetime! Serving from cache", key)
+				return of.decode(object, value)
+			}
+		}
+	}
+	of.logger.Debug("No cache entry for", key)
+
+	object, err := of.load(ctx, key, loader)
+	if err != nil {
+		return err
+	}
+	return of.decode(object, value)
+}
+
+func (of *ObjectFrontend) load(ctx context.Context, key string, loader ObjectLoader) (objectEntry, error) {
+	ctx, span := trace.StartSpan(ctx, "flamingo/cache/objectFrontend/load")
+	span.Annotate(nil, key)
+	defer span.End()
+
+	var spanContext trace.SpanContext
+	data, err := of.Do(key, func() (res interface{}, resultErr error) {
+		ctx, fetchRoutineSpan := trace.StartSpan(context.Background(), "flamingo/cache/objectFrontend/fetchRoutine")
+		fetchRoutineSpan.Annotate(nil, key)
+		defer fetchRoutineSpan.End()
+		spanContext = fetchRoutineSpan.SpanContext()
+
+		defer func() {
+			if err := recover(); err != nil {
+				if err2, ok := err.(error); ok {
+					resultErr = errors.WithStack(err2)
+				} else {
+					resultErr = errors.WithStack(errors.Errorf("ObjectFrontend.load exception: %#v", err))
+				}
+				of.storeError(key, resultErr)
+			}
+		}()
+
+		data, meta, err := loader(ctx)
+		if err != nil {
+			of.storeError(key, err)
+			return nil, err
+		}
+		if meta == nil {
+			meta = &Meta{
+				Lifetime:  30 * time.Second,
+				Gracetime: 10 * time.Minute,
+			}
+		}
+
+		object := objectEntry{Data: data}
+		if of.codec != nil {
+			encoded, err := of.codec.Encode(data)
+			if err != nil {
+				return nil, errors.Wrapf(err, "object %q can not be encoded", key)
+			}
+			object = objectEntry{Encoded: encoded}
+		}
+
+		of.logger.Debug("Store in Cache", key, meta)
+		of.backend.Set(key, &Entry{
+			Data: object,
+			Meta: Meta{
+				lifetime:  time.Now().Add(meta.Lifetime),
+				gracetime: time.Now().Add(meta.Lifetime + meta.Gracetime),
+				Tags:      meta.Tags,
+			},
+		})
+
+		return object, nil
+	})
+
+	span.AddAttributes(trace.StringAttribute("parenttrace", spanContext.TraceID.String()))
+	span.AddAttributes(trace.StringAttribute("parentspan", spanContext.SpanID.String()))
+
+	if err != nil {
+		return objectEntry{}, err
+	}
+	return data.(objectEntry), nil
+}
+
+// storeError caches the error for the negative ttl, a stale object within its gracetime is kept instead
+func (of *ObjectFrontend) storeError(key string, err error) {
+	if of.negativeTTL <= 0 {
+		return
+	}
+
+	if entry, ok := of.backend.Get(key); ok {
+		if object, ok := entry.Data.(objectEntry); ok && object.Error == "" && entry.Meta.gracetime.After(time.Now()) {
+			return
+		}
+	}
+
+	of.logger.Debug("Store error in Cache", key, err)
+	of.backend.Set(key, &Entry{
+		Data: objectEntry{Error: err.Error()},
+		Meta: Meta{
+			lifetime:  time.Now().Add(of.negativeTTL),
+			gracetime: time.Now().Add(of.negativeTTL),
+		},
+	})
+}
+
+func (of *ObjectFrontend) decode(object objectEntry, value interface{}) error {
+	if object.Error != "" {
+		return errors.New(object.Error)
+	}
+
+	if object.Encoded != nil {
+		codec := of.codec
+		if codec == nil {
+			return errors.New("cached object is encoded, but no codec is set")
+		}
+		return codec.Decode(object.Encoded, value)
+	}
+
+	target := reflect.ValueOf(value)
+	if target.Kind() != reflect.Ptr || target.IsNil() {
+		return errors.Errorf("cached object can not be stored in non-pointer %T", value)
+	}
+
+	if object.Data == nil {
+		target.Elem().Set(reflect.Zero(target.Elem().Type()))
+		return nil
+	}
+
+	data := reflect.ValueOf(object.Data)
+	if !data.Type().AssignableTo(target.Elem().Type()) {
+		return errors.Errorf("cached %T can not be assigned to %T", object.Data, value)
+	}
+	target.Elem().Set(data)
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"flamingo.me/flamingo/v3/core/cache"
+	"flamingo.me/flamingo/v3/framework/flamingo"
+	"github.com/stretchr/testify/assert"
+)
+
+type testProduct struct {
+	ID   string
+	Name string
+}
+
+func TestObjectFrontend_Get(t *testing.T) {
+	for name, frontend := range map[string]*cache.ObjectFrontend{
+		"unencoded": cache.NewObjectFrontend(cache.NewInMemoryCache(), nil, 0),
+		"gob":       cache.NewObjectFrontend(cache.NewInMemoryCache(), flamingo.GobCodec{}, 0),
+		"json":      cache.NewObjectFrontend(cache.NewInMemoryCache(), flamingo.JSONCodec{}, 0),
+	} {
+		t.Run(name, func(t *testing.T) {
+			loads := 0
+			loader := func(ctx context.Context) (interface{}, *cache.Meta, error) {
+				loads++
+				return &testProduct{ID: "1", Name: "product"}, &cache.Meta{Lifetime: time.Minute}, nil
+			}
+
+			for i := 0; i < 2; i++ {
+				var product *testProduct
+				assert.NoError(t, frontend.Get(context.Background(), "product-1", loader, &product))
+				assert.Equal(t, &testProduct{ID: "1", Name: "product"}, product)
+			}
+			assert.Equal(t, 1, loads)
+
+			var wrong string
+			assert.Error(t, frontend.Get(context.Background(), "product-1", loader, &wrong))
+		})
+	}
+}
+
+func TestObjectFrontend_FileBackend(t *testing.T) {
+	dir, err := ioutil.TempDir("", "objectfrontend")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	loads := 0
+	loader := func(ctx context.Context) (interface{}, *cache.Meta, error) {
+		loads++
+		return &testProduct{ID: "1", Name: "product"}, &cache.Meta{Lifetime: time.Minute}, nil
+	}
+
+	frontend := cache.NewObjectFrontend(cache.NewFileBackend(dir), flamingo.GobCodec{}, 0)
+	for i := 0; i < 2; i++ {
+		var product *testProduct
+		assert.NoError(t, frontend.Get(context.Background(), "product-1", loader, &product))
+		assert.Equal(t, &testProduct{ID: "1", Name: "product"}, product)
+	}
+	assert.Equal(t, 1, loads, "the lifetime must be kept by the file backend")
+}
+
+func TestObjectFrontend_NegativeTTL(t *testing.T) {
+	loads := 0
+	loader := func(ctx context.Context) (interface{}, *cache.Meta, error) {
+		loads++
+		return nil, nil, errors.New("not available")
+	}
+
+	frontend := cache.NewObjectFrontend(cache.NewInMemoryCache(), nil, time.Minute)
+	var product *testProduct
+	assert.EqualError(t, frontend.Get(context.Background(), "product-1", loader, &product), "not available")
+	assert.EqualError(t, frontend.Get(context.Background(), "product-1", loader, &product), "not available")
+	assert.Equal(t, 1, loads, "the error is cached")
+
+	frontend = cache.NewObjectFrontend(cache.NewInMemoryCache(), nil, 0)
+	loads = 0
+	assert.Error(t, frontend.Get(context.Background(), "product-1", loader, &product))
+	assert.Error(t, frontend.Get(context.Background(), "product-1", loader, &product))
+	assert.Equal(t, 2, loads, "errors are not cached without a negative ttl")
+}
+
+func TestObjectFrontend_NegativeTTLKeepsStaleObject(t *testing.T) {
+	backend := cache.NewInMemoryCache()
+	frontend := cache.NewObjectFrontend(backend, nil, time.Minute)
+
+	var product *testProduct
+	assert.NoError(t, frontend.Get(context.Background(), "product-1", func(ctx context.Context) (interface{}, *cache.Meta, error) {
+		return &testProduct{ID: "1"}, &cache.Meta{Lifetime: time.Nanosecond, Gracetime: time.Minute}, nil
+	}, &product))
+	time.Sleep(time.Millisecond)
+
+	failed := make(chan struct{})
+	assert.NoError(t, frontend.Get(context.Background(), "product-1", func(ctx context.Context) (interface{}, *cache.Meta, error) {
+		defer close(failed)
+		return nil, nil, errors.New("not available")
+	}, &product), "the stale object is served within the gracetime")
+	<-failed
+	time.Sleep(10 * time.Millisecond)
+
+	product = nil
+	assert.NoError(t, frontend.Get(context.Background(), "product-1", func(ctx context.Context) (interface{}, *cache.Meta, error) {
+		return nil, nil, errors.New("not available")
+	}, &product), "the failed background load does not replace the stale object")
+	assert.Equal(t, &testProduct{ID: "1"}, product)
+}
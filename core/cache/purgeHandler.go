@@ -0,0 +1,72 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"flamingo.me/flamingo/v3/framework/flamingo"
+)
+
+type (
+	// PurgeHandler purges the entries with the given tags from the registered cache backends, e.g. for a CMS:
+	// /cache/purge?tag=product-1&tag=category-2, optionally limited to some backends with &cache=products
+	PurgeHandler struct {
+		backends map[string]Backend
+		logger   flamingo.Logger
+	}
+
+	purgeResult struct {
+		Caches []string `json:"caches"`
+		Tags   []string `json:"tags"`
+	}
+)
+
+// Inject dependencies
+func (h *PurgeHandler) Inject(logger flamingo.Logger, cfg *struct {
+	Backends map[string]Backend `inject:",optional"`
+}) *PurgeHandler {
+	h.logger = logger.WithField(flamingo.LogKeyModule, "cache")
+	if cfg != nil {
+		h.backends = cfg.Backends
+	}
+	return h
+}
+
+// ServeHTTP purges the tags
+func (h *PurgeHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	tags := req.URL.Query()["tag"]
+	if len(tags) == 0 {
+		http.Error(rw, "missing tag parameter", http.StatusBadRequest)
+		return
+	}
+
+	caches := req.URL.Query()["cache"]
+	if len(caches) == 0 {
+		for name := range h.backends {
+			caches = append(caches, name)
+		}
+		sort.Strings(caches)
+	}
+
+	for _, name := range caches {
+		if _, ok := h.backends[name]; !ok {
+			http.Error(rw, fmt.Sprintf("cache %q is not registered", name), http.StatusNotFound)
+			return
+		}
+	}
+
+	for _, name := range caches {
+		if err := h.backends[name].PurgeTags(tags); err != nil {
+			h.logger.Error(fmt.Sprintf("purging tags %v of cache %q failed: %v", tags, name, err))
+			http.Error(rw, fmt.Sprintf("purging cache %q failed", name), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	h.logger.Info(fmt.Sprintf("purged tags %v of caches %v", tags, caches))
+
+	rw.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(rw).Encode(purgeResult{Caches: caches, Tags: tags})
+}
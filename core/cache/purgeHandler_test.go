@@ -0,0 +1,47 @@
+package cache_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"flamingo.me/flamingo/v3/core/cache"
+	"flamingo.me/flamingo/v3/framework/flamingo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPurgeHandler(t *testing.T) {
+	products, pages := cache.NewInMemoryCache(), cache.NewInMemoryCache()
+	handler := new(cache.PurgeHandler).Inject(new(flamingo.NullLogger), &struct {
+		Backends map[string]cache.Backend `inject:",optional"`
+	}{Backends: map[string]cache.Backend{"products": products, "pages": pages}})
+
+	set := func() {
+		products.Set("product-1", &cache.Entry{Meta: cache.Meta{Tags: []string{"product-1"}}, Data: "product 1"})
+		pages.Set("home", &cache.Entry{Meta: cache.Meta{Tags: []string{"product-1"}}, Data: "home"})
+	}
+	purge := func(query string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/cache/purge"+query, nil))
+		return recorder
+	}
+
+	set()
+	recorder := purge("?tag=product-1")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, `{"caches": ["pages", "products"], "tags": ["product-1"]}`, recorder.Body.String())
+	_, found := products.Get("product-1")
+	assert.False(t, found)
+	_, found = pages.Get("home")
+	assert.False(t, found)
+
+	set()
+	assert.Equal(t, http.StatusOK, purge("?tag=product-1&cache=products").Code)
+	_, found = products.Get("product-1")
+	assert.False(t, found)
+	_, found = pages.Get("home")
+	assert.True(t, found, "only the given caches are purged")
+
+	assert.Equal(t, http.StatusBadRequest, purge("").Code)
+	assert.Equal(t, http.StatusNotFound, purge("?tag=product-1&cache=unknown").Code)
+}
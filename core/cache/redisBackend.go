@@ -0,0 +1,280 @@
+package cache
+
+import (
+	"bytes"
+	"time"
+
+	"flamingo.me/flamingo/v3/framework/flamingo"
+	"github.com/gomodule/redigo/redis"
+	"github.com/pkg/errors"
+)
+
+type (
+	// RedisBackend is a cache backend shared across all instances using the same redis.
+	// Entries expire after their gracetime, and the keys of each tag are kept in a set, so tags can be purged efficiently.
+	// The tags of each entry are kept in a set as well, so the entry is removed from its tag sets when it is purged or replaced.
+	RedisBackend struct {
+		pool   *redis.Pool
+		prefix string
+		codec  flamingo.Codec
+	}
+
+	// redisEntry keeps the meta data which is not exported by Meta
+	redisEntry struct {
+		Tags                []string
+		Lifetime, Gracetime time.Duration
+		LifetimeUntil       time.Time
+		GracetimeUntil      time.Time
+		Data                interface{}
+	}
+)
+
+var (
+	_ Backend = new(RedisBackend)
+
+	// redisSetScript stores the entry and its tags, removes it from the tag sets of a previous entry,
+	// and adds it to the tag sets, which expire with their last entry
+	redisSetScript = redis.NewScript(-1, `
+local ttl = tonumber(ARGV[2])
+for _, tag in ipairs(redis.call("smembers", KEYS[2])) do
+	redis.call("srem", tag, KEYS[1])
+end
+redis.call("del", KEYS[2])
+if ttl > 0 then
+	redis.call("set", KEYS[1], ARGV[1], "px", ttl)
+else
+	redis.call("set", KEYS[1], ARGV[1])
+end
+for i = 3, #KEYS do
+	redis.call("sadd", KEYS[2], KEYS[i])
+	local existed = redis.call("exists", KEYS[i])
+	redis.call("sadd", KEYS[i], KEYS[1])
+	if ttl == 0 then
+		redis.call("persist", KEYS[i])
+	elseif existed == 0 then
+		redis.call("pexpire", KEYS[i], ttl)
+	else
+		local current = redis.call("pttl", KEYS[i])
+		if current >= 0 and current < ttl then
+			redis.call("pexpire", KEYS[i], ttl)
+		end
+	end
+end
+if ttl > 0 and #KEYS > 2 then
+	redis.call("pexpire", KEYS[2], ttl)
+end
+return 1`)
+
+	// redisPurgeScript deletes the entry and removes it from its tag sets
+	redisPurgeScript = redis.NewScript(2, `
+for _, tag in ipairs(redis.call("smembers", KEYS[2])) do
+	redis.call("srem", tag, KEYS[1])
+end
+redis.call("del", KEYS[1], KEYS[2])
+return 1`)
+
+	// redisPurgeTagsScript deletes all entries of the tags, removes them from their other tag sets and deletes the tag sets,
+	// the tags of an entry are kept under the entry key with the entry tags prefix ARGV[2] instead of the entry prefix ARGV[1]
+	redisPurgeTagsScript = redis.NewScript(-1, `
+for i = 1, #KEYS do
+	for _, key in ipairs(redis.call("smembers", KEYS[i])) do
+		local tagsKey = ARGV[2] .. string.sub(key, #ARGV[1] + 1)
+		for _, tag in ipairs(redis.call("smembers", tagsKey)) do
+			if tag ~= KEYS[i] then
+				redis.call("srem", tag, key)
+			end
+		end
+		redis.call("del", key, tagsKey)
+	end
+	redis.call("del", KEYS[i])
+end
+return 1`)
+)
+
+// NewRedisBackend creates a redis cache backend, the connection is configured like the redis session backend.
+// All keys are prefixed, so multiple caches can share the same redis. The entries are encoded with the codec, gob if it is nil.
+func NewRedisBackend(host, password string, idleConnections int, prefix string, codec flamingo.Codec) *RedisBackend {
+	return NewRedisBackendWithPool(&redis.Pool{
+		MaxIdle:     idleConnections,
+		IdleTimeout: 240 * time.Second,
+		Dial: func() (redis.Conn, error) {
+			return redis.Dial("tcp", host, redis.DialPassword(password))
+		},
+	}, prefix, codec)
+}
+
+// NewRedisBackendWithPool creates a redis cache backend using an existing pool, the entries are encoded with the codec, gob if it is nil
+func NewRedisBackendWithPool(pool *redis.Pool, prefix string, codec flamingo.Codec) *RedisBackend {
+	if codec == nil {
+		codec = flamingo.GobCodec{}
+	}
+
+	return &RedisBackend{
+		pool:   pool,
+		prefix: prefix,
+		codec:  codec,
+	}
+}
+
+// Get a cache entry, with gob the types of the cached data have to be registered with gob.Register
+func (rb *RedisBackend) Get(key string) (entry *Entry, found bool) {
+	conn := rb.pool.Get()
+	defer conn.Close()
+
+	data, err := redis.Bytes(conn.Do("GET", rb.entryKey(key)))
+	if err != nil {
+		return nil, false
+	}
+
+	stored := new(redisEntry)
+	if err := rb.codec.Decode(data, stored); err != nil {
+		return nil, false
+	}
+
+	return &Entry{
+		Meta: Meta{
+			Tags:      stored.Tags,
+			Lifetime:  stored.Lifetime,
+			Gracetime: stored.Gracetime,
+			lifetime:  stored.LifetimeUntil,
+			gracetime: stored.GracetimeUntil,
+		},
+		Data: stored.Data,
+	}, true
+}
+
+// Set a cache entry, it expires after the gracetime
+func (rb *RedisBackend) Set(key string, entry *Entry) error {
+	data, err := rb.codec.Encode(&redisEntry{
+		Tags:           entry.Meta.Tags,
+		Lifetime:       entry.Meta.Lifetime,
+		Gracetime:      entry.Meta.Gracetime,
+		LifetimeUntil:  entry.Meta.lifetime,
+		GracetimeUntil: entry.Meta.gracetime,
+		Data:           entry.Data,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "redis cache: entry %q can not be encoded", key)
+	}
+
+	keys := redis.Args{}.Add(rb.entryKey(key), rb.entryTagsKey(key))
+	for _, tag := range entry.Meta.Tags {
+		keys = keys.Add(rb.tagKey(tag))
+	}
+	args := redis.Args{}.Add(len(keys)).AddFlat(keys).Add(data, int64(redisTTL(entry.Meta)/time.Millisecond))
+
+	conn := rb.pool.Get()
+	defer conn.Close()
+
+	if _, err := redisSetScript.Do(conn, args...); err != nil {
+		return errors.Wrap(err, "redis cache")
+	}
+
+	return nil
+}
+
+// Purge a cache entry, and remove it from its tag sets
+func (rb *RedisBackend) Purge(key string) error {
+	conn := rb.pool.Get()
+	defer conn.Close()
+
+	if _, err := redisPurgeScript.Do(conn, rb.entryKey(key), rb.entryTagsKey(key)); err != nil {
+		return errors.Wrap(err, "redis cache")
+	}
+
+	return nil
+}
+
+// PurgeTags purges all entries with one of the tags
+func (rb *RedisBackend) PurgeTags(tags []string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	keys := redis.Args{}
+	for _, tag := range tags {
+		keys = keys.Add(rb.tagKey(tag))
+	}
+
+	conn := rb.pool.Get()
+	defer conn.Close()
+
+	args := redis.Args{}.Add(len(keys)).AddFlat(keys).Add(rb.entryKey(""), rb.entryTagsKey(""))
+	if _, err := redisPurgeTagsScript.Do(conn, args...); err != nil {
+		return errors.Wrap(err, "redis cache")
+	}
+
+	return nil
+}
+
+// Flush deletes all entries and tags of the backend
+func (rb *RedisBackend) Flush() error {
+	conn := rb.pool.Get()
+	defer conn.Close()
+
+	for _, prefix := range []string{rb.entryKey(""), rb.entryTagsKey(""), rb.tagKey("")} {
+		pattern := redisPattern(prefix) + "*"
+		cursor := "0"
+		for {
+			reply, err := redis.Values(conn.Do("SCAN", cursor, "MATCH", pattern, "COUNT", 1000))
+			if err != nil {
+				return errors.Wrap(err, "redis cache")
+			}
+
+			var keys []string
+			if _, err := redis.Scan(reply, &cursor, &keys); err != nil {
+				return errors.Wrap(err, "redis cache")
+			}
+
+			if len(keys) > 0 {
+				if _, err := conn.Do("DEL", redis.Args{}.AddFlat(keys)...); err != nil {
+					return errors.Wrap(err, "redis cache")
+				}
+			}
+
+			if cursor == "0" {
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+func (rb *RedisBackend) entryKey(key string) string {
+	return rb.prefix + "entry:" + key
+}
+
+func (rb *RedisBackend) entryTagsKey(key string) string {
+	return rb.prefix + "entrytags:" + key
+}
+
+func (rb *RedisBackend) tagKey(tag string) string {
+	return rb.prefix + "tag:" + tag
+}
+
+// redisTTL is the remaining time until the end of the gracetime, 0 if the entry does not expire
+func redisTTL(meta Meta) time.Duration {
+	ttl := meta.Lifetime + meta.Gracetime
+	if !meta.gracetime.IsZero() {
+		ttl = time.Until(meta.gracetime)
+		if ttl < time.Millisecond {
+			// already expired entries are kept for a moment, a ttl of 0 would keep them forever
+			ttl = time.Millisecond
+		}
+	}
+	return ttl
+}
+
+// redisPattern escapes the glob characters of the prefix
+func redisPattern(prefix string) string {
+	var pattern bytes.Buffer
+	for _, r := range prefix {
+		switch r {
+		case '*', '?', '[', ']', '\\':
+			pattern.WriteRune('\\')
+		}
+		pattern.WriteRune(r)
+	}
+	return pattern.String()
+}
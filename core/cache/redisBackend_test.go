@@ -0,0 +1,186 @@
+package cache_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"flamingo.me/flamingo/v3/core/cache"
+	"flamingo.me/flamingo/v3/framework/flamingo"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gomodule/redigo/redis"
+	"github.com/stretchr/testify/assert"
+)
+
+var _ cache.Backend = new(cache.RedisBackend)
+
+func newTestRedisBackend(t *testing.T) (*cache.RedisBackend, *miniredis.Miniredis) {
+	return newTestRedisBackendWithCodec(t, nil)
+}
+
+func newTestRedisBackendWithCodec(t *testing.T, codec flamingo.Codec) (*cache.RedisBackend, *miniredis.Miniredis) {
+	t.Helper()
+
+	server, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(server.Close)
+
+	return cache.NewRedisBackendWithPool(&redis.Pool{Dial: func() (redis.Conn, error) {
+		return redis.Dial("tcp", server.Addr())
+	}}, "test:", codec), server
+}
+
+func TestRedisBackend(t *testing.T) {
+	t.Run("set and get", func(t *testing.T) {
+		backend, server := newTestRedisBackend(t)
+
+		assert.NoError(t, backend.Set("key", &cache.Entry{Data: "value", Meta: cache.Meta{Lifetime: time.Minute, Gracetime: time.Minute, Tags: []string{"tag"}}}))
+
+		entry, found := backend.Get("key")
+		if assert.True(t, found) {
+			assert.Equal(t, "value", entry.Data)
+			assert.Equal(t, []string{"tag"}, entry.Meta.Tags)
+		}
+		assert.Equal(t, 2*time.Minute, server.TTL("test:entry:key"), "entries expire after their gracetime")
+
+		_, found = backend.Get("unknown")
+		assert.False(t, found)
+
+		server.FastForward(2 * time.Minute)
+		_, found = backend.Get("key")
+		assert.False(t, found, "expired entries are removed")
+	})
+
+	t.Run("tag sets expire with their last entry", func(t *testing.T) {
+		backend, server := newTestRedisBackend(t)
+
+		assert.NoError(t, backend.Set("long", &cache.Entry{Data: "value", Meta: cache.Meta{Lifetime: time.Hour, Tags: []string{"tag"}}}))
+		assert.NoError(t, backend.Set("short", &cache.Entry{Data: "value", Meta: cache.Meta{Lifetime: time.Minute, Tags: []string{"tag"}}}))
+		assert.Equal(t, time.Hour, server.TTL("test:tag:tag"), "a shorter entry does not shorten the tag set")
+
+		assert.NoError(t, backend.Set("forever", &cache.Entry{Data: "value", Meta: cache.Meta{Tags: []string{"tag"}}}))
+		assert.Zero(t, server.TTL("test:tag:tag"), "entries without ttl keep the tag set forever")
+
+		server.FastForward(2 * time.Hour)
+		members, err := server.Members("test:tag:tag")
+		assert.NoError(t, err)
+		assert.Len(t, members, 3)
+	})
+
+	t.Run("purge removes the entry from its tag sets", func(t *testing.T) {
+		backend, server := newTestRedisBackend(t)
+
+		assert.NoError(t, backend.Set("key", &cache.Entry{Data: "value", Meta: cache.Meta{Lifetime: time.Minute, Tags: []string{"first", "second"}}}))
+		assert.NoError(t, backend.Set("other", &cache.Entry{Data: "value", Meta: cache.Meta{Lifetime: time.Minute, Tags: []string{"first"}}}))
+
+		assert.NoError(t, backend.Purge("key"))
+		_, found := backend.Get("key")
+		assert.False(t, found)
+
+		members, _ := server.Members("test:tag:first")
+		assert.Equal(t, []string{"test:entry:other"}, members)
+		assert.False(t, server.Exists("test:tag:second"))
+		assert.False(t, server.Exists("test:entrytags:key"))
+	})
+
+	t.Run("set removes the entry from previous tags", func(t *testing.T) {
+		backend, server := newTestRedisBackend(t)
+
+		assert.NoError(t, backend.Set("key", &cache.Entry{Data: "value", Meta: cache.Meta{Lifetime: time.Minute, Tags: []string{"old"}}}))
+		assert.NoError(t, backend.Set("key", &cache.Entry{Data: "value", Meta: cache.Meta{Lifetime: time.Minute, Tags: []string{"new"}}}))
+
+		assert.False(t, server.Exists("test:tag:old"))
+		assert.NoError(t, backend.PurgeTags([]string{"old"}))
+		_, found := backend.Get("key")
+		assert.True(t, found, "the entry is not purged by its previous tag")
+	})
+
+	t.Run("purge tags", func(t *testing.T) {
+		backend, server := newTestRedisBackend(t)
+
+		assert.NoError(t, backend.Set("first", &cache.Entry{Data: "value", Meta: cache.Meta{Lifetime: time.Minute, Tags: []string{"purged", "kept"}}}))
+		assert.NoError(t, backend.Set("second", &cache.Entry{Data: "value", Meta: cache.Meta{Lifetime: time.Minute, Tags: []string{"purged"}}}))
+		assert.NoError(t, backend.Set("third", &cache.Entry{Data: "value", Meta: cache.Meta{Lifetime: time.Minute, Tags: []string{"kept"}}}))
+
+		assert.NoError(t, backend.PurgeTags([]string{"purged", "unknown"}))
+
+		_, found := backend.Get("first")
+		assert.False(t, found)
+		_, found = backend.Get("second")
+		assert.False(t, found)
+		_, found = backend.Get("third")
+		assert.True(t, found)
+
+		assert.False(t, server.Exists("test:tag:purged"))
+		members, _ := server.Members("test:tag:kept")
+		assert.Equal(t, []string{"test:entry:third"}, members, "purged entries are removed from their other tags")
+		assert.NoError(t, backend.PurgeTags(nil))
+	})
+
+	t.Run("flush", func(t *testing.T) {
+		backend, server := newTestRedisBackend(t)
+
+		assert.NoError(t, server.Set("other:key", "kept"))
+		assert.NoError(t, backend.Set("key", &cache.Entry{Data: "value", Meta: cache.Meta{Lifetime: time.Minute, Tags: []string{"tag"}}}))
+
+		assert.NoError(t, backend.Flush())
+		assert.Equal(t, []string{"other:key"}, server.Keys(), "only the keys of the backend are deleted")
+	})
+
+	t.Run("codec", func(t *testing.T) {
+		backend, server := newTestRedisBackendWithCodec(t, flamingo.JSONCodec{})
+
+		assert.NoError(t, backend.Set("key", &cache.Entry{Data: "value", Meta: cache.Meta{Lifetime: time.Minute}}))
+		entry, found := backend.Get("key")
+		if assert.True(t, found) {
+			assert.Equal(t, "value", entry.Data)
+		}
+
+		stored, _ := server.Get("test:entry:key")
+		assert.True(t, json.Valid([]byte(stored)), "the entry is encoded with the codec")
+	})
+
+	t.Run("unavailable", func(t *testing.T) {
+		backend, server := newTestRedisBackend(t)
+		server.SetError("server down")
+
+		assert.Error(t, backend.Set("key", &cache.Entry{Data: "value"}))
+		_, found := backend.Get("key")
+		assert.False(t, found)
+	})
+}
+
+func TestRedisBackend_HTTPFrontend(t *testing.T) {
+	backend, _ := newTestRedisBackend(t)
+	frontend := new(cache.HTTPFrontend)
+	frontend.Inject(backend, flamingo.NullLogger{})
+
+	loads := 0
+	loader := func(context.Context) (*http.Response, *cache.Meta, error) {
+		loads++
+		return &http.Response{
+			Status:     "200 OK",
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{"text/plain"}},
+			Body:       ioutil.NopCloser(strings.NewReader("body")),
+		}, &cache.Meta{Lifetime: time.Minute}, nil
+	}
+
+	for i := 0; i < 2; i++ {
+		response, err := frontend.Get(context.Background(), "response", loader)
+		if !assert.NoError(t, err) {
+			return
+		}
+		body, _ := ioutil.ReadAll(response.Body)
+		assert.Equal(t, "body", string(body))
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.Equal(t, "text/plain", response.Header.Get("Content-Type"))
+	}
+	assert.Equal(t, 1, loads, "the response is served from redis")
+}
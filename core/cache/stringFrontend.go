@@ -64,5 +64,5 @@ func (sf *StringFrontend) load(key string, loader StringLoader) (string, error)
 		},
 	})
 
-	return data.(string), nil
+	return data.(loaderResponse).data.(string), nil
 }
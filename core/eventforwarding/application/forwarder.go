@@ -0,0 +1,94 @@
+package application
+
+import (
+	"context"
+	"time"
+
+	"flamingo.me/flamingo/v3/core/eventforwarding/domain"
+	"flamingo.me/flamingo/v3/framework/config"
+	"flamingo.me/flamingo/v3/framework/flamingo"
+	"go.opencensus.io/trace"
+)
+
+type (
+	// Forwarder subscribes to all events and forwards the selected ones to the outbox
+	Forwarder struct {
+		outbox   *Outbox
+		encoders map[string]domain.Encoder
+		area     string
+		logger   flamingo.Logger
+	}
+
+	encoderProvider func() map[string]domain.Encoder
+)
+
+// NewForwarder creates a forwarder for the given event types, which have to be contained in the encoders
+func NewForwarder(outbox *Outbox, encoders map[string]domain.Encoder, events []string, area string, logger flamingo.Logger) *Forwarder {
+	return new(Forwarder).init(outbox, encoders, events, area, logger)
+}
+
+// Inject dependencies
+func (f *Forwarder) Inject(
+	outbox *Outbox,
+	encoderProvider encoderProvider,
+	logger flamingo.Logger,
+	cfg *struct {
+		Events config.Slice `inject:"config:eventforwarding.events"`
+		Area   string       `inject:"config:area"`
+	},
+) *Forwarder {
+	events := make([]string, 0, len(cfg.Events))
+	for _, event := range cfg.Events {
+		name, _ := event.(string)
+		events = append(events, name)
+	}
+
+	return f.init(outbox, encoderProvider(), events, cfg.Area, logger)
+}
+
+func (f *Forwarder) init(outbox *Outbox, encoders map[string]domain.Encoder, events []string, area string, logger flamingo.Logger) *Forwarder {
+	f.outbox = outbox
+	f.area = area
+	f.logger = logger.WithField(flamingo.LogKeyCategory, "eventforwarding")
+
+	f.encoders = make(map[string]domain.Encoder, len(events))
+	for _, name := range events {
+		encoder, ok := encoders[name]
+		if !ok {
+			f.logger.Warn("no encoder registered for event type ", name)
+			continue
+		}
+		f.encoders[name] = encoder
+	}
+
+	return f
+}
+
+// Notify forwards events with a selected encoder and closes the outbox on shutdown
+func (f *Forwarder) Notify(ctx context.Context, event flamingo.Event) {
+	if _, ok := event.(*flamingo.ShutdownEvent); ok {
+		if err := f.outbox.Close(ctx); err != nil {
+			f.logger.WithContext(ctx).Error("events lost on shutdown: ", err)
+		}
+		return
+	}
+
+	for name, encoder := range f.encoders {
+		payload, ok := encoder.Encode(ctx, event)
+		if !ok {
+			continue
+		}
+
+		envelope := &domain.Envelope{
+			Type:    name,
+			Time:    time.Now(),
+			Area:    f.area,
+			Payload: payload,
+		}
+		if span := trace.FromContext(ctx); span != nil {
+			envelope.TraceID = span.SpanContext().TraceID.String()
+		}
+
+		f.outbox.Add(envelope)
+	}
+}
@@ -0,0 +1,42 @@
+package application
+
+import (
+	"context"
+	"testing"
+
+	"flamingo.me/flamingo/v3/core/eventforwarding/domain"
+	"flamingo.me/flamingo/v3/framework/flamingo"
+	"github.com/stretchr/testify/assert"
+)
+
+type testEvent struct {
+	Value string
+}
+
+func TestForwarder_Notify(t *testing.T) {
+	publisher := new(flakyPublisher)
+	outbox := newTestOutbox(publisher, 10)
+
+	forwarder := NewForwarder(
+		outbox,
+		map[string]domain.Encoder{
+			"test": domain.EncoderFunc(func(_ context.Context, event flamingo.Event) (interface{}, bool) {
+				e, ok := event.(*testEvent)
+				return e, ok
+			}),
+			"unselected": domain.EncoderFunc(func(context.Context, flamingo.Event) (interface{}, bool) {
+				return nil, true
+			}),
+		},
+		[]string{"test", "unknown"},
+		"de",
+		flamingo.NullLogger{},
+	)
+
+	forwarder.Notify(context.Background(), &testEvent{Value: "forwarded"})
+	forwarder.Notify(context.Background(), new(flamingo.StartupEvent))
+	forwarder.Notify(context.Background(), new(flamingo.ShutdownEvent))
+
+	assert.Equal(t, 0, outbox.Pending(), "outbox must be flushed on shutdown")
+	assert.Equal(t, []string{"test"}, publisher.get())
+}
@@ -0,0 +1,182 @@
+package application
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"flamingo.me/flamingo/v3/core/eventforwarding/domain"
+	"flamingo.me/flamingo/v3/framework/flamingo"
+)
+
+type (
+	// Outbox buffers envelopes and publishes them in the background.
+	// If the publisher fails the envelopes are kept and publishing is retried with an exponential backoff.
+	Outbox struct {
+		publisher domain.Publisher
+		logger    flamingo.Logger
+		size      int
+		retry     time.Duration
+		maxRetry  time.Duration
+
+		mutex   sync.Mutex
+		flush   sync.Mutex
+		pending []outboxEntry
+		seq     uint64
+
+		startOnce sync.Once
+		stopOnce  sync.Once
+		wakeup    chan struct{}
+		stop      chan struct{}
+		done      chan struct{}
+	}
+
+	outboxEntry struct {
+		seq      uint64
+		envelope *domain.Envelope
+	}
+)
+
+// NewOutbox creates an outbox holding up to size envelopes (0 for unlimited),
+// failed publishes are retried after retry, doubled up to maxRetry
+func NewOutbox(publisher domain.Publisher, logger flamingo.Logger, size int, retry, maxRetry time.Duration) *Outbox {
+	return new(Outbox).init(publisher, logger, size, retry, maxRetry)
+}
+
+// Inject dependencies
+func (o *Outbox) Inject(
+	publisher domain.Publisher,
+	logger flamingo.Logger,
+	cfg *struct {
+		Size     float64 `inject:"config:eventforwarding.outbox.size"`
+		Retry    float64 `inject:"config:eventforwarding.outbox.retry"`
+		MaxRetry float64 `inject:"config:eventforwarding.outbox.maxRetry"`
+	},
+) *Outbox {
+	return o.init(publisher, logger, int(cfg.Size), time.Duration(cfg.Retry)*time.Millisecond, time.Duration(cfg.MaxRetry)*time.Millisecond)
+}
+
+func (o *Outbox) init(publisher domain.Publisher, logger flamingo.Logger, size int, retry, maxRetry time.Duration) *Outbox {
+	o.publisher = publisher
+	o.logger = logger.WithField(flamingo.LogKeyCategory, "eventforwarding")
+	o.size = size
+	o.retry = retry
+	o.maxRetry = maxRetry
+	o.wakeup = make(chan struct{}, 1)
+	o.stop = make(chan struct{})
+	o.done = make(chan struct{})
+	return o
+}
+
+// Add an envelope to the outbox. If the outbox is full, the oldest envelope is dropped.
+func (o *Outbox) Add(envelope *domain.Envelope) {
+	o.startOnce.Do(func() { go o.run() })
+
+	o.mutex.Lock()
+	if o.size > 0 && len(o.pending) >= o.size {
+		o.logger.Warn("outbox is full, dropping event ", o.pending[0].envelope.Type, " from ", o.pending[0].envelope.Time)
+		o.pending = o.pending[1:]
+	}
+	o.seq++
+	o.pending = append(o.pending, outboxEntry{seq: o.seq, envelope: envelope})
+	o.mutex.Unlock()
+
+	select {
+	case o.wakeup <- struct{}{}:
+	default:
+	}
+}
+
+// Pending returns the number of not yet published envelopes
+func (o *Outbox) Pending() int {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	return len(o.pending)
+}
+
+// Flush publishes all pending envelopes in order, it stops at the first failing envelope
+func (o *Outbox) Flush(ctx context.Context) error {
+	o.flush.Lock()
+	defer o.flush.Unlock()
+
+	o.mutex.Lock()
+	pending := append([]outboxEntry(nil), o.pending...)
+	o.mutex.Unlock()
+
+	for _, entry := range pending {
+		if err := o.publisher.Publish(ctx, entry.envelope); err != nil {
+			return err
+		}
+		o.remove(entry.seq)
+	}
+
+	return nil
+}
+
+// remove all entries up to seq
+func (o *Outbox) remove(seq uint64) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	i := 0
+	for i < len(o.pending) && o.pending[i].seq <= seq {
+		i++
+	}
+	o.pending = o.pending[i:]
+}
+
+// Close stops the background publishing and does a last attempt to publish all pending envelopes
+func (o *Outbox) Close(ctx context.Context) error {
+	o.stopOnce.Do(func() {
+		close(o.stop)
+		o.startOnce.Do(func() { close(o.done) })
+	})
+	<-o.done
+
+	return o.Flush(ctx)
+}
+
+func (o *Outbox) run() {
+	defer close(o.done)
+
+	var backoff time.Duration
+	var retry <-chan time.Time
+
+	for {
+		select {
+		case <-o.stop:
+			return
+		case <-o.wakeup:
+			if retry != nil {
+				// wait for the retry
+				continue
+			}
+		case <-retry:
+			retry = nil
+		}
+
+		if err := o.Flush(context.Background()); err != nil {
+			backoff = o.nextBackoff(backoff)
+			o.logger.Warn("publishing failed, ", o.Pending(), " events pending, retry in ", backoff, ": ", err)
+			retry = time.After(backoff)
+			continue
+		}
+		backoff = 0
+	}
+}
+
+func (o *Outbox) nextBackoff(backoff time.Duration) time.Duration {
+	if backoff == 0 {
+		backoff = o.retry
+	} else {
+		backoff *= 2
+	}
+	if o.maxRetry > 0 && backoff > o.maxRetry {
+		backoff = o.maxRetry
+	}
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+	return backoff
+}
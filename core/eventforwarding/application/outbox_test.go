@@ -0,0 +1,86 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"flamingo.me/flamingo/v3/core/eventforwarding/domain"
+	"flamingo.me/flamingo/v3/framework/flamingo"
+	"github.com/stretchr/testify/assert"
+)
+
+type flakyPublisher struct {
+	mutex     sync.Mutex
+	failing   bool
+	published []string
+}
+
+func (p *flakyPublisher) Publish(_ context.Context, envelope *domain.Envelope) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.failing {
+		return errors.New("bus not available")
+	}
+	p.published = append(p.published, envelope.Type)
+	return nil
+}
+
+func (p *flakyPublisher) setFailing(failing bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.failing = failing
+}
+
+func (p *flakyPublisher) get() []string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return append([]string(nil), p.published...)
+}
+
+func newTestOutbox(publisher domain.Publisher, size int) *Outbox {
+	return NewOutbox(publisher, flamingo.NullLogger{}, size, 10*time.Millisecond, 20*time.Millisecond)
+}
+
+func TestOutbox_Retry(t *testing.T) {
+	publisher := &flakyPublisher{failing: true}
+	outbox := newTestOutbox(publisher, 10)
+
+	outbox.Add(&domain.Envelope{Type: "a"})
+	outbox.Add(&domain.Envelope{Type: "b"})
+
+	time.Sleep(50 * time.Millisecond)
+	assert.Empty(t, publisher.get())
+	assert.Equal(t, 2, outbox.Pending())
+
+	publisher.setFailing(false)
+	for i := 0; i < 100 && outbox.Pending() > 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, 0, outbox.Pending())
+	assert.Equal(t, []string{"a", "b"}, publisher.get(), "envelopes must be published in order")
+
+	assert.NoError(t, outbox.Close(context.Background()))
+}
+
+func TestOutbox_Full(t *testing.T) {
+	publisher := &flakyPublisher{failing: true}
+	outbox := newTestOutbox(publisher, 2)
+
+	outbox.Add(&domain.Envelope{Type: "a"})
+	outbox.Add(&domain.Envelope{Type: "b"})
+	outbox.Add(&domain.Envelope{Type: "c"})
+	assert.Equal(t, 2, outbox.Pending())
+
+	publisher.setFailing(false)
+	assert.NoError(t, outbox.Close(context.Background()))
+	assert.Equal(t, []string{"b", "c"}, publisher.get(), "the oldest envelope must be dropped")
+}
+
+func TestOutbox_CloseUnused(t *testing.T) {
+	outbox := newTestOutbox(new(flakyPublisher), 2)
+	assert.NoError(t, outbox.Close(context.Background()))
+}
@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"flamingo.me/flamingo/v3/framework/flamingo"
+)
+
+type (
+	// Envelope wraps a forwarded event with its metadata
+	Envelope struct {
+		// Type is the name of the event type, e.g. `oauth.login`
+		Type    string      `json:"type"`
+		Time    time.Time   `json:"time"`
+		TraceID string      `json:"traceId,omitempty"`
+		Area    string      `json:"area"`
+		Payload interface{} `json:"payload"`
+	}
+
+	// Publisher publishes envelopes to a message bus
+	Publisher interface {
+		Publish(ctx context.Context, envelope *Envelope) error
+	}
+
+	// Encoder converts events into a JSON serializable payload.
+	// Encoders are map-bound by the event type name which is used in the envelope.
+	Encoder interface {
+		// Encode returns the payload for the event, or false if the event is not handled by the encoder
+		Encode(ctx context.Context, event flamingo.Event) (payload interface{}, ok bool)
+	}
+
+	// EncoderFunc is a function implementing the Encoder interface
+	EncoderFunc func(ctx context.Context, event flamingo.Event) (payload interface{}, ok bool)
+)
+
+// Encode calls the EncoderFunc
+func (f EncoderFunc) Encode(ctx context.Context, event flamingo.Event) (interface{}, bool) {
+	return f(ctx, event)
+}
@@ -0,0 +1,76 @@
+package infrastructure
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"flamingo.me/flamingo/v3/core/eventforwarding/domain"
+	oauthDomain "flamingo.me/flamingo/v3/core/oauth/domain"
+	"flamingo.me/flamingo/v3/framework/flamingo"
+	"flamingo.me/flamingo/v3/framework/web"
+)
+
+type (
+	sessionPayload struct {
+		Session string `json:"session"`
+	}
+
+	requestPayload struct {
+		Method string `json:"method"`
+		Host   string `json:"host"`
+		Path   string `json:"path"`
+		Error  string `json:"error,omitempty"`
+	}
+)
+
+// NewLoginEncoder encodes the oauth LoginEvent, the session ID is replaced with a hash keyed with the given key
+func NewLoginEncoder(key []byte) domain.Encoder {
+	return domain.EncoderFunc(func(_ context.Context, event flamingo.Event) (interface{}, bool) {
+		e, ok := event.(*oauthDomain.LoginEvent)
+		if !ok || e.Session == nil {
+			return nil, false
+		}
+		return &sessionPayload{Session: sessionHash(key, e.Session.ID())}, true
+	})
+}
+
+// NewLogoutEncoder encodes the oauth LogoutEvent, the session ID is replaced with a hash keyed with the given key
+func NewLogoutEncoder(key []byte) domain.Encoder {
+	return domain.EncoderFunc(func(_ context.Context, event flamingo.Event) (interface{}, bool) {
+		e, ok := event.(*oauthDomain.LogoutEvent)
+		if !ok || e.Session == nil {
+			return nil, false
+		}
+		return &sessionPayload{Session: sessionHash(key, e.Session.ID())}, true
+	})
+}
+
+// sessionHash allows to correlate the events of a session without forwarding the session ID, which is a credential
+func sessionHash(key []byte, id string) string {
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write([]byte(id))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+var (
+	// FinishEncoder encodes the web OnFinishEvent
+	FinishEncoder domain.Encoder = domain.EncoderFunc(func(_ context.Context, event flamingo.Event) (interface{}, bool) {
+		e, ok := event.(*web.OnFinishEvent)
+		if !ok || e.Request == nil {
+			return nil, false
+		}
+
+		r := e.Request.Request()
+		payload := &requestPayload{
+			Method: r.Method,
+			Host:   r.Host,
+			Path:   r.URL.Path,
+		}
+		if e.Error != nil {
+			payload.Error = e.Error.Error()
+		}
+		return payload, true
+	})
+)
@@ -0,0 +1,34 @@
+package infrastructure
+
+import (
+	"context"
+	"testing"
+
+	oauthDomain "flamingo.me/flamingo/v3/core/oauth/domain"
+	"flamingo.me/flamingo/v3/framework/web"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionEncoders(t *testing.T) {
+	session := web.EmptySession()
+	session.Regenerate()
+
+	login, ok := NewLoginEncoder([]byte("key")).Encode(context.Background(), &oauthDomain.LoginEvent{Session: session})
+	assert.True(t, ok)
+	logout, ok := NewLogoutEncoder([]byte("key")).Encode(context.Background(), &oauthDomain.LogoutEvent{Session: session})
+	assert.True(t, ok)
+
+	hash := login.(*sessionPayload).Session
+	assert.NotEqual(t, session.ID(), hash, "the session id must not be forwarded")
+	assert.NotContains(t, hash, session.ID())
+	assert.Len(t, hash, 64)
+	assert.Equal(t, hash, logout.(*sessionPayload).Session, "login and logout of a session must be correlated")
+
+	other, _ := NewLoginEncoder([]byte("other")).Encode(context.Background(), &oauthDomain.LoginEvent{Session: session})
+	assert.NotEqual(t, hash, other.(*sessionPayload).Session, "the hash must depend on the key")
+
+	_, ok = NewLoginEncoder([]byte("key")).Encode(context.Background(), &oauthDomain.LogoutEvent{Session: session})
+	assert.False(t, ok)
+	_, ok = NewLogoutEncoder([]byte("key")).Encode(context.Background(), &oauthDomain.LogoutEvent{})
+	assert.False(t, ok)
+}
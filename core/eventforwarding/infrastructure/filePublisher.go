@@ -0,0 +1,53 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+
+	"flamingo.me/flamingo/v3/core/eventforwarding/domain"
+)
+
+// FilePublisher appends envelopes as newline delimited JSON to a file
+type FilePublisher struct {
+	mutex sync.Mutex
+	path  string
+}
+
+var _ domain.Publisher = new(FilePublisher)
+
+// NewFilePublisher creates a publisher appending to the file at path
+func NewFilePublisher(path string) *FilePublisher {
+	return &FilePublisher{path: path}
+}
+
+// Inject dependencies
+func (p *FilePublisher) Inject(cfg *struct {
+	Path string `inject:"config:eventforwarding.file.path"`
+}) *FilePublisher {
+	p.path = cfg.Path
+	return p
+}
+
+// Publish appends the envelope to the file
+func (p *FilePublisher) Publish(_ context.Context, envelope *domain.Envelope) error {
+	line, err := json.Marshal(envelope)
+	if err != nil {
+		return err
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	f, err := os.OpenFile(p.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,34 @@
+package infrastructure
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"flamingo.me/flamingo/v3/core/eventforwarding/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilePublisher_Publish(t *testing.T) {
+	dir, err := ioutil.TempDir("", "eventforwarding")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "events.ndjson")
+	publisher := NewFilePublisher(path)
+
+	eventTime := time.Date(2019, 4, 1, 12, 0, 0, 0, time.UTC)
+	assert.NoError(t, publisher.Publish(context.Background(), &domain.Envelope{Type: "oauth.login", Time: eventTime, Area: "de", Payload: map[string]string{"session": "abc"}}))
+	assert.NoError(t, publisher.Publish(context.Background(), &domain.Envelope{Type: "oauth.logout", Time: eventTime, TraceID: "123", Area: "de"}))
+
+	content, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t,
+		`{"type":"oauth.login","time":"2019-04-01T12:00:00Z","area":"de","payload":{"session":"abc"}}`+"\n"+
+			`{"type":"oauth.logout","time":"2019-04-01T12:00:00Z","traceId":"123","area":"de","payload":null}`+"\n",
+		string(content),
+	)
+}
@@ -0,0 +1,33 @@
+package infrastructure
+
+import (
+	"context"
+	"sync"
+
+	"flamingo.me/flamingo/v3/core/eventforwarding/domain"
+)
+
+// MemoryPublisher keeps all published envelopes in memory, useful for local development and tests
+type MemoryPublisher struct {
+	mutex     sync.Mutex
+	envelopes []*domain.Envelope
+}
+
+var _ domain.Publisher = new(MemoryPublisher)
+
+// Publish stores the envelope
+func (p *MemoryPublisher) Publish(_ context.Context, envelope *domain.Envelope) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.envelopes = append(p.envelopes, envelope)
+	return nil
+}
+
+// Envelopes returns all published envelopes
+func (p *MemoryPublisher) Envelopes() []*domain.Envelope {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return append([]*domain.Envelope(nil), p.envelopes...)
+}
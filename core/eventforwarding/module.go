@@ -0,0 +1,62 @@
+// Package eventforwarding forwards selected flamingo events as JSON envelopes to a message bus
+package eventforwarding
+
+import (
+	"crypto/rand"
+
+	"flamingo.me/dingo"
+	"flamingo.me/flamingo/v3/core/eventforwarding/application"
+	"flamingo.me/flamingo/v3/core/eventforwarding/domain"
+	"flamingo.me/flamingo/v3/core/eventforwarding/infrastructure"
+	"flamingo.me/flamingo/v3/framework/config"
+	"flamingo.me/flamingo/v3/framework/flamingo"
+)
+
+// Module for event forwarding
+type Module struct {
+	Publisher  string `inject:"config:eventforwarding.publisher"`
+	SessionKey string `inject:"config:eventforwarding.sessionKey"`
+}
+
+// Configure DI
+func (m *Module) Configure(injector *dingo.Injector) {
+	injector.Bind(application.Outbox{}).In(dingo.Singleton)
+	flamingo.BindEventSubscriber(injector).To(application.Forwarder{})
+
+	switch m.Publisher {
+	case "file":
+		injector.Bind(infrastructure.FilePublisher{}).In(dingo.Singleton)
+		injector.Bind(new(domain.Publisher)).To(infrastructure.FilePublisher{})
+	case "memory":
+		injector.Bind(infrastructure.MemoryPublisher{}).In(dingo.Singleton)
+		injector.Bind(new(domain.Publisher)).To(infrastructure.MemoryPublisher{})
+	}
+
+	key := []byte(m.SessionKey)
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		_, _ = rand.Read(key)
+	}
+	injector.BindMap(new(domain.Encoder), "oauth.login").ToInstance(infrastructure.NewLoginEncoder(key))
+	injector.BindMap(new(domain.Encoder), "oauth.logout").ToInstance(infrastructure.NewLogoutEncoder(key))
+	injector.BindMap(new(domain.Encoder), "web.finish").ToInstance(infrastructure.FinishEncoder)
+}
+
+// DefaultConfig for the module
+func (m *Module) DefaultConfig() config.Map {
+	return config.Map{
+		"eventforwarding": config.Map{
+			"events":     config.Slice{},
+			"publisher":  "memory",
+			"sessionKey": "",
+			"file": config.Map{
+				"path": "events.ndjson",
+			},
+			"outbox": config.Map{
+				"size":     float64(1000),
+				"retry":    float64(1000),
+				"maxRetry": float64(60000),
+			},
+		},
+	}
+}
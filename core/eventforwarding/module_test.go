@@ -0,0 +1,24 @@
+package eventforwarding_test
+
+import (
+	"testing"
+
+	"flamingo.me/dingo"
+	"flamingo.me/flamingo/v3/core/eventforwarding"
+	"flamingo.me/flamingo/v3/framework/config"
+)
+
+func TestModule_Configure(t *testing.T) {
+	for _, publisher := range []string{"memory", "file"} {
+		cfgModule := &config.Module{
+			Map: new(eventforwarding.Module).DefaultConfig(),
+		}
+
+		cfgModule.Map["area"] = "test"
+		cfgModule.Map["eventforwarding"].(config.Map)["publisher"] = publisher
+
+		if err := dingo.TryModule(cfgModule, new(eventforwarding.Module)); err != nil {
+			t.Error(err)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package application
+
+import (
+	"context"
+	"sort"
+
+	"flamingo.me/flamingo/v3/core/featureflag/domain"
+	"flamingo.me/flamingo/v3/framework/config"
+	"flamingo.me/flamingo/v3/framework/flamingo"
+	"flamingo.me/flamingo/v3/framework/web"
+)
+
+type (
+	// Flags evaluates the configured feature flags for the current request
+	Flags struct {
+		flags            map[string]*domain.Flag
+		area             string
+		groupProvider    domain.GroupProvider
+		logger           flamingo.Logger
+		overridesEnabled bool
+		overrideHeader   string
+		overrideCookie   string
+	}
+
+	// overridesKey caches the parsed overrides in the request values
+	overridesKey struct{}
+)
+
+// Inject dependencies
+func (f *Flags) Inject(groupProvider domain.GroupProvider, logger flamingo.Logger, cfg *struct {
+	Flags            config.Map `inject:"config:featureflag.flags"`
+	Area             string     `inject:"config:area"`
+	OverridesEnabled bool       `inject:"config:featureflag.overrides.enabled"`
+	OverrideHeader   string     `inject:"config:featureflag.overrides.header"`
+	OverrideCookie   string     `inject:"config:featureflag.overrides.cookie"`
+}) *Flags {
+	f.groupProvider = groupProvider
+	f.logger = logger.WithField(flamingo.LogKeyModule, "featureflag")
+	f.flags = make(map[string]*domain.Flag)
+
+	if cfg != nil {
+		f.area = cfg.Area
+		f.overridesEnabled = cfg.OverridesEnabled
+		f.overrideHeader = cfg.OverrideHeader
+		f.overrideCookie = cfg.OverrideCookie
+
+		for name, definition := range cfg.Flags {
+			flag := &domain.Flag{Name: name}
+			switch definition := definition.(type) {
+			case bool:
+				flag.Enabled = definition
+			case config.Map:
+				if err := definition.MapInto(flag); err != nil {
+					f.logger.Error("invalid definition for feature flag ", name, ": ", err)
+					continue
+				}
+			default:
+				f.logger.Error("invalid definition for feature flag ", name)
+				continue
+			}
+			f.flags[name] = flag
+		}
+	}
+
+	return f
+}
+
+// IsEnabled checks if the feature flag is enabled in the given context.
+// Unknown flags are always disabled, unless they are enabled via an override.
+func (f *Flags) IsEnabled(ctx context.Context, name string) bool {
+	return f.isEnabled(ctx, name, f.overrides(ctx))
+}
+
+func (f *Flags) isEnabled(ctx context.Context, name string, overrides map[string]bool) bool {
+	if enabled, ok := overrides[name]; ok {
+		return enabled
+	}
+
+	flag, ok := f.flags[name]
+	if !ok {
+		return false
+	}
+
+	return flag.IsEnabledFor(f.subject(ctx, flag.NeedsGroups()))
+}
+
+// Enabled returns a sorted list of all flags enabled in the given context
+func (f *Flags) Enabled(ctx context.Context) []string {
+	overrides := f.overrides(ctx)
+	enabled := make([]string, 0, len(f.flags))
+
+	for name := range overrides {
+		if _, ok := f.flags[name]; !ok && overrides[name] {
+			enabled = append(enabled, name)
+		}
+	}
+
+	for name := range f.flags {
+		if f.isEnabled(ctx, name, overrides) {
+			enabled = append(enabled, name)
+		}
+	}
+
+	sort.Strings(enabled)
+	return enabled
+}
+
+func (f *Flags) subject(ctx context.Context, withGroups bool) domain.Subject {
+	subject := domain.Subject{Area: f.area}
+
+	session := web.SessionFromContext(ctx)
+	if session == nil {
+		return subject
+	}
+	subject.SessionID = session.ID()
+
+	if withGroups && f.groupProvider != nil {
+		subject.Groups = f.groupProvider.Groups(ctx, session)
+	}
+
+	return subject
+}
+
+// overrides of the current request, they are parsed once per request
+func (f *Flags) overrides(ctx context.Context) map[string]bool {
+	if !f.overridesEnabled {
+		return nil
+	}
+
+	req := web.RequestFromContext(ctx)
+	if req == nil {
+		return nil
+	}
+
+	if cached, ok := req.Values.Load(overridesKey{}); ok {
+		return cached.(map[string]bool)
+	}
+
+	overrides := f.parseOverrides(req)
+	req.Values.Store(overridesKey{}, overrides)
+
+	return overrides
+}
+
+func (f *Flags) parseOverrides(req *web.Request) map[string]bool {
+	overrides := make(map[string]bool)
+
+	if f.overrideCookie != "" {
+		if cookie, err := req.Request().Cookie(f.overrideCookie); err == nil {
+			for name, enabled := range domain.ParseOverrides(cookie.Value) {
+				overrides[name] = enabled
+			}
+		}
+	}
+
+	if f.overrideHeader != "" {
+		for name, enabled := range domain.ParseOverrides(req.Request().Header.Get(f.overrideHeader)) {
+			overrides[name] = enabled
+		}
+	}
+
+	return overrides
+}
@@ -0,0 +1,107 @@
+package application
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"flamingo.me/flamingo/v3/framework/config"
+	"flamingo.me/flamingo/v3/framework/flamingo"
+	"flamingo.me/flamingo/v3/framework/web"
+	"github.com/stretchr/testify/assert"
+)
+
+type staticGroups []string
+
+func (g staticGroups) Groups(context.Context, *web.Session) []string {
+	return g
+}
+
+func flagsConfig(flags config.Map, overrides bool) *struct {
+	Flags            config.Map `inject:"config:featureflag.flags"`
+	Area             string     `inject:"config:area"`
+	OverridesEnabled bool       `inject:"config:featureflag.overrides.enabled"`
+	OverrideHeader   string     `inject:"config:featureflag.overrides.header"`
+	OverrideCookie   string     `inject:"config:featureflag.overrides.cookie"`
+} {
+	return &struct {
+		Flags            config.Map `inject:"config:featureflag.flags"`
+		Area             string     `inject:"config:area"`
+		OverridesEnabled bool       `inject:"config:featureflag.overrides.enabled"`
+		OverrideHeader   string     `inject:"config:featureflag.overrides.header"`
+		OverrideCookie   string     `inject:"config:featureflag.overrides.cookie"`
+	}{
+		Flags:            flags,
+		Area:             "de",
+		OverridesEnabled: overrides,
+		OverrideHeader:   "X-Feature-Flags",
+		OverrideCookie:   "featureflags",
+	}
+}
+
+func TestFlags_IsEnabled(t *testing.T) {
+	flags := new(Flags).Inject(staticGroups{"beta"}, flamingo.NullLogger{}, flagsConfig(config.Map{
+		"simple":   true,
+		"disabled": false,
+		"beta":     config.Map{"enabled": true, "groups": config.Slice{"beta"}},
+		"staff":    config.Map{"enabled": true, "groups": config.Slice{"staff"}},
+		"austria":  config.Map{"enabled": true, "areas": config.Slice{"at"}},
+	}, false))
+
+	ctx := web.ContextWithSession(context.Background(), web.EmptySession())
+
+	assert.True(t, flags.IsEnabled(ctx, "simple"))
+	assert.False(t, flags.IsEnabled(ctx, "disabled"))
+	assert.True(t, flags.IsEnabled(ctx, "beta"))
+	assert.False(t, flags.IsEnabled(ctx, "staff"))
+	assert.False(t, flags.IsEnabled(ctx, "austria"))
+	assert.False(t, flags.IsEnabled(ctx, "unknown"))
+	assert.Equal(t, []string{"beta", "simple"}, flags.Enabled(ctx))
+}
+
+func TestFlags_Overrides(t *testing.T) {
+	flagConfig := config.Map{
+		"simple":   true,
+		"disabled": false,
+	}
+
+	httpRequest, _ := http.NewRequest(http.MethodGet, "/", nil)
+	httpRequest.Header.Set("X-Feature-Flags", "!simple,unknown")
+	httpRequest.AddCookie(&http.Cookie{Name: "featureflags", Value: "disabled,unknown"})
+	req := web.CreateRequest(httpRequest, nil)
+	ctx := web.ContextWithRequest(web.ContextWithSession(context.Background(), req.Session()), req)
+
+	t.Run("overrides disabled", func(t *testing.T) {
+		flags := new(Flags).Inject(staticGroups{}, flamingo.NullLogger{}, flagsConfig(flagConfig, false))
+
+		assert.True(t, flags.IsEnabled(ctx, "simple"))
+		assert.False(t, flags.IsEnabled(ctx, "disabled"))
+		assert.False(t, flags.IsEnabled(ctx, "unknown"))
+	})
+
+	t.Run("overrides enabled", func(t *testing.T) {
+		flags := new(Flags).Inject(staticGroups{}, flamingo.NullLogger{}, flagsConfig(flagConfig, true))
+
+		assert.False(t, flags.IsEnabled(ctx, "simple"))
+		assert.True(t, flags.IsEnabled(ctx, "disabled"))
+		assert.True(t, flags.IsEnabled(ctx, "unknown"))
+		assert.Equal(t, []string{"disabled", "unknown"}, flags.Enabled(ctx))
+	})
+
+	t.Run("overrides are parsed once per request", func(t *testing.T) {
+		flags := new(Flags).Inject(staticGroups{}, flamingo.NullLogger{}, flagsConfig(flagConfig, true))
+
+		httpRequest, _ := http.NewRequest(http.MethodGet, "/", nil)
+		httpRequest.Header.Set("X-Feature-Flags", "!simple")
+		req := web.CreateRequest(httpRequest, nil)
+		ctx := web.ContextWithRequest(context.Background(), req)
+
+		assert.False(t, flags.IsEnabled(ctx, "simple"))
+		req.Request().Header.Set("X-Feature-Flags", "simple")
+		assert.False(t, flags.IsEnabled(ctx, "simple"), "the parsed overrides are kept for the request")
+		assert.Equal(t, []string{}, flags.Enabled(ctx))
+
+		other := web.CreateRequest(req.Request(), nil)
+		assert.True(t, flags.IsEnabled(web.ContextWithRequest(context.Background(), other), "simple"))
+	})
+}
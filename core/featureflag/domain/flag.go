@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"context"
+	"hash/fnv"
+	"strings"
+
+	"flamingo.me/flamingo/v3/framework/web"
+)
+
+type (
+	// Flag defines a feature flag and the rules which must match to enable it.
+	// All configured rules have to match, rules which are not configured are ignored.
+	Flag struct {
+		Name       string   `json:"-"`
+		Enabled    bool     `json:"enabled"`
+		Percentage *float64 `json:"percentage"`
+		Groups     []string `json:"groups"`
+		Areas      []string `json:"areas"`
+	}
+
+	// Subject describes whom a flag is evaluated for
+	Subject struct {
+		SessionID string
+		Groups    []string
+		Area      string
+	}
+
+	// GroupProvider returns the groups of the user attached to the session
+	GroupProvider interface {
+		Groups(ctx context.Context, session *web.Session) []string
+	}
+)
+
+// IsEnabledFor evaluates the flag's rules for the given subject
+func (f *Flag) IsEnabledFor(subject Subject) bool {
+	if !f.Enabled {
+		return false
+	}
+
+	if len(f.Areas) > 0 && !contains(f.Areas, subject.Area) {
+		return false
+	}
+
+	if len(f.Groups) > 0 && !intersects(f.Groups, subject.Groups) {
+		return false
+	}
+
+	if f.Percentage != nil {
+		return f.inRollout(subject.SessionID)
+	}
+
+	return true
+}
+
+// NeedsGroups checks if the flag evaluation depends on the user's groups
+func (f *Flag) NeedsGroups() bool {
+	return f.Enabled && len(f.Groups) > 0
+}
+
+// inRollout puts the session in a stable bucket between 0 and 100 for this flag
+func (f *Flag) inRollout(sessionID string) bool {
+	percentage := *f.Percentage
+	if percentage >= 100 {
+		return true
+	}
+	if percentage <= 0 || sessionID == "" {
+		return false
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(f.Name + ":" + sessionID))
+	bucket := float64(h.Sum32()%10000) / 100
+
+	return bucket < percentage
+}
+
+// ParseOverrides parses a comma separated list of flag names, names prefixed with `!` are disabled, e.g. "flagA,!flagB"
+func ParseOverrides(value string) map[string]bool {
+	overrides := make(map[string]bool)
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || name == "!" {
+			continue
+		}
+		if name[0] == '!' {
+			overrides[name[1:]] = false
+			continue
+		}
+		overrides[name] = true
+	}
+
+	return overrides
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func intersects(a, b []string) bool {
+	for _, v := range a {
+		if contains(b, v) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,99 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func percentage(p float64) *float64 {
+	return &p
+}
+
+func TestFlag_IsEnabledFor(t *testing.T) {
+	tests := []struct {
+		name    string
+		flag    Flag
+		subject Subject
+		want    bool
+	}{
+		{
+			name: "disabled",
+			flag: Flag{Name: "test"},
+			want: false,
+		},
+		{
+			name: "enabled without rules",
+			flag: Flag{Name: "test", Enabled: true},
+			want: true,
+		},
+		{
+			name:    "area matches",
+			flag:    Flag{Name: "test", Enabled: true, Areas: []string{"de", "at"}},
+			subject: Subject{Area: "at"},
+			want:    true,
+		},
+		{
+			name:    "area does not match",
+			flag:    Flag{Name: "test", Enabled: true, Areas: []string{"de"}},
+			subject: Subject{Area: "at"},
+			want:    false,
+		},
+		{
+			name:    "group matches",
+			flag:    Flag{Name: "test", Enabled: true, Groups: []string{"beta"}},
+			subject: Subject{Groups: []string{"staff", "beta"}},
+			want:    true,
+		},
+		{
+			name:    "group does not match",
+			flag:    Flag{Name: "test", Enabled: true, Groups: []string{"beta"}},
+			subject: Subject{Groups: []string{"staff"}},
+			want:    false,
+		},
+		{
+			name:    "full rollout",
+			flag:    Flag{Name: "test", Enabled: true, Percentage: percentage(100)},
+			subject: Subject{SessionID: "abc"},
+			want:    true,
+		},
+		{
+			name:    "no rollout",
+			flag:    Flag{Name: "test", Enabled: true, Percentage: percentage(0)},
+			subject: Subject{SessionID: "abc"},
+			want:    false,
+		},
+		{
+			name:    "partial rollout without session",
+			flag:    Flag{Name: "test", Enabled: true, Percentage: percentage(50)},
+			subject: Subject{},
+			want:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.flag.IsEnabledFor(tt.subject))
+		})
+	}
+}
+
+func TestFlag_Rollout(t *testing.T) {
+	flag := Flag{Name: "test", Enabled: true, Percentage: percentage(30)}
+
+	enabled := 0
+	for i := 0; i < 1000; i++ {
+		subject := Subject{SessionID: string(rune('a'+i%26)) + string(rune('a'+i/26%26)) + string(rune('0'+i/676))}
+		if flag.IsEnabledFor(subject) {
+			enabled++
+		}
+		assert.Equal(t, flag.IsEnabledFor(subject), flag.IsEnabledFor(subject), "rollout must be stable per session")
+	}
+
+	assert.InDelta(t, 300, enabled, 60)
+}
+
+func TestParseOverrides(t *testing.T) {
+	assert.Equal(t, map[string]bool{"a": true, "b": false, "c": true}, ParseOverrides("a, !b,c,,!"))
+	assert.Equal(t, map[string]bool{}, ParseOverrides(""))
+}
@@ -0,0 +1,44 @@
+package infrastructure
+
+import (
+	"context"
+
+	"flamingo.me/flamingo/v3/core/featureflag/domain"
+	"flamingo.me/flamingo/v3/core/oauth/application"
+	"flamingo.me/flamingo/v3/framework/web"
+)
+
+type (
+	// OAuthGroupProvider reads the groups of the logged in oauth user
+	OAuthGroupProvider struct {
+		userService application.UserServiceInterface
+	}
+)
+
+var _ domain.GroupProvider = new(OAuthGroupProvider)
+
+// Inject dependencies, the user service is optional so the module works without the oauth module
+func (p *OAuthGroupProvider) Inject(cfg *struct {
+	UserService application.UserServiceInterface `inject:",optional"`
+}) *OAuthGroupProvider {
+	if cfg != nil {
+		p.userService = cfg.UserService
+	}
+
+	return p
+}
+
+// Groups returns the groups of the oauth user
+func (p *OAuthGroupProvider) Groups(ctx context.Context, session *web.Session) []string {
+	// the oauth user service relies on the request being available in the context
+	if p.userService == nil || session == nil || web.RequestFromContext(ctx) == nil {
+		return nil
+	}
+
+	user := p.userService.GetUser(ctx, session)
+	if user == nil {
+		return nil
+	}
+
+	return user.Groups
+}
@@ -0,0 +1,29 @@
+package controller
+
+import (
+	"context"
+
+	"flamingo.me/flamingo/v3/core/featureflag/application"
+	"flamingo.me/flamingo/v3/framework/web"
+)
+
+// DataController exposes feature flags to templates and javascript
+type DataController struct {
+	flags *application.Flags
+}
+
+// Inject dependencies
+func (c *DataController) Inject(flags *application.Flags) *DataController {
+	c.flags = flags
+	return c
+}
+
+// IsEnabled checks the flag given by the `name` parameter
+func (c *DataController) IsEnabled(ctx context.Context, _ *web.Request, params web.RequestParams) interface{} {
+	return c.flags.IsEnabled(ctx, params["name"])
+}
+
+// Enabled lists all enabled flags
+func (c *DataController) Enabled(ctx context.Context, _ *web.Request, _ web.RequestParams) interface{} {
+	return c.flags.Enabled(ctx)
+}
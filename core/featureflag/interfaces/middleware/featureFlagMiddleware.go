@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"context"
+
+	"flamingo.me/flamingo/v3/core/featureflag/application"
+	"flamingo.me/flamingo/v3/framework/web"
+	"github.com/pkg/errors"
+)
+
+type (
+	// FeatureFlagMiddleware to be used to enable controllers/routes only for certain feature flags
+	FeatureFlagMiddleware struct {
+		responder *web.Responder
+		flags     *application.Flags
+	}
+)
+
+// Inject dependencies
+func (m *FeatureFlagMiddleware) Inject(responder *web.Responder, flags *application.Flags) *FeatureFlagMiddleware {
+	m.responder = responder
+	m.flags = flags
+	return m
+}
+
+// HandleIfEnabled allows a controller to be used if the flag is enabled, otherwise a 404 is returned
+func (m *FeatureFlagMiddleware) HandleIfEnabled(action web.Action, flag string) web.Action {
+	return m.HandleIfEnabledWithFallback(action, m.notFoundAction(flag), flag)
+}
+
+// HandleIfEnabledWithFallback is HandleIfEnabled with a fallback action
+func (m *FeatureFlagMiddleware) HandleIfEnabledWithFallback(action web.Action, fallback web.Action, flag string) web.Action {
+	return func(ctx context.Context, req *web.Request) web.Result {
+		if !m.flags.IsEnabled(ctx, flag) {
+			return fallback(ctx, req)
+		}
+		return action(ctx, req)
+	}
+}
+
+func (m *FeatureFlagMiddleware) notFoundAction(flag string) web.Action {
+	return func(ctx context.Context, req *web.Request) web.Result {
+		return m.responder.NotFound(errors.Errorf("Feature %s is disabled for path %s.", flag, req.Request().URL.Path))
+	}
+}
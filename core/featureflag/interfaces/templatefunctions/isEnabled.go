@@ -0,0 +1,25 @@
+package templatefunctions
+
+import (
+	"context"
+
+	"flamingo.me/flamingo/v3/core/featureflag/application"
+)
+
+// IsEnabled is exported as a template function
+type IsEnabled struct {
+	flags *application.Flags
+}
+
+// Inject dependencies
+func (tf *IsEnabled) Inject(flags *application.Flags) *IsEnabled {
+	tf.flags = flags
+	return tf
+}
+
+// Func returns the isEnabled template function, usage: isEnabled("flagName")
+func (tf *IsEnabled) Func(ctx context.Context) interface{} {
+	return func(name string) bool {
+		return tf.flags.IsEnabled(ctx, name)
+	}
+}
@@ -0,0 +1,58 @@
+// Package featureflag provides feature flags which are defined in the configuration and evaluated per request
+package featureflag
+
+import (
+	"flamingo.me/dingo"
+	"flamingo.me/flamingo/v3/core/featureflag/application"
+	"flamingo.me/flamingo/v3/core/featureflag/domain"
+	"flamingo.me/flamingo/v3/core/featureflag/infrastructure"
+	"flamingo.me/flamingo/v3/core/featureflag/interfaces/controller"
+	"flamingo.me/flamingo/v3/core/featureflag/interfaces/templatefunctions"
+	"flamingo.me/flamingo/v3/framework/config"
+	"flamingo.me/flamingo/v3/framework/flamingo"
+	"flamingo.me/flamingo/v3/framework/web"
+)
+
+type (
+	// Module is the featureflag module entry point
+	Module struct{}
+
+	routes struct {
+		dataController *controller.DataController
+	}
+)
+
+// Inject dependencies
+func (r *routes) Inject(c *controller.DataController) {
+	r.dataController = c
+}
+
+// Routes registers the featureflag data controllers
+func (r *routes) Routes(registry *web.RouterRegistry) {
+	registry.HandleData("featureflag.isEnabled", r.dataController.IsEnabled)
+	registry.HandleData("featureflag.enabled", r.dataController.Enabled)
+}
+
+// Configure featureflag dependency injection
+func (m *Module) Configure(injector *dingo.Injector) {
+	injector.Bind(application.Flags{}).In(dingo.ChildSingleton)
+	injector.Bind(new(domain.GroupProvider)).To(infrastructure.OAuthGroupProvider{})
+
+	flamingo.BindTemplateFunc(injector, "isEnabled", new(templatefunctions.IsEnabled))
+
+	web.BindRoutes(injector, new(routes))
+}
+
+// DefaultConfig for the featureflag module
+func (m *Module) DefaultConfig() config.Map {
+	return config.Map{
+		"featureflag": config.Map{
+			"flags": config.Map{},
+			"overrides": config.Map{
+				"enabled": false,
+				"header":  "X-Feature-Flags",
+				"cookie":  "featureflags",
+			},
+		},
+	}
+}
@@ -0,0 +1,21 @@
+package featureflag_test
+
+import (
+	"testing"
+
+	"flamingo.me/dingo"
+	"flamingo.me/flamingo/v3/core/featureflag"
+	"flamingo.me/flamingo/v3/framework/config"
+)
+
+func TestModule_Configure(t *testing.T) {
+	cfgModule := &config.Module{
+		Map: new(featureflag.Module).DefaultConfig(),
+	}
+
+	cfgModule.Map["area"] = "test"
+
+	if err := dingo.TryModule(cfgModule, new(featureflag.Module)); err != nil {
+		t.Error(err)
+	}
+}
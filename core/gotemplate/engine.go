@@ -79,6 +79,7 @@ func (e *engine) Inject(
 
 func (e *engine) Render(ctx context.Context, name string, data interface{}) (io.Reader, error) {
 	ctx, span := trace.StartSpan(ctx, "gotemplate/Render")
+	span.Annotate(nil, name)
 	defer span.End()
 
 	lock.Lock()
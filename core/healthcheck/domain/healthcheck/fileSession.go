@@ -3,6 +3,8 @@ package healthcheck
 import "os"
 
 // FileSession session backend health check
+//
+// Deprecated: the session status is reported by flamingo.SessionStatus
 type FileSession struct {
 	fileName string
 }
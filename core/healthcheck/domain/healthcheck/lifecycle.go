@@ -0,0 +1,24 @@
+package healthcheck
+
+import "flamingo.me/flamingo/v3/framework/flamingo"
+
+// Lifecycle health check, the application is only ready after all lifecycle hooks have been started
+type Lifecycle struct {
+	lifecycle *flamingo.Lifecycle
+}
+
+var _ Status = &Lifecycle{}
+
+// Inject the lifecycle
+func (s *Lifecycle) Inject(lifecycle *flamingo.Lifecycle) {
+	s.lifecycle = lifecycle
+}
+
+// Status checks if all lifecycle hooks have been started
+func (s *Lifecycle) Status() (bool, string) {
+	if s.lifecycle != nil && s.lifecycle.Ready() {
+		return true, "success"
+	}
+
+	return false, "lifecycle hooks not started"
+}
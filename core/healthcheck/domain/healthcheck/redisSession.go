@@ -3,6 +3,8 @@ package healthcheck
 import "github.com/gomodule/redigo/redis"
 
 // RedisSession pool status check
+//
+// Deprecated: the session status is reported by flamingo.SessionStatus
 type RedisSession struct {
 	pool *redis.Pool
 }
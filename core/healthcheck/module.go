@@ -9,6 +9,7 @@ import (
 	"flamingo.me/flamingo/v3/core/healthcheck/domain/healthcheck"
 	"flamingo.me/flamingo/v3/core/healthcheck/interfaces/controllers"
 	"flamingo.me/flamingo/v3/framework/config"
+	"flamingo.me/flamingo/v3/framework/flamingo"
 	"flamingo.me/flamingo/v3/framework/systemendpoint"
 	"flamingo.me/flamingo/v3/framework/systemendpoint/domain"
 	"flamingo.me/flamingo/v3/framework/web"
@@ -19,9 +20,9 @@ type Module struct {
 	controller      *controllers.Healthcheck
 	checkSession    bool
 	checkAuthServer bool
+	checkLifecycle  bool
 	checkPath       string
 	pingPath        string
-	sessionBackend  string
 }
 
 // Inject dependencies
@@ -30,17 +31,17 @@ func (m *Module) Inject(
 	config *struct {
 		CheckSession    bool   `inject:"config:healthcheck.checkSession"`
 		CheckAuthServer bool   `inject:"config:healthcheck.checkAuth"`
+		CheckLifecycle  bool   `inject:"config:healthcheck.checkLifecycle"`
 		CheckPath       string `inject:"config:healthcheck.checkPath"`
 		PingPath        string `inject:"config:healthcheck.pingPath"`
-		SessionBackend  string `inject:"config:session.backend"`
 	},
 ) {
 	m.controller = controller
 	m.checkSession = config.CheckSession
 	m.checkAuthServer = config.CheckAuthServer
+	m.checkLifecycle = config.CheckLifecycle
 	m.checkPath = config.CheckPath
 	m.pingPath = config.PingPath
-	m.sessionBackend = config.SessionBackend
 }
 
 type routes struct {
@@ -59,18 +60,14 @@ func (r *routes) Routes(registry *web.RouterRegistry) {
 // Configure dependency injection
 func (m *Module) Configure(injector *dingo.Injector) {
 	if m.checkSession {
-		switch m.sessionBackend {
-		case "redis":
-			injector.BindMap(new(healthcheck.Status), "session").To(healthcheck.RedisSession{})
-		case "file":
-			injector.BindMap(new(healthcheck.Status), "session").To(healthcheck.FileSession{})
-		default:
-			injector.BindMap(new(healthcheck.Status), "session").To(healthcheck.Nil{})
-		}
+		injector.BindMap(new(healthcheck.Status), "session").To(flamingo.SessionStatus{})
 	}
 	if m.checkAuthServer {
 		injector.BindMap((*healthcheck.Status)(nil), "auth").To(healthcheck.Auth{})
 	}
+	if m.checkLifecycle {
+		injector.BindMap(new(healthcheck.Status), "lifecycle").To(healthcheck.Lifecycle{})
+	}
 
 	injector.BindMap((*domain.Handler)(nil), m.pingPath).To(&controllers.Ping{})
 	injector.BindMap((*domain.Handler)(nil), m.checkPath).To(&controllers.Healthcheck{})
@@ -82,10 +79,11 @@ func (m *Module) Configure(injector *dingo.Injector) {
 func (m *Module) DefaultConfig() config.Map {
 	return config.Map{
 		"healthcheck": config.Map{
-			"checkSession": true,
-			"checkAuth":    false,
-			"checkPath":    "/status/healthcheck",
-			"pingPath":     "/status/ping",
+			"checkSession":   true,
+			"checkAuth":      false,
+			"checkLifecycle": true,
+			"checkPath":      "/status/healthcheck",
+			"pingPath":       "/status/ping",
 		},
 	}
 }
@@ -0,0 +1,124 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"flamingo.me/flamingo/v3/core/inspector/domain"
+	"flamingo.me/flamingo/v3/framework/flamingo"
+)
+
+type (
+	// LoggerInterceptor records the log lines of loggers used with the context of a recorded request
+	LoggerInterceptor struct {
+		flamingo.Logger
+		fields map[string]interface{}
+	}
+
+	recordingLogger struct {
+		flamingo.Logger
+		record *domain.Record
+		fields map[string]interface{}
+	}
+)
+
+var (
+	_ flamingo.Logger = new(LoggerInterceptor)
+	_ flamingo.Logger = new(recordingLogger)
+)
+
+// WithContext returns a recording logger if the request of the context is recorded
+func (l *LoggerInterceptor) WithContext(ctx context.Context) flamingo.Logger {
+	logger := l.Logger.WithContext(ctx)
+	if record := domain.RecordFromContext(ctx); record != nil {
+		return &recordingLogger{Logger: logger, record: record, fields: l.fields}
+	}
+	return &LoggerInterceptor{Logger: logger, fields: l.fields}
+}
+
+// WithField keeps the field for the recorded log lines
+func (l *LoggerInterceptor) WithField(key flamingo.LogKey, value interface{}) flamingo.Logger {
+	return &LoggerInterceptor{Logger: l.Logger.WithField(key, value), fields: withFields(l.fields, map[flamingo.LogKey]interface{}{key: value})}
+}
+
+// WithFields keeps the fields for the recorded log lines
+func (l *LoggerInterceptor) WithFields(fields map[flamingo.LogKey]interface{}) flamingo.Logger {
+	return &LoggerInterceptor{Logger: l.Logger.WithFields(fields), fields: withFields(l.fields, fields)}
+}
+
+// WithContext keeps recording, also for the context of another recorded request
+func (l *recordingLogger) WithContext(ctx context.Context) flamingo.Logger {
+	record := domain.RecordFromContext(ctx)
+	if record == nil {
+		record = l.record
+	}
+	return &recordingLogger{Logger: l.Logger.WithContext(ctx), record: record, fields: l.fields}
+}
+
+// WithField adds a field
+func (l *recordingLogger) WithField(key flamingo.LogKey, value interface{}) flamingo.Logger {
+	return &recordingLogger{Logger: l.Logger.WithField(key, value), record: l.record, fields: withFields(l.fields, map[flamingo.LogKey]interface{}{key: value})}
+}
+
+// WithFields adds fields
+func (l *recordingLogger) WithFields(fields map[flamingo.LogKey]interface{}) flamingo.Logger {
+	return &recordingLogger{Logger: l.Logger.WithFields(fields), record: l.record, fields: withFields(l.fields, fields)}
+}
+
+// Debug logs and records a message
+func (l *recordingLogger) Debug(args ...interface{}) {
+	l.add("debug", fmt.Sprint(args...))
+	l.Logger.Debug(args...)
+}
+
+// Debugf logs and records a formatted message
+func (l *recordingLogger) Debugf(log string, args ...interface{}) {
+	l.add("debug", fmt.Sprintf(log, args...))
+	l.Logger.Debugf(log, args...)
+}
+
+// Info logs and records a message
+func (l *recordingLogger) Info(args ...interface{}) {
+	l.add("info", fmt.Sprint(args...))
+	l.Logger.Info(args...)
+}
+
+// Warn logs and records a message
+func (l *recordingLogger) Warn(args ...interface{}) {
+	l.add("warn", fmt.Sprint(args...))
+	l.Logger.Warn(args...)
+}
+
+// Error logs and records a message
+func (l *recordingLogger) Error(args ...interface{}) {
+	l.add("error", fmt.Sprint(args...))
+	l.Logger.Error(args...)
+}
+
+// Fatal logs and records a message
+func (l *recordingLogger) Fatal(args ...interface{}) {
+	l.add("fatal", fmt.Sprint(args...))
+	l.Logger.Fatal(args...)
+}
+
+// Panic logs and records a message
+func (l *recordingLogger) Panic(args ...interface{}) {
+	l.add("panic", fmt.Sprint(args...))
+	l.Logger.Panic(args...)
+}
+
+func (l *recordingLogger) add(level, message string) {
+	l.record.AddLog(domain.LogLine{Time: time.Now(), Level: level, Message: message, Fields: l.fields})
+}
+
+func withFields(fields map[string]interface{}, add map[flamingo.LogKey]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(fields)+len(add))
+	for k, v := range fields {
+		merged[k] = v
+	}
+	for k, v := range add {
+		merged[string(k)] = v
+	}
+	return merged
+}
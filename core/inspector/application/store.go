@@ -0,0 +1,101 @@
+package application
+
+import (
+	"sync"
+
+	"flamingo.me/flamingo/v3/core/inspector/domain"
+	"go.opencensus.io/trace"
+)
+
+type (
+	// Store keeps the records of the last requests, and records the steps of the requests from their trace spans
+	Store struct {
+		mutex   sync.RWMutex
+		size    int
+		records []*domain.Record
+		byID    map[string]*domain.Record
+	}
+)
+
+var _ trace.Exporter = new(Store)
+
+// NewStore keeps the last size records
+func NewStore(size int) *Store {
+	if size < 1 {
+		size = 1
+	}
+
+	return &Store{
+		size: size,
+		byID: make(map[string]*domain.Record),
+	}
+}
+
+// Add a record, the oldest record is dropped if the store is full
+func (s *Store) Add(record *domain.Record) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.records = append(s.records, record)
+	s.byID[record.ID] = record
+
+	if len(s.records) > s.size {
+		delete(s.byID, s.records[0].ID)
+		s.records = s.records[1:]
+	}
+}
+
+// Records returns copies of all records, the latest first
+func (s *Store) Records() []*domain.Record {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	records := make([]*domain.Record, len(s.records))
+	for i, record := range s.records {
+		records[len(records)-1-i] = record.Copy()
+	}
+	return records
+}
+
+// Get a copy of a record by its id
+func (s *Store) Get(id string) (*domain.Record, bool) {
+	s.mutex.RLock()
+	record, ok := s.byID[id]
+	s.mutex.RUnlock()
+
+	if !ok {
+		return nil, false
+	}
+	return record.Copy(), true
+}
+
+// ExportSpan records filters, templates and data controller calls of recorded requests.
+// The requests are identified by their trace, which is sampled by the inspector filter.
+func (s *Store) ExportSpan(span *trace.SpanData) {
+	s.mutex.RLock()
+	record := s.byID[span.TraceID.String()]
+	s.mutex.RUnlock()
+
+	if record == nil {
+		return
+	}
+
+	duration := span.EndTime.Sub(span.StartTime)
+	switch span.Name {
+	case "router/filter":
+		name, _ := span.Attributes["filter"].(string)
+		record.AddFilter(name, span.StartTime, duration)
+	case "gotemplate/Render":
+		record.AddTemplate(annotation(span), span.StartTime, duration)
+	case "flamingo/router/data":
+		record.AddDataCall(annotation(span), span.StartTime, duration)
+	}
+}
+
+// annotation returns the first annotation of the span, which is the name of the template or data controller
+func annotation(span *trace.SpanData) string {
+	if len(span.Annotations) == 0 {
+		return ""
+	}
+	return span.Annotations[0].Message
+}
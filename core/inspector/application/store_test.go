@@ -0,0 +1,97 @@
+package application
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"flamingo.me/flamingo/v3/core/inspector/domain"
+	"flamingo.me/flamingo/v3/framework/flamingo"
+	"github.com/stretchr/testify/assert"
+	"go.opencensus.io/trace"
+)
+
+func TestStore(t *testing.T) {
+	store := NewStore(2)
+	for _, id := range []string{"a", "b", "c"} {
+		store.Add(&domain.Record{ID: id})
+	}
+
+	records := store.Records()
+	assert.Len(t, records, 2)
+	assert.Equal(t, "c", records[0].ID, "latest first")
+	assert.Equal(t, "b", records[1].ID)
+
+	_, ok := store.Get("a")
+	assert.False(t, ok, "the oldest record is dropped")
+}
+
+func TestStore_ExportSpan(t *testing.T) {
+	store := NewStore(10)
+	trace.RegisterExporter(store)
+	defer trace.UnregisterExporter(store)
+
+	ctx, span := trace.StartSpan(context.Background(), "request", trace.WithSampler(trace.AlwaysSample()))
+	record := &domain.Record{ID: span.SpanContext().TraceID.String(), Time: time.Now()}
+	store.Add(record)
+
+	_, render := trace.StartSpan(ctx, "gotemplate/Render")
+	render.Annotate(nil, "page/home")
+	render.End()
+
+	_, data := trace.StartSpan(ctx, "flamingo/router/data")
+	data.Annotate(nil, "session.flash")
+	data.End()
+
+	_, filter := trace.StartSpan(ctx, "router/filter")
+	filter.AddAttributes(trace.StringAttribute("filter", "*main.filter"))
+	filter.End()
+
+	_, unrelated := trace.StartSpan(context.Background(), "gotemplate/Render", trace.WithSampler(trace.AlwaysSample()))
+	unrelated.End()
+	span.End()
+
+	record, _ = store.Get(record.ID)
+	assert.Len(t, record.Templates, 1)
+	assert.Equal(t, "page/home", record.Templates[0].Name)
+	assert.Len(t, record.DataCalls, 1)
+	assert.Equal(t, "session.flash", record.DataCalls[0].Name)
+	assert.Len(t, record.Filters, 1)
+	assert.Equal(t, "*main.filter", record.Filters[0].Name)
+}
+
+func TestTransportAndLogger(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	defer server.Close()
+
+	record := &domain.Record{Time: time.Now()}
+	ctx := domain.ContextWithRecord(context.Background(), record)
+
+	client := &http.Client{Transport: &Transport{Base: http.DefaultTransport}}
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/api", nil)
+	res, err := client.Do(req.WithContext(ctx))
+	assert.NoError(t, err)
+	res.Body.Close()
+
+	res, err = client.Get(server.URL + "/not-recorded")
+	assert.NoError(t, err)
+	res.Body.Close()
+
+	logger := (&LoggerInterceptor{Logger: flamingo.NullLogger{}}).WithField(flamingo.LogKeyCategory, "test")
+	logger.WithContext(ctx).WithField("foo", "bar").Info("recorded", 1)
+	logger.WithContext(context.Background()).Info("not recorded")
+
+	record = record.Copy()
+	assert.Len(t, record.HTTPCalls, 1)
+	assert.Equal(t, server.URL+"/api", record.HTTPCalls[0].URL)
+	assert.Equal(t, http.StatusTeapot, record.HTTPCalls[0].Status)
+
+	assert.Len(t, record.Logs, 1)
+	assert.Equal(t, "info", record.Logs[0].Level)
+	assert.Equal(t, "recorded1", record.Logs[0].Message)
+	assert.Equal(t, map[string]interface{}{"category": "test", "foo": "bar"}, record.Logs[0].Fields)
+}
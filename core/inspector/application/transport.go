@@ -0,0 +1,49 @@
+package application
+
+import (
+	"net/http"
+	"time"
+
+	"flamingo.me/flamingo/v3/core/inspector/domain"
+)
+
+type (
+	// Transport records the outgoing HTTP requests of recorded requests.
+	// The requests are executed with the Base transport, or http.DefaultTransport if Base is nil.
+	Transport struct {
+		Base http.RoundTripper
+	}
+)
+
+var _ http.RoundTripper = new(Transport)
+
+// RoundTrip executes the request with the base transport
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	record := domain.RecordFromContext(req.Context())
+	if record == nil {
+		return base.RoundTrip(req)
+	}
+
+	start := time.Now()
+	res, err := base.RoundTrip(req)
+
+	call := domain.HTTPCall{
+		Method:   req.Method,
+		URL:      req.URL.String(),
+		Duration: time.Since(start),
+	}
+	if res != nil {
+		call.Status = res.StatusCode
+	}
+	if err != nil {
+		call.Error = err.Error()
+	}
+	record.AddHTTPCall(call, start)
+
+	return res, err
+}
@@ -0,0 +1,157 @@
+package domain
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+type (
+	// Record of a request, see the Add* methods for the recorded details
+	Record struct {
+		ID          string            `json:"id"`
+		Time        time.Time         `json:"time"`
+		Duration    time.Duration     `json:"duration"`
+		Method      string            `json:"method"`
+		URL         string            `json:"url"`
+		Status      int               `json:"status"`
+		Handler     string            `json:"handler"`
+		Params      map[string]string `json:"params"`
+		SessionKeys []string          `json:"sessionKeys"`
+		Filters     []Timing          `json:"filters"`
+		Templates   []Timing          `json:"templates"`
+		DataCalls   []Timing          `json:"dataCalls"`
+		HTTPCalls   []HTTPCall        `json:"httpCalls"`
+		Logs        []LogLine         `json:"logs"`
+		Error       string            `json:"error,omitempty"`
+
+		mutex sync.Mutex
+	}
+
+	// Timing of a step of the request, relative to the start of the request
+	Timing struct {
+		Name     string        `json:"name"`
+		Start    time.Duration `json:"start"`
+		Duration time.Duration `json:"duration"`
+	}
+
+	// HTTPCall is an outgoing HTTP request
+	HTTPCall struct {
+		Method   string        `json:"method"`
+		URL      string        `json:"url"`
+		Status   int           `json:"status"`
+		Start    time.Duration `json:"start"`
+		Duration time.Duration `json:"duration"`
+		Error    string        `json:"error,omitempty"`
+	}
+
+	// LogLine logged in the context of the request
+	LogLine struct {
+		Time    time.Time              `json:"time"`
+		Level   string                 `json:"level"`
+		Message string                 `json:"message"`
+		Fields  map[string]interface{} `json:"fields,omitempty"`
+	}
+
+	contextKey struct{}
+)
+
+// ContextWithRecord returns a context which records into the record
+func ContextWithRecord(ctx context.Context, record *Record) context.Context {
+	return context.WithValue(ctx, contextKey{}, record)
+}
+
+// RecordFromContext returns the record of the request, or nil if the request is not recorded
+func RecordFromContext(ctx context.Context) *Record {
+	record, _ := ctx.Value(contextKey{}).(*Record)
+	return record
+}
+
+// AddFilter records a filter, including the time of the filters and controller called after it
+func (r *Record) AddFilter(name string, start time.Time, duration time.Duration) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.Filters = append(r.Filters, Timing{Name: name, Start: start.Sub(r.Time), Duration: duration})
+}
+
+// AddTemplate records a rendered template
+func (r *Record) AddTemplate(name string, start time.Time, duration time.Duration) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.Templates = append(r.Templates, Timing{Name: name, Start: start.Sub(r.Time), Duration: duration})
+}
+
+// AddDataCall records a call of a data controller
+func (r *Record) AddDataCall(handler string, start time.Time, duration time.Duration) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.DataCalls = append(r.DataCalls, Timing{Name: handler, Start: start.Sub(r.Time), Duration: duration})
+}
+
+// AddHTTPCall records an outgoing HTTP request
+func (r *Record) AddHTTPCall(call HTTPCall, start time.Time) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	call.Start = start.Sub(r.Time)
+	r.HTTPCalls = append(r.HTTPCalls, call)
+}
+
+// AddLog records a log line
+func (r *Record) AddLog(line LogLine) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.Logs = append(r.Logs, line)
+}
+
+// SetSessionKeys records the keys of the session
+func (r *Record) SetSessionKeys(keys []string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.SessionKeys = keys
+}
+
+// Finish the record with the response status and the total duration
+func (r *Record) Finish(status int, err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.Status = status
+	r.Duration = time.Since(r.Time)
+	if err != nil {
+		r.Error = err.Error()
+	}
+}
+
+// Copy returns a consistent copy of the record, with the timings in the order they were started.
+// Steps of the request may still be recorded while it is read, e.g. by background tasks.
+func (r *Record) Copy() *Record {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	c := &Record{
+		ID:          r.ID,
+		Time:        r.Time,
+		Duration:    r.Duration,
+		Method:      r.Method,
+		URL:         r.URL,
+		Status:      r.Status,
+		Handler:     r.Handler,
+		Params:      r.Params,
+		SessionKeys: r.SessionKeys,
+		Filters:     sortedTimings(r.Filters),
+		Templates:   sortedTimings(r.Templates),
+		DataCalls:   sortedTimings(r.DataCalls),
+		HTTPCalls:   append([]HTTPCall(nil), r.HTTPCalls...),
+		Logs:        append([]LogLine(nil), r.Logs...),
+		Error:       r.Error,
+	}
+	sort.SliceStable(c.HTTPCalls, func(i, j int) bool { return c.HTTPCalls[i].Start < c.HTTPCalls[j].Start })
+
+	return c
+}
+
+func sortedTimings(timings []Timing) []Timing {
+	sorted := append([]Timing(nil), timings...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Start < sorted[j].Start })
+	return sorted
+}
@@ -0,0 +1,219 @@
+package interfaces
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"flamingo.me/flamingo/v3/core/inspector/application"
+	"flamingo.me/flamingo/v3/core/inspector/domain"
+	"flamingo.me/flamingo/v3/framework/web"
+	"go.opencensus.io/tag"
+	"go.opencensus.io/trace"
+)
+
+type (
+	// Filter records the requests, and adds the toolbar to HTML responses
+	Filter struct {
+		store        *application.Store
+		toolbar      bool
+		alwaysSample bool
+		serviceAddr  string
+	}
+
+	// inspectedResult applies the result in the context of the record
+	inspectedResult struct {
+		web.Result
+		ctx    context.Context
+		record *domain.Record
+		filter *Filter
+		host   string
+	}
+
+	// responseWriter keeps the status, and buffers HTML responses to add the toolbar
+	responseWriter struct {
+		http.ResponseWriter
+		html   bool
+		status int
+		buffer *bytes.Buffer
+	}
+)
+
+var toolbarTemplate = template.Must(template.New("toolbar").Parse(`
+<div id="flamingo-inspector" style="position:fixed;bottom:0;right:0;z-index:2147483647;padding:4px 8px;font:12px/1.5 monospace;color:#fff;background:#333;opacity:.9">
+	<strong>{{.Status}}</strong> {{.Handler}} · {{.Duration}} · {{len .Templates}} templates · {{len .DataCalls}} data · {{len .HTTPCalls}} http · {{len .Logs}} logs
+	{{- if .Link}} · <a href="{{.Link}}" target="_blank" style="color:#ff69b4">inspect</a>{{end}}
+</div>
+`))
+
+// NewFilter creates a filter recording into the store, e.g. for usage without dependency injection
+func NewFilter(store *application.Store, toolbar, alwaysSample bool, serviceAddr string) *Filter {
+	return new(Filter).init(store, toolbar, alwaysSample, serviceAddr)
+}
+
+// Inject dependencies
+func (f *Filter) Inject(
+	store *application.Store,
+	cfg *struct {
+		Toolbar      bool   `inject:"config:inspector.toolbar"`
+		AlwaysSample bool   `inject:"config:inspector.alwaysSample"`
+		ServiceAddr  string `inject:"config:systemendpoint.serviceAddr,optional"`
+	},
+) *Filter {
+	if cfg == nil {
+		return f.init(store, false, false, "")
+	}
+	return f.init(store, cfg.Toolbar, cfg.AlwaysSample, cfg.ServiceAddr)
+}
+
+func (f *Filter) init(store *application.Store, toolbar, alwaysSample bool, serviceAddr string) *Filter {
+	f.store = store
+	f.toolbar = toolbar
+	f.alwaysSample = alwaysSample
+	f.serviceAddr = serviceAddr
+	return f
+}
+
+// FilterPriority ensures the inspector records all other filters
+func (f *Filter) FilterPriority() int {
+	return 1000
+}
+
+// Filter records the request. The filters, templates and data controller calls are recorded from the trace spans,
+// so only for sampled traces. With `inspector.alwaysSample` the traces of all requests are sampled.
+func (f *Filter) Filter(ctx context.Context, r *web.Request, w http.ResponseWriter, chain *web.FilterChain) web.Result {
+	var options []trace.StartOption
+	if f.alwaysSample {
+		options = append(options, trace.WithSampler(trace.AlwaysSample()))
+	}
+
+	ctx, span := trace.StartSpan(ctx, "inspector/request", options...)
+	defer span.End()
+
+	record := &domain.Record{
+		ID:     span.SpanContext().TraceID.String(),
+		Time:   time.Now(),
+		Method: r.Request().Method,
+		URL:    r.Request().URL.String(),
+		Params: make(map[string]string, len(r.Params)),
+	}
+	if tags := tag.FromContext(ctx); tags != nil {
+		record.Handler, _ = tags.Value(web.ControllerKey)
+	}
+	for k, v := range r.Params {
+		record.Params[k] = v
+	}
+	f.store.Add(record)
+
+	ctx = domain.ContextWithRecord(ctx, record)
+	result := chain.Next(ctx, r, w)
+
+	var keys []string
+	for _, key := range r.Session().Keys() {
+		keys = append(keys, fmt.Sprint(key))
+	}
+	sort.Strings(keys)
+	record.SetSessionKeys(keys)
+
+	if result == nil {
+		record.Finish(0, nil)
+		return nil
+	}
+
+	return &inspectedResult{Result: result, ctx: ctx, record: record, filter: f, host: r.Request().Host}
+}
+
+// link to the record in the inspector of the system endpoint
+func (f *Filter) link(host, id string) string {
+	if f.serviceAddr == "" {
+		return ""
+	}
+
+	addr := f.serviceAddr
+	if strings.HasPrefix(addr, ":") {
+		if hostname, _, err := net.SplitHostPort(host); err == nil {
+			host = hostname
+		}
+		addr = host + addr
+	}
+	return "http://" + addr + "/_flamingo/debug?id=" + id
+}
+
+// Apply the result and add the toolbar. The context of the record is used, so the templates and data controller calls are recorded
+func (r *inspectedResult) Apply(_ context.Context, w http.ResponseWriter) error {
+	rw := &responseWriter{ResponseWriter: w, html: r.filter.toolbar}
+	err := r.Result.Apply(r.ctx, rw)
+	r.record.Finish(rw.status, err)
+
+	if err != nil || rw.buffer == nil {
+		return rw.flush(nil)
+	}
+
+	toolbar := new(bytes.Buffer)
+	record := r.record.Copy()
+	if err := toolbarTemplate.Execute(toolbar, struct {
+		*domain.Record
+		Duration time.Duration
+		Link     string
+	}{
+		Record:   record,
+		Duration: record.Duration.Round(time.Microsecond),
+		Link:     r.filter.link(r.host, record.ID),
+	}); err != nil {
+		return rw.flush(nil)
+	}
+
+	return rw.flush(toolbar.Bytes())
+}
+
+// WriteHeader starts buffering HTML responses
+func (w *responseWriter) WriteHeader(status int) {
+	if w.status != 0 {
+		return
+	}
+	w.status = status
+
+	if w.html && strings.HasPrefix(w.Header().Get("Content-Type"), "text/html") {
+		w.buffer = new(bytes.Buffer)
+		return
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write the body, or buffer it for HTML responses
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.buffer != nil {
+		return w.buffer.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// flush a buffered response, with the toolbar added before the closing body tag
+func (w *responseWriter) flush(toolbar []byte) error {
+	if w.buffer == nil {
+		return nil
+	}
+
+	body := w.buffer.Bytes()
+	if len(toolbar) > 0 {
+		if i := bytes.LastIndex(bytes.ToLower(body), []byte("</body>")); i >= 0 {
+			body = append(body[:i:i], append(toolbar, body[i:]...)...)
+		} else {
+			body = append(body, toolbar...)
+		}
+	}
+
+	w.Header().Del("Content-Length")
+	w.ResponseWriter.WriteHeader(w.status)
+	_, err := w.ResponseWriter.Write(body)
+	return err
+}
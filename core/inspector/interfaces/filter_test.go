@@ -0,0 +1,140 @@
+package interfaces
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"flamingo.me/flamingo/v3/core/inspector/application"
+	"flamingo.me/flamingo/v3/core/inspector/domain"
+	"flamingo.me/flamingo/v3/framework/web"
+	"github.com/stretchr/testify/assert"
+	"go.opencensus.io/trace"
+)
+
+type renderResult struct {
+	contentType string
+}
+
+func (r *renderResult) Apply(ctx context.Context, w http.ResponseWriter) error {
+	_, span := trace.StartSpan(ctx, "gotemplate/Render")
+	span.Annotate(nil, "home")
+	span.End()
+
+	w.Header().Set("Content-Type", r.contentType)
+	w.WriteHeader(http.StatusCreated)
+	_, err := w.Write([]byte("<html><body>content</body></html>"))
+	return err
+}
+
+func testFilter(toolbar bool) (*Filter, *application.Store) {
+	store := application.NewStore(10)
+	return NewFilter(store, toolbar, true, ":13210"), store
+}
+
+func serve(f *Filter, contentType string) (*httptest.ResponseRecorder, error) {
+	request := web.CreateRequest(httptest.NewRequest(http.MethodGet, "http://example.com:3322/home?q=1", nil), nil)
+	request.Params["q"] = "1"
+
+	chain := web.NewFilterChain(func(ctx context.Context, r *web.Request, w http.ResponseWriter) web.Result {
+		return &renderResult{contentType: contentType}
+	})
+
+	recorder := httptest.NewRecorder()
+	result := f.Filter(context.Background(), request, recorder, chain)
+	return recorder, result.Apply(context.Background(), recorder)
+}
+
+func TestFilter(t *testing.T) {
+	f, store := testFilter(true)
+	trace.RegisterExporter(store)
+	defer trace.UnregisterExporter(store)
+
+	recorder, err := serve(f, "text/html; charset=utf-8")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+
+	records := store.Records()
+	assert.Len(t, records, 1)
+	record := records[0]
+	assert.Equal(t, http.StatusCreated, record.Status)
+	assert.Equal(t, "http://example.com:3322/home?q=1", record.URL)
+	assert.Equal(t, map[string]string{"q": "1"}, record.Params)
+	assert.Len(t, record.Templates, 1, "templates rendered when the result is applied are recorded")
+
+	body := recorder.Body.String()
+	assert.True(t, strings.HasPrefix(body, "<html><body>content"))
+	assert.True(t, strings.HasSuffix(body, "</div>\n</body></html>"), "toolbar is added before the closing body tag")
+	assert.Contains(t, body, `href="http://example.com:13210/_flamingo/debug?id=`+record.ID+`"`)
+	assert.Contains(t, body, "1 templates")
+}
+
+func TestFilter_Sampling(t *testing.T) {
+	store := application.NewStore(10)
+	trace.RegisterExporter(store)
+	defer trace.UnregisterExporter(store)
+
+	request := web.CreateRequest(httptest.NewRequest(http.MethodGet, "http://example.com/", nil), nil)
+	chain := func() *web.FilterChain {
+		return web.NewFilterChain(func(ctx context.Context, r *web.Request, w http.ResponseWriter) web.Result {
+			return &renderResult{contentType: "text/html"}
+		})
+	}
+
+	ctx, span := trace.StartSpan(context.Background(), "test", trace.WithSampler(trace.NeverSample()))
+	defer span.End()
+
+	recorder := httptest.NewRecorder()
+	assert.NoError(t, NewFilter(store, false, false, "").Filter(ctx, request, recorder, chain()).Apply(ctx, recorder))
+	assert.Len(t, store.Records(), 1, "requests are recorded regardless of sampling")
+	assert.Len(t, store.Records()[0].Templates, 0, "the sampling decision of the trace is kept")
+
+	recorder = httptest.NewRecorder()
+	assert.NoError(t, NewFilter(store, false, true, "").Filter(ctx, request, recorder, chain()).Apply(ctx, recorder))
+	assert.Len(t, store.Records()[0].Templates, 1, "traces are sampled with inspector.alwaysSample")
+}
+
+func TestFilter_NoToolbar(t *testing.T) {
+	f, _ := testFilter(true)
+	recorder, err := serve(f, "application/json")
+	assert.NoError(t, err)
+	assert.Equal(t, "<html><body>content</body></html>", recorder.Body.String(), "only HTML responses get the toolbar")
+
+	f, _ = testFilter(false)
+	recorder, err = serve(f, "text/html")
+	assert.NoError(t, err)
+	assert.Equal(t, "<html><body>content</body></html>", recorder.Body.String(), "toolbar is disabled")
+}
+
+func TestHandler(t *testing.T) {
+	f, store := testFilter(false)
+	_, err := serve(f, "text/html")
+	assert.NoError(t, err)
+	id := store.Records()[0].ID
+
+	handler := new(Handler).Inject(store)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/_flamingo/debug", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "?id="+id)
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/_flamingo/debug?id="+id, nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "GET http://example.com:3322/home?q=1")
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/_flamingo/debug?format=json&id="+id, nil))
+	var record domain.Record
+	assert.NoError(t, json.NewDecoder(bytes.NewReader(recorder.Body.Bytes())).Decode(&record))
+	assert.Equal(t, id, record.ID)
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/_flamingo/debug?id=unknown", nil))
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
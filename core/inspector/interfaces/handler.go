@@ -0,0 +1,130 @@
+package interfaces
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"time"
+
+	"flamingo.me/flamingo/v3/core/inspector/application"
+	"flamingo.me/flamingo/v3/core/inspector/domain"
+)
+
+type (
+	// Handler shows the recorded requests on the system endpoint, `?id=` shows the details of a request
+	// and `?format=json` returns the records as JSON
+	Handler struct {
+		store *application.Store
+	}
+)
+
+var templates = template.Must(template.New("layout").Funcs(template.FuncMap{
+	"ms": func(d time.Duration) string {
+		return d.Round(time.Microsecond).String()
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<title>Flamingo request inspector</title>
+<style>
+body { font: 14px/1.4 sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { text-align: left; padding: 2px 12px 2px 0; vertical-align: top; }
+td { font-family: monospace; }
+</style>
+</head>
+<body>
+<h1><a href="?">Flamingo request inspector</a></h1>
+{{if .List}}{{template "list" .Records}}{{else}}{{template "detail" .Record}}{{end}}
+</body>
+</html>
+{{define "list"}}
+<table>
+<tr><th>Time</th><th>Method</th><th>URL</th><th>Status</th><th>Handler</th><th>Duration</th><th>Templates</th><th>Data</th><th>HTTP</th><th>Logs</th></tr>
+{{range .}}
+<tr>
+	<td><a href="?id={{.ID}}">{{.Time.Format "15:04:05.000"}}</a></td><td>{{.Method}}</td><td>{{.URL}}</td><td>{{.Status}}</td><td>{{.Handler}}</td>
+	<td>{{ms .Duration}}</td><td>{{len .Templates}}</td><td>{{len .DataCalls}}</td><td>{{len .HTTPCalls}}</td><td>{{len .Logs}}</td>
+</tr>
+{{end}}
+</table>
+{{end}}
+{{define "timings"}}
+<table>
+<tr><th>Name</th><th>Start</th><th>Duration</th></tr>
+{{range .}}<tr><td>{{.Name}}</td><td>{{ms .Start}}</td><td>{{ms .Duration}}</td></tr>{{end}}
+</table>
+{{end}}
+{{define "detail"}}
+<h2>{{.Method}} {{.URL}}</h2>
+<table>
+<tr><th>Time</th><td>{{.Time}}</td></tr>
+<tr><th>Status</th><td>{{.Status}}</td></tr>
+<tr><th>Handler</th><td>{{.Handler}}</td></tr>
+<tr><th>Duration</th><td>{{ms .Duration}}</td></tr>
+{{if .Error}}<tr><th>Error</th><td>{{.Error}}</td></tr>{{end}}
+</table>
+<h3>Params</h3>
+<table>{{range $k, $v := .Params}}<tr><th>{{$k}}</th><td>{{$v}}</td></tr>{{end}}</table>
+<h3>Session keys</h3>
+<table>{{range .SessionKeys}}<tr><td>{{.}}</td></tr>{{end}}</table>
+<h3>Filters</h3>
+{{template "timings" .Filters}}
+<h3>Templates</h3>
+{{template "timings" .Templates}}
+<h3>Data controller calls</h3>
+{{template "timings" .DataCalls}}
+<h3>HTTP calls</h3>
+<table>
+<tr><th>Method</th><th>URL</th><th>Status</th><th>Start</th><th>Duration</th><th>Error</th></tr>
+{{range .HTTPCalls}}<tr><td>{{.Method}}</td><td>{{.URL}}</td><td>{{.Status}}</td><td>{{ms .Start}}</td><td>{{ms .Duration}}</td><td>{{.Error}}</td></tr>{{end}}
+</table>
+<h3>Logs</h3>
+<table>
+<tr><th>Time</th><th>Level</th><th>Message</th><th>Fields</th></tr>
+{{range .Logs}}<tr><td>{{.Time.Format "15:04:05.000"}}</td><td>{{.Level}}</td><td>{{.Message}}</td><td>{{range $k, $v := .Fields}}{{$k}}={{$v}} {{end}}</td></tr>{{end}}
+</table>
+{{end}}
+`))
+
+// Inject dependencies
+func (h *Handler) Inject(store *application.Store) *Handler {
+	h.store = store
+	return h
+}
+
+// ServeHTTP shows the records
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var data struct {
+		List    bool
+		Records []*domain.Record
+		Record  *domain.Record
+	}
+
+	if id := r.URL.Query().Get("id"); id != "" {
+		record, ok := h.store.Get(id)
+		if !ok {
+			http.Error(w, "request "+id+" not found", http.StatusNotFound)
+			return
+		}
+		data.Record = record
+	} else {
+		data.List = true
+		data.Records = h.store.Records()
+	}
+
+	if r.URL.Query().Get("format") == "json" {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		var v interface{} = data.Record
+		if data.List {
+			v = data.Records
+		}
+		_ = json.NewEncoder(w).Encode(v)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := templates.Execute(w, data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,75 @@
+// Package inspector records requests, if enabled: the matched handler, params, filters, session keys,
+// rendered templates, data controller calls, outgoing HTTP calls and log lines.
+// The last requests are shown on the system endpoint at /_flamingo/debug, and HTML responses get a small toolbar.
+package inspector
+
+import (
+	"net/http"
+	"sync"
+
+	"flamingo.me/dingo"
+	"flamingo.me/flamingo/v3/core/inspector/application"
+	"flamingo.me/flamingo/v3/core/inspector/interfaces"
+	"flamingo.me/flamingo/v3/framework/config"
+	"flamingo.me/flamingo/v3/framework/flamingo"
+	"flamingo.me/flamingo/v3/framework/systemendpoint/domain"
+	"flamingo.me/flamingo/v3/framework/web"
+	"go.opencensus.io/trace"
+)
+
+type (
+	// Module records requests, if `inspector.enabled` is set
+	Module struct {
+		enabled  bool
+		requests int
+	}
+)
+
+var (
+	registerOnce = new(sync.Once)
+	store        *application.Store
+)
+
+// Inject dependencies
+func (m *Module) Inject(
+	cfg *struct {
+		Enabled  bool    `inject:"config:inspector.enabled"`
+		Requests float64 `inject:"config:inspector.requests"`
+	},
+) {
+	if cfg != nil {
+		m.enabled = cfg.Enabled
+		m.requests = int(cfg.Requests)
+	}
+}
+
+// Configure DI, the inspector is only active if enabled.
+// The transport recording outgoing requests is always bound, it only records if the inspector is active.
+func (m *Module) Configure(injector *dingo.Injector) {
+	injector.Bind(new(http.RoundTripper)).AnnotatedWith("inspector").To(application.Transport{})
+
+	if !m.enabled {
+		return
+	}
+
+	// the spans of all areas are recorded in one store
+	registerOnce.Do(func() {
+		store = application.NewStore(m.requests)
+		trace.RegisterExporter(store)
+	})
+
+	injector.Bind(new(application.Store)).ToInstance(store)
+	injector.BindMulti(new(web.Filter)).To(interfaces.Filter{})
+	injector.BindInterceptor(new(flamingo.Logger), application.LoggerInterceptor{})
+	injector.BindMap((*domain.Handler)(nil), "/_flamingo/debug").To(interfaces.Handler{})
+}
+
+// DefaultConfig for the module
+func (m *Module) DefaultConfig() config.Map {
+	return config.Map{
+		"inspector.enabled":      false,
+		"inspector.requests":     float64(50),
+		"inspector.toolbar":      true,
+		"inspector.alwaysSample": false,
+	}
+}
@@ -0,0 +1,20 @@
+package inspector_test
+
+import (
+	"testing"
+
+	"flamingo.me/dingo"
+	"flamingo.me/flamingo/v3/core/inspector"
+	"flamingo.me/flamingo/v3/framework/config"
+)
+
+func TestModule_Configure(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		cfgModule := &config.Module{Map: new(inspector.Module).DefaultConfig()}
+		cfgModule.Map["inspector.enabled"] = enabled
+
+		if err := dingo.TryModule(cfgModule, new(inspector.Module)); err != nil {
+			t.Error(err)
+		}
+	}
+}
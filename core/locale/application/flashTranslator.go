@@ -0,0 +1,25 @@
+package application
+
+import (
+	"flamingo.me/flamingo/v3/framework/controller"
+)
+
+type (
+	// FlashTranslator resolves the translation keys of flash messages with the LabelService
+	FlashTranslator struct {
+		labelService *LabelService
+	}
+)
+
+var _ controller.FlashTranslator = new(FlashTranslator)
+
+// Inject dependencies
+func (t *FlashTranslator) Inject(labelService *LabelService) *FlashTranslator {
+	t.labelService = labelService
+	return t
+}
+
+// TranslateFlash translates the key, the args are passed as translation arguments
+func (t *FlashTranslator) TranslateFlash(key string, args map[string]interface{}) string {
+	return t.labelService.NewLabel(key).SetTranslationArguments(args).String()
+}
@@ -7,6 +7,7 @@ import (
 	"flamingo.me/flamingo/v3/core/locale/infrastructure"
 	"flamingo.me/flamingo/v3/core/locale/interfaces/templatefunctions"
 	"flamingo.me/flamingo/v3/framework/config"
+	"flamingo.me/flamingo/v3/framework/controller"
 	"flamingo.me/flamingo/v3/framework/flamingo"
 )
 
@@ -19,6 +20,7 @@ type (
 func (m *Module) Configure(injector *dingo.Injector) {
 	injector.Bind(new(domain.TranslationService)).In(dingo.ChildSingleton).To(infrastructure.TranslationService{})
 	injector.Bind(new(application.DateTimeServiceInterface)).To(application.DateTimeService{})
+	injector.Bind(new(controller.FlashTranslator)).To(application.FlashTranslator{})
 
 	flamingo.BindTemplateFunc(injector, "__", new(templatefunctions.Label))
 	flamingo.BindTemplateFunc(injector, "priceFormat", new(templatefunctions.PriceFormatFunc))
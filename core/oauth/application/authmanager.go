@@ -28,6 +28,16 @@ const (
 	keyTokenExtras = "auth.token.extras"
 )
 
+// SessionKeys are the typed session values of the auth manager
+var SessionKeys = []flamingo.SessionKey{
+	{Key: keyToken, Type: new(oauth2.Token), Version: 1},
+	{Key: keyRawIDToken, Type: "", Version: 1},
+	{Key: keyAuthstate, Type: "", Version: 1},
+	{Key: keyTokenExtras, Type: new(domain.TokenExtras), Version: 1},
+	{Key: keySessionSubject, Type: "", Version: 1},
+	{Key: keySessionID, Type: "", Version: 1},
+}
+
 func init() {
 	gob.Register(&oauth2.Token{})
 	gob.Register(&oidc.IDToken{})
@@ -47,11 +57,12 @@ type (
 		logger              flamingo.Logger
 		router              *web.Router
 		openIDProvider      *oidc.Provider
+		sessionManager      *SessionManager
 	}
 )
 
 // Inject authManager dependencies
-func (am *AuthManager) Inject(logger flamingo.Logger, router *web.Router, openIDProvider *oidc.Provider, config *struct {
+func (am *AuthManager) Inject(logger flamingo.Logger, router *web.Router, openIDProvider *oidc.Provider, sessionManager *SessionManager, config *struct {
 	Server              string       `inject:"config:oauth.server"`
 	Secret              string       `inject:"config:oauth.secret"`
 	ClientID            string       `inject:"config:oauth.clientid"`
@@ -62,6 +73,7 @@ func (am *AuthManager) Inject(logger flamingo.Logger, router *web.Router, openID
 }) {
 	am.logger = logger.WithField(flamingo.LogKeyModule, "oauth")
 	am.router = router
+	am.sessionManager = sessionManager
 	am.server = config.Server
 	am.secret = config.Secret
 	am.clientID = config.ClientID
@@ -173,6 +185,10 @@ func (am *AuthManager) getIDToken(c context.Context, session *web.Session) (*oid
 		return nil, "", errors.New("no session configured")
 	}
 
+	if err := am.checkRevoked(c, session); err != nil {
+		return nil, "", err
+	}
+
 	if token, ok := session.Load(keyRawIDToken); ok {
 		idtoken, err := am.Verifier().Verify(c, token.(string))
 		if err == nil {
@@ -258,6 +274,10 @@ func (am *AuthManager) ExtractRawIDToken(oauth2Token *oauth2.Token) (string, err
 
 // TokenSource to be used in situations where you need it
 func (am *AuthManager) TokenSource(c context.Context, session *web.Session) (oauth2.TokenSource, error) {
+	if err := am.checkRevoked(c, session); err != nil {
+		return nil, err
+	}
+
 	oauth2Token, err := am.OAuth2Token(session)
 	if err != nil {
 		return nil, err
@@ -275,6 +295,16 @@ func (am *AuthManager) HTTPClient(c context.Context, session *web.Session) (*htt
 	return oauth2.NewClient(c, ts), nil
 }
 
+// checkRevoked removes the token details of a session which has been revoked
+func (am *AuthManager) checkRevoked(c context.Context, session *web.Session) error {
+	if !am.sessionManager.Revoked(c, session) {
+		return nil
+	}
+
+	am.DeleteTokenDetails(session)
+	return ErrSessionRevoked
+}
+
 // StoreTokenDetails stores all token related data into session
 func (am *AuthManager) StoreTokenDetails(session *web.Session, oauth2Token *oauth2.Token, rawToken string, tokenExtras *domain.TokenExtras) {
 	session.Store(keyToken, oauth2Token)
@@ -29,19 +29,39 @@ func (e *EventPublisher) PublishLogoutEvent(ctx context.Context, event *domain.L
 
 // EventHandler for logout events
 type EventHandler struct {
-	authManager *AuthManager
+	authManager    *AuthManager
+	sessionManager *SessionManager
+	logger         flamingo.Logger
 }
 
 // Inject dependencies
-func (e *EventHandler) Inject(authManager *AuthManager) {
+func (e *EventHandler) Inject(authManager *AuthManager, sessionManager *SessionManager, logger flamingo.Logger) {
 	e.authManager = authManager
+	e.sessionManager = sessionManager
+	e.logger = logger.WithField(flamingo.LogKeyModule, "oauth")
 }
 
-// Notify calls AuthManager on each logout, so it can destroy data stored for previously logged in user
-func (e *EventHandler) Notify(_ context.Context, event flamingo.Event) {
-	logoutEvent, ok := event.(*domain.LogoutEvent)
-	if ok {
-		e.authManager.DeleteTokenDetails(logoutEvent.Session)
-		e.authManager.DeleteAuthState(logoutEvent.Session)
+// Notify regenerates the session id on login and logout to prevent session fixation,
+// keeps the session index up to date and calls AuthManager on each logout, so it can destroy data stored for previously logged in user
+func (e *EventHandler) Notify(ctx context.Context, event flamingo.Event) {
+	switch event := event.(type) {
+	case *domain.LoginEvent:
+		if event.Session != nil {
+			event.Session.Regenerate()
+			if err := e.sessionManager.Login(ctx, event.Session); err != nil {
+				e.logger.WithContext(ctx).Warn("unable to index the session: ", err)
+			}
+		}
+	case *domain.LogoutEvent:
+		if event.Session != nil {
+			if err := e.sessionManager.Logout(ctx, event.Session); err != nil {
+				e.logger.WithContext(ctx).Warn("unable to remove the session from the index: ", err)
+			}
+		}
+		e.authManager.DeleteTokenDetails(event.Session)
+		e.authManager.DeleteAuthState(event.Session)
+		if event.Session != nil {
+			event.Session.Regenerate()
+		}
 	}
 }
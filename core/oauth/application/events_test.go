@@ -0,0 +1,49 @@
+package application
+
+import (
+	"context"
+	"testing"
+
+	"flamingo.me/flamingo/v3/core/oauth/domain"
+	"flamingo.me/flamingo/v3/core/oauth/infrastructure"
+	"flamingo.me/flamingo/v3/framework/flamingo"
+	"flamingo.me/flamingo/v3/framework/web"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestEventHandler(sessionManager *SessionManager) *EventHandler {
+	handler := new(EventHandler)
+	handler.Inject(new(AuthManager), sessionManager, new(flamingo.NullLogger))
+	return handler
+}
+
+func TestEventHandler_Login(t *testing.T) {
+	session := web.EmptySession()
+	session.Store("token", "value")
+
+	newTestEventHandler(newTestSessionManager(new(infrastructure.MemorySessionIndex), false)).Notify(context.Background(), &domain.LoginEvent{Session: session})
+
+	assert.NotEmpty(t, session.ID(), "the session id is regenerated on login")
+	assert.Equal(t, "value", session.Try("token"))
+}
+
+func TestEventHandler_SessionIndex(t *testing.T) {
+	index := new(infrastructure.MemorySessionIndex)
+	handler := newTestEventHandler(newTestSessionManager(index, false))
+	ctx := context.Background()
+
+	session := testLoggedInSession("user")
+	handler.Notify(ctx, &domain.LoginEvent{Session: session})
+
+	sessions, err := index.List(ctx, "user")
+	assert.NoError(t, err)
+	if assert.Len(t, sessions, 1) {
+		assert.Equal(t, session.ID(), sessions[0].ID, "the regenerated session id is indexed")
+	}
+
+	handler.Notify(ctx, &domain.LogoutEvent{Session: session})
+	sessions, err = index.List(ctx, "user")
+	assert.NoError(t, err)
+	assert.Empty(t, sessions)
+	assert.Nil(t, session.Try(keyRawIDToken))
+}
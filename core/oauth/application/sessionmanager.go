@@ -0,0 +1,219 @@
+package application
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"flamingo.me/flamingo/v3/core/oauth/domain"
+	"flamingo.me/flamingo/v3/framework/flamingo"
+	"flamingo.me/flamingo/v3/framework/web"
+	"github.com/pkg/errors"
+)
+
+const (
+	// keySessionSubject defines where the subject of the indexed session is saved
+	keySessionSubject = "auth.session.subject"
+	// keySessionID defines where the id the session has been indexed with is saved, it differs after a Regenerate
+	keySessionID = "auth.session.id"
+)
+
+// ErrSessionRevoked is returned if the session has been logged out remotely
+var ErrSessionRevoked = errors.New("session has been revoked")
+
+type (
+	// SessionManager keeps track of the sessions a user is logged in with,
+	// so they can be listed and revoked, e.g. for a "log out everywhere" or after a password change
+	SessionManager struct {
+		index                       domain.SessionIndex
+		logger                      flamingo.Logger
+		ttl                         time.Duration
+		preventSimultaneousSessions bool
+		enforceRevocation           bool
+	}
+)
+
+// NewSessionManager creates a session manager, e.g. for usage without dependency injection.
+// Revoked sessions are only logged out with enforceRevocation, which is implied by preventSimultaneousSessions
+func NewSessionManager(index domain.SessionIndex, logger flamingo.Logger, ttl time.Duration, preventSimultaneousSessions bool, enforceRevocation bool) *SessionManager {
+	return new(SessionManager).init(index, logger, ttl, preventSimultaneousSessions, enforceRevocation)
+}
+
+// Inject dependencies
+func (sm *SessionManager) Inject(index domain.SessionIndex, logger flamingo.Logger, cfg *struct {
+	PreventSimultaneousSessions bool   `inject:"config:oauth.preventSimultaneousSessions"`
+	SessionIndexBackend         string `inject:"config:oauth.sessionIndex.backend,optional"`
+	// float64 is used due to the injection as config from json - int is not possible on this
+	MaxAge float64 `inject:"config:session.max.age"`
+}) *SessionManager {
+	// revocations are only enforced if the index is explicitly configured, the default index might not know all sessions,
+	// e.g. the memory index after a restart or on another instance
+	return sm.init(index, logger, time.Duration(cfg.MaxAge)*time.Second, cfg.PreventSimultaneousSessions, cfg.SessionIndexBackend != "")
+}
+
+func (sm *SessionManager) init(index domain.SessionIndex, logger flamingo.Logger, ttl time.Duration, preventSimultaneousSessions bool, enforceRevocation bool) *SessionManager {
+	sm.index = index
+	sm.logger = logger.WithField(flamingo.LogKeyModule, "oauth")
+	sm.ttl = ttl
+	sm.preventSimultaneousSessions = preventSimultaneousSessions
+	sm.enforceRevocation = enforceRevocation || preventSimultaneousSessions
+	return sm
+}
+
+// Login adds the session to the index of the logged in subject,
+// all other sessions of the subject are revoked if simultaneous sessions are prevented
+func (sm *SessionManager) Login(ctx context.Context, session *web.Session) error {
+	value, _ := session.Load(keyRawIDToken)
+	raw, _ := value.(string)
+	subject, err := rawIDTokenSubject(raw)
+	if err != nil {
+		return err
+	}
+
+	if sm.preventSimultaneousSessions {
+		if err := sm.RevokeAll(ctx, subject); err != nil {
+			return err
+		}
+	}
+
+	if err := sm.index.Add(ctx, subject, domain.UserSession{ID: session.ID(), LoginTime: time.Now()}, sm.ttl); err != nil {
+		return err
+	}
+	session.Store(keySessionSubject, subject)
+	session.Store(keySessionID, session.ID())
+
+	return nil
+}
+
+// Logout removes the session from the index
+func (sm *SessionManager) Logout(ctx context.Context, session *web.Session) error {
+	subject, ok := sm.subject(session)
+	if !ok {
+		return nil
+	}
+	indexedID := sm.indexedID(session)
+	session.Delete(keySessionSubject)
+	session.Delete(keySessionID)
+
+	return sm.index.Remove(ctx, subject, indexedID)
+}
+
+// Sessions lists the active sessions of the subject
+func (sm *SessionManager) Sessions(ctx context.Context, subject string) ([]domain.UserSession, error) {
+	return sm.index.List(ctx, subject)
+}
+
+// Revoke a session of the subject, the session is logged out on its next request
+func (sm *SessionManager) Revoke(ctx context.Context, subject string, sessionID string) error {
+	return sm.index.Remove(ctx, subject, sessionID)
+}
+
+// RevokeAll sessions of the subject, e.g. after a password change
+func (sm *SessionManager) RevokeAll(ctx context.Context, subject string) error {
+	return sm.index.RemoveAll(ctx, subject)
+}
+
+// Revoked checks if the logged in session has been revoked, if the index is not available the session is kept.
+// Without enforced revocation sessions are never revoked. A session which has been regenerated after the login
+// is indexed again with its new id.
+func (sm *SessionManager) Revoked(ctx context.Context, session *web.Session) bool {
+	subject, ok := sm.subject(session)
+	if !ok {
+		return false
+	}
+
+	indexedID := sm.indexedID(session)
+	if !sm.enforceRevocation && indexedID == session.ID() {
+		return false
+	}
+
+	active, err := sm.index.Contains(ctx, subject, indexedID)
+	if err != nil {
+		sm.logger.WithContext(ctx).Warn("unable to check the session index: ", err)
+		return false
+	}
+	if !active {
+		if !sm.enforceRevocation {
+			return false
+		}
+		session.Delete(keySessionSubject)
+		session.Delete(keySessionID)
+		return true
+	}
+
+	if indexedID != session.ID() {
+		if err := sm.reindex(ctx, subject, indexedID, session); err != nil {
+			sm.logger.WithContext(ctx).Warn("unable to index the regenerated session: ", err)
+		}
+	}
+
+	return false
+}
+
+// reindex replaces the previous id of a regenerated session in the index, the login time is kept
+func (sm *SessionManager) reindex(ctx context.Context, subject string, previousID string, session *web.Session) error {
+	sessions, err := sm.index.List(ctx, subject)
+	if err != nil {
+		return err
+	}
+
+	loginTime := time.Now()
+	for _, indexed := range sessions {
+		if indexed.ID == previousID {
+			loginTime = indexed.LoginTime
+		}
+	}
+
+	if err := sm.index.Add(ctx, subject, domain.UserSession{ID: session.ID(), LoginTime: loginTime}, sm.ttl); err != nil {
+		return err
+	}
+	session.Store(keySessionID, session.ID())
+
+	return sm.index.Remove(ctx, subject, previousID)
+}
+
+func (sm *SessionManager) subject(session *web.Session) (string, bool) {
+	if sm == nil || session == nil {
+		return "", false
+	}
+
+	value, _ := session.Load(keySessionSubject)
+	subject, ok := value.(string)
+	return subject, ok && subject != ""
+}
+
+// indexedID is the id the session has been indexed with
+func (sm *SessionManager) indexedID(session *web.Session) string {
+	if id, ok := session.Try(keySessionID).(string); ok && id != "" {
+		return id
+	}
+	return session.ID()
+}
+
+// rawIDTokenSubject reads the subject of an ID token which has been received directly from the token endpoint,
+// so the TLS connection to the issuer is used instead of checking the signature
+func rawIDTokenSubject(raw string) (string, error) {
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return "", errors.New("malformed id token")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", errors.Wrap(err, "malformed id token payload")
+	}
+
+	var claims struct {
+		Subject string `json:"sub"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return "", errors.Wrap(err, "malformed id token payload")
+	}
+	if claims.Subject == "" {
+		return "", errors.New("id token without subject")
+	}
+
+	return claims.Subject, nil
+}
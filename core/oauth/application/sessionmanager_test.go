@@ -0,0 +1,135 @@
+package application
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"flamingo.me/flamingo/v3/core/oauth/domain"
+	"flamingo.me/flamingo/v3/core/oauth/infrastructure"
+	"flamingo.me/flamingo/v3/framework/flamingo"
+	"flamingo.me/flamingo/v3/framework/web"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+)
+
+func newTestSessionManager(index *infrastructure.MemorySessionIndex, preventSimultaneousSessions bool) *SessionManager {
+	return NewSessionManager(index, new(flamingo.NullLogger), time.Hour, preventSimultaneousSessions, true)
+}
+
+func testLoggedInSession(subject string) *web.Session {
+	session := web.EmptySession()
+	session.Regenerate()
+	session.Store(keyToken, &oauth2.Token{AccessToken: "access"})
+	session.Store(keyRawIDToken, "eyJhbGciOiJSUzI1NiJ9."+base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"`+subject+`"}`))+".signature")
+	return session
+}
+
+func TestSessionManager(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("list and revoke", func(t *testing.T) {
+		manager := newTestSessionManager(new(infrastructure.MemorySessionIndex), false)
+
+		first, second := testLoggedInSession("user"), testLoggedInSession("user")
+		assert.NoError(t, manager.Login(ctx, first))
+		assert.NoError(t, manager.Login(ctx, second))
+
+		sessions, err := manager.Sessions(ctx, "user")
+		assert.NoError(t, err)
+		if assert.Len(t, sessions, 2) {
+			assert.Equal(t, first.ID(), sessions[0].ID)
+			assert.Equal(t, second.ID(), sessions[1].ID)
+		}
+		assert.False(t, manager.Revoked(ctx, first))
+
+		assert.NoError(t, manager.Revoke(ctx, "user", first.ID()))
+		assert.True(t, manager.Revoked(ctx, first))
+		assert.False(t, manager.Revoked(ctx, first), "the session is not indexed anymore")
+		assert.False(t, manager.Revoked(ctx, second))
+
+		assert.NoError(t, manager.RevokeAll(ctx, "user"))
+		assert.True(t, manager.Revoked(ctx, second))
+	})
+
+	t.Run("prevent simultaneous sessions", func(t *testing.T) {
+		manager := newTestSessionManager(new(infrastructure.MemorySessionIndex), true)
+
+		first, second := testLoggedInSession("user"), testLoggedInSession("user")
+		other := testLoggedInSession("other")
+		assert.NoError(t, manager.Login(ctx, first))
+		assert.NoError(t, manager.Login(ctx, other))
+		assert.NoError(t, manager.Login(ctx, second))
+
+		assert.True(t, manager.Revoked(ctx, first), "the previous session is revoked on login")
+		assert.False(t, manager.Revoked(ctx, second))
+		assert.False(t, manager.Revoked(ctx, other), "sessions of other users are kept")
+	})
+
+	t.Run("invalid id token", func(t *testing.T) {
+		manager := newTestSessionManager(new(infrastructure.MemorySessionIndex), false)
+
+		session := web.EmptySession()
+		session.Store(keyRawIDToken, "invalid")
+		assert.EqualError(t, manager.Login(ctx, session), "malformed id token")
+		assert.False(t, manager.Revoked(ctx, session), "sessions which are not indexed are never revoked")
+	})
+}
+
+func TestSessionManager_NotEnforced(t *testing.T) {
+	ctx := context.Background()
+	manager := NewSessionManager(new(infrastructure.MemorySessionIndex), new(flamingo.NullLogger), time.Hour, false, false)
+
+	session := testLoggedInSession("user")
+	assert.NoError(t, manager.Login(ctx, session))
+
+	sessions, err := manager.Sessions(ctx, "user")
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 1, "sessions are indexed without enforced revocation")
+
+	assert.NoError(t, manager.RevokeAll(ctx, "user"))
+	assert.False(t, manager.Revoked(ctx, session), "sessions missing in the index are kept, e.g. after a restart")
+	assert.Equal(t, "user", session.Try(keySessionSubject))
+}
+
+func TestSessionManager_Regenerate(t *testing.T) {
+	ctx := context.Background()
+
+	for name, enforce := range map[string]bool{"enforced": true, "not enforced": false} {
+		t.Run(name, func(t *testing.T) {
+			manager := NewSessionManager(new(infrastructure.MemorySessionIndex), new(flamingo.NullLogger), time.Hour, false, enforce)
+
+			session := testLoggedInSession("user")
+			assert.NoError(t, manager.Login(ctx, session))
+			sessions, _ := manager.Sessions(ctx, "user")
+			loginTime := sessions[0].LoginTime
+
+			session.Regenerate()
+			assert.False(t, manager.Revoked(ctx, session), "regenerated sessions are kept")
+
+			sessions, err := manager.Sessions(ctx, "user")
+			assert.NoError(t, err)
+			assert.Equal(t, []domain.UserSession{{ID: session.ID(), LoginTime: loginTime}}, sessions, "the session is indexed with the new id")
+
+			assert.NoError(t, manager.Logout(ctx, session))
+			sessions, _ = manager.Sessions(ctx, "user")
+			assert.Empty(t, sessions)
+		})
+	}
+}
+
+func TestAuthManager_RevokedSession(t *testing.T) {
+	ctx := context.Background()
+	manager := newTestSessionManager(new(infrastructure.MemorySessionIndex), false)
+	authManager := &AuthManager{sessionManager: manager}
+
+	session := testLoggedInSession("user")
+	assert.NoError(t, manager.Login(ctx, session))
+	assert.NoError(t, manager.RevokeAll(ctx, "user"))
+
+	_, err := authManager.TokenSource(ctx, session)
+	assert.Equal(t, ErrSessionRevoked, err)
+	assert.Nil(t, session.Try(keyToken), "the token details of revoked sessions are removed")
+	assert.Nil(t, session.Try(keyRawIDToken))
+}
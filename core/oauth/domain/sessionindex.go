@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type (
+	// SessionIndex keeps track of the sessions a user is logged in with
+	SessionIndex interface {
+		// Add a session of the subject, the entry expires after the ttl
+		Add(ctx context.Context, subject string, session UserSession, ttl time.Duration) error
+		// Remove a session of the subject
+		Remove(ctx context.Context, subject string, sessionID string) error
+		// RemoveAll sessions of the subject
+		RemoveAll(ctx context.Context, subject string) error
+		// List the active sessions of the subject, ordered by login time
+		List(ctx context.Context, subject string) ([]UserSession, error)
+		// Contains checks if the session of the subject is still active
+		Contains(ctx context.Context, subject string, sessionID string) (bool, error)
+	}
+
+	// UserSession is a session a user logged in with
+	UserSession struct {
+		ID        string
+		LoginTime time.Time
+	}
+)
@@ -0,0 +1,115 @@
+package infrastructure
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"flamingo.me/flamingo/v3/core/oauth/domain"
+)
+
+type (
+	// MemorySessionIndex keeps the session index within the current process
+	MemorySessionIndex struct {
+		mutex    sync.Mutex
+		sessions map[string]map[string]memorySessionEntry
+		now      func() time.Time
+	}
+
+	memorySessionEntry struct {
+		session domain.UserSession
+		expires time.Time
+	}
+)
+
+var _ domain.SessionIndex = new(MemorySessionIndex)
+
+// Add a session of the subject
+func (i *MemorySessionIndex) Add(_ context.Context, subject string, session domain.UserSession, ttl time.Duration) error {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	if i.sessions == nil {
+		i.sessions = make(map[string]map[string]memorySessionEntry)
+	}
+	if i.sessions[subject] == nil {
+		i.sessions[subject] = make(map[string]memorySessionEntry)
+	}
+
+	i.sessions[subject][session.ID] = memorySessionEntry{session: session, expires: i.time().Add(ttl)}
+
+	return nil
+}
+
+// Remove a session of the subject
+func (i *MemorySessionIndex) Remove(_ context.Context, subject string, sessionID string) error {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	delete(i.sessions[subject], sessionID)
+	if len(i.sessions[subject]) == 0 {
+		delete(i.sessions, subject)
+	}
+
+	return nil
+}
+
+// RemoveAll sessions of the subject
+func (i *MemorySessionIndex) RemoveAll(_ context.Context, subject string) error {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	delete(i.sessions, subject)
+
+	return nil
+}
+
+// List the active sessions of the subject, expired entries are removed
+func (i *MemorySessionIndex) List(_ context.Context, subject string) ([]domain.UserSession, error) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	i.expire(subject)
+
+	sessions := make([]domain.UserSession, 0, len(i.sessions[subject]))
+	for _, entry := range i.sessions[subject] {
+		sessions = append(sessions, entry.session)
+	}
+	sort.Slice(sessions, func(a, b int) bool {
+		return sessions[a].LoginTime.Before(sessions[b].LoginTime)
+	})
+
+	return sessions, nil
+}
+
+// Contains checks if the session of the subject is still active
+func (i *MemorySessionIndex) Contains(_ context.Context, subject string, sessionID string) (bool, error) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	i.expire(subject)
+
+	_, ok := i.sessions[subject][sessionID]
+	return ok, nil
+}
+
+// expire removes outdated entries of the subject, the mutex must be held
+func (i *MemorySessionIndex) expire(subject string) {
+	now := i.time()
+	for id, entry := range i.sessions[subject] {
+		if !now.Before(entry.expires) {
+			delete(i.sessions[subject], id)
+		}
+	}
+	if len(i.sessions[subject]) == 0 {
+		delete(i.sessions, subject)
+	}
+}
+
+func (i *MemorySessionIndex) time() time.Time {
+	if i.now == nil {
+		return time.Now()
+	}
+	return i.now()
+}
@@ -0,0 +1,38 @@
+package infrastructure
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"flamingo.me/flamingo/v3/core/oauth/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemorySessionIndex(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	index := &MemorySessionIndex{now: func() time.Time { return now }}
+
+	assert.NoError(t, index.Add(ctx, "user", domain.UserSession{ID: "second", LoginTime: now.Add(time.Second)}, time.Hour))
+	assert.NoError(t, index.Add(ctx, "user", domain.UserSession{ID: "first", LoginTime: now}, time.Minute))
+	assert.NoError(t, index.Add(ctx, "other", domain.UserSession{ID: "other", LoginTime: now}, time.Hour))
+
+	sessions, err := index.List(ctx, "user")
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.UserSession{{ID: "first", LoginTime: now}, {ID: "second", LoginTime: now.Add(time.Second)}}, sessions)
+
+	now = now.Add(2 * time.Minute)
+	ok, err := index.Contains(ctx, "user", "first")
+	assert.NoError(t, err)
+	assert.False(t, ok, "expired sessions are removed")
+
+	assert.NoError(t, index.Remove(ctx, "user", "second"))
+	ok, _ = index.Contains(ctx, "user", "second")
+	assert.False(t, ok)
+
+	assert.NoError(t, index.RemoveAll(ctx, "other"))
+	sessions, err = index.List(ctx, "other")
+	assert.NoError(t, err)
+	assert.Empty(t, sessions)
+}
@@ -0,0 +1,179 @@
+package infrastructure
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"time"
+
+	"flamingo.me/flamingo/v3/core/oauth/domain"
+	"github.com/gomodule/redigo/redis"
+	"github.com/pkg/errors"
+)
+
+type (
+	// RedisSessionIndex shares the session index across all instances using the same redis,
+	// the sessions of a subject are kept in a sorted set scored by their expiry, and a hash with their login times
+	RedisSessionIndex struct {
+		pool   *redis.Pool
+		prefix string
+	}
+)
+
+var (
+	_ domain.SessionIndex = new(RedisSessionIndex)
+
+	// addScript adds the session and lets both keys expire together with the latest session
+	addScript = redis.NewScript(2, `
+redis.call("zadd", KEYS[1], ARGV[1], ARGV[2])
+redis.call("hset", KEYS[2], ARGV[2], ARGV[3])
+local last = redis.call("zrevrange", KEYS[1], 0, 0, "withscores")[2]
+redis.call("pexpireat", KEYS[1], last)
+redis.call("pexpireat", KEYS[2], last)
+return 1`)
+)
+
+// NewRedisSessionIndex creates a session index using the given redis pool, all keys are prefixed with prefix
+func NewRedisSessionIndex(pool *redis.Pool, prefix string) *RedisSessionIndex {
+	return &RedisSessionIndex{pool: pool, prefix: prefix}
+}
+
+// Inject dependencies, without a configured host the redis of the session backend is used
+func (i *RedisSessionIndex) Inject(
+	cfg *struct {
+		Host            string  `inject:"config:oauth.sessionIndex.redis.host"`
+		Password        string  `inject:"config:oauth.sessionIndex.redis.password"`
+		Prefix          string  `inject:"config:oauth.sessionIndex.redis.prefix"`
+		IdleTimeout     float64 `inject:"config:oauth.sessionIndex.redis.idleTimeout"`
+		SessionHost     string  `inject:"config:session.redis.host,optional"`
+		SessionPassword string  `inject:"config:session.redis.password,optional"`
+	},
+) *RedisSessionIndex {
+	host, password := cfg.Host, cfg.Password
+	if host == "" {
+		host, password = cfg.SessionHost, cfg.SessionPassword
+	}
+
+	i.prefix = cfg.Prefix
+	i.pool = &redis.Pool{
+		MaxIdle:     2,
+		IdleTimeout: time.Duration(cfg.IdleTimeout) * time.Millisecond,
+		Dial: func() (redis.Conn, error) {
+			return redis.Dial("tcp", host, redis.DialPassword(password))
+		},
+	}
+	return i
+}
+
+// Add a session of the subject
+func (i *RedisSessionIndex) Add(ctx context.Context, subject string, session domain.UserSession, ttl time.Duration) error {
+	conn, err := i.pool.GetContext(ctx)
+	if err != nil {
+		return errors.Wrap(err, "redis session index")
+	}
+	defer conn.Close()
+
+	expires := millis(time.Now().Add(ttl))
+	if _, err := addScript.Do(conn, i.key(subject), i.loginKey(subject), expires, session.ID, millis(session.LoginTime)); err != nil {
+		return errors.Wrap(err, "redis session index")
+	}
+
+	return nil
+}
+
+// Remove a session of the subject
+func (i *RedisSessionIndex) Remove(ctx context.Context, subject string, sessionID string) error {
+	conn, err := i.pool.GetContext(ctx)
+	if err != nil {
+		return errors.Wrap(err, "redis session index")
+	}
+	defer conn.Close()
+
+	_ = conn.Send("MULTI")
+	_ = conn.Send("ZREM", i.key(subject), sessionID)
+	_ = conn.Send("HDEL", i.loginKey(subject), sessionID)
+	if _, err := conn.Do("EXEC"); err != nil {
+		return errors.Wrap(err, "redis session index")
+	}
+
+	return nil
+}
+
+// RemoveAll sessions of the subject
+func (i *RedisSessionIndex) RemoveAll(ctx context.Context, subject string) error {
+	conn, err := i.pool.GetContext(ctx)
+	if err != nil {
+		return errors.Wrap(err, "redis session index")
+	}
+	defer conn.Close()
+
+	if _, err := conn.Do("DEL", i.key(subject), i.loginKey(subject)); err != nil {
+		return errors.Wrap(err, "redis session index")
+	}
+
+	return nil
+}
+
+// List the active sessions of the subject
+func (i *RedisSessionIndex) List(ctx context.Context, subject string) ([]domain.UserSession, error) {
+	conn, err := i.pool.GetContext(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "redis session index")
+	}
+	defer conn.Close()
+
+	ids, err := redis.Strings(conn.Do("ZRANGEBYSCORE", i.key(subject), "("+strconv.FormatInt(millis(time.Now()), 10), "+inf"))
+	if err != nil {
+		return nil, errors.Wrap(err, "redis session index")
+	}
+	if len(ids) == 0 {
+		return []domain.UserSession{}, nil
+	}
+
+	args := redis.Args{}.Add(i.loginKey(subject)).AddFlat(ids)
+	logins, err := redis.Int64s(conn.Do("HMGET", args...))
+	if err != nil {
+		return nil, errors.Wrap(err, "redis session index")
+	}
+
+	sessions := make([]domain.UserSession, len(ids))
+	for n, id := range ids {
+		sessions[n] = domain.UserSession{ID: id, LoginTime: time.Unix(0, logins[n]*int64(time.Millisecond))}
+	}
+	sort.SliceStable(sessions, func(a, b int) bool {
+		return sessions[a].LoginTime.Before(sessions[b].LoginTime)
+	})
+
+	return sessions, nil
+}
+
+// Contains checks if the session of the subject is still active
+func (i *RedisSessionIndex) Contains(ctx context.Context, subject string, sessionID string) (bool, error) {
+	conn, err := i.pool.GetContext(ctx)
+	if err != nil {
+		return false, errors.Wrap(err, "redis session index")
+	}
+	defer conn.Close()
+
+	expires, err := redis.Int64(conn.Do("ZSCORE", i.key(subject), sessionID))
+	if err == redis.ErrNil {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrap(err, "redis session index")
+	}
+
+	return expires > millis(time.Now()), nil
+}
+
+func (i *RedisSessionIndex) key(subject string) string {
+	return i.prefix + subject
+}
+
+func (i *RedisSessionIndex) loginKey(subject string) string {
+	return i.prefix + subject + ":logins"
+}
+
+func millis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
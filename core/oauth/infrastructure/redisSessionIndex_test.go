@@ -0,0 +1,83 @@
+package infrastructure
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"flamingo.me/flamingo/v3/core/oauth/domain"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gomodule/redigo/redis"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedisSessionIndex(t *testing.T) {
+	server, err := miniredis.Run()
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer server.Close()
+
+	ctx := context.Background()
+	index := NewRedisSessionIndex(&redis.Pool{Dial: func() (redis.Conn, error) {
+		return redis.Dial("tcp", server.Addr())
+	}}, "sessions:")
+
+	now := time.Now().Truncate(time.Millisecond)
+	assert.NoError(t, index.Add(ctx, "user", domain.UserSession{ID: "second", LoginTime: now.Add(time.Second)}, time.Hour))
+	assert.NoError(t, index.Add(ctx, "user", domain.UserSession{ID: "first", LoginTime: now}, time.Minute))
+	assert.NoError(t, index.Add(ctx, "other", domain.UserSession{ID: "other", LoginTime: now}, time.Hour))
+
+	sessions, err := index.List(ctx, "user")
+	assert.NoError(t, err)
+	if assert.Len(t, sessions, 2) {
+		assert.Equal(t, "first", sessions[0].ID)
+		assert.True(t, now.Equal(sessions[0].LoginTime))
+		assert.Equal(t, "second", sessions[1].ID)
+		assert.True(t, now.Add(time.Second).Equal(sessions[1].LoginTime))
+	}
+
+	ok, err := index.Contains(ctx, "user", "first")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = index.Contains(ctx, "user", "unknown")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	t.Run("keys expire with the latest session", func(t *testing.T) {
+		ttl := server.TTL("sessions:user")
+		assert.True(t, ttl > 59*time.Minute && ttl <= time.Hour, "ttl %s", ttl)
+		assert.InDelta(t, ttl, server.TTL("sessions:user:logins"), float64(time.Second))
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		assert.NoError(t, index.Remove(ctx, "user", "second"))
+		ok, _ := index.Contains(ctx, "user", "second")
+		assert.False(t, ok)
+		logins, _ := server.HKeys("sessions:user:logins")
+		assert.Equal(t, []string{"first"}, logins)
+
+		assert.NoError(t, index.RemoveAll(ctx, "other"))
+		sessions, err := index.List(ctx, "other")
+		assert.NoError(t, err)
+		assert.Empty(t, sessions)
+		assert.False(t, server.Exists("sessions:other"))
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		assert.NoError(t, index.Add(ctx, "expiring", domain.UserSession{ID: "session", LoginTime: now}, time.Minute))
+		server.FastForward(2 * time.Minute)
+		assert.False(t, server.Exists("sessions:expiring"))
+		assert.False(t, server.Exists("sessions:expiring:logins"))
+	})
+
+	t.Run("unavailable", func(t *testing.T) {
+		server.SetError("server down")
+		defer server.SetError("")
+
+		_, err := index.Contains(ctx, "user", "first")
+		assert.Error(t, err)
+		_, err = index.List(ctx, "user")
+		assert.Error(t, err)
+	})
+}
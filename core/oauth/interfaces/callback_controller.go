@@ -97,7 +97,7 @@ func (cc *CallbackController) Get(ctx context.Context, request *web.Request) web
 
 		cc.eventPublisher.PublishLoginEvent(ctx, &domain.LoginEvent{Session: request.Session()})
 		cc.logger.Debug("successful logged in and saved tokens", oauth2Token)
-		request.Session().AddFlash("successful logged in")
+		request.Session().AddFlashMessage(web.FlashSuccess, "successful logged in", nil)
 	} else if errCode != "" {
 		cc.logger.Error("core.auth.callback Error parameter", errCode)
 	}
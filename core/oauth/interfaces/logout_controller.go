@@ -102,7 +102,7 @@ func (l *LogoutController) Get(ctx context.Context, request *web.Request) web.Re
 	}
 
 	l.logoutLocally(ctx, request)
-	request.Session().AddFlash("successful logged out")
+	request.Session().AddFlashMessage(web.FlashSuccess, "successful logged out", nil)
 
 	return l.responder.URLRedirect(redirectURL)
 }
@@ -4,6 +4,8 @@ import (
 	"flamingo.me/dingo"
 	"flamingo.me/flamingo/v3/core/oauth/application"
 	fakeService "flamingo.me/flamingo/v3/core/oauth/application/fake"
+	"flamingo.me/flamingo/v3/core/oauth/domain"
+	"flamingo.me/flamingo/v3/core/oauth/infrastructure"
 	"flamingo.me/flamingo/v3/core/oauth/interfaces"
 	fakeController "flamingo.me/flamingo/v3/core/oauth/interfaces/fake"
 	"flamingo.me/flamingo/v3/core/security/application/role"
@@ -17,6 +19,7 @@ type Module struct {
 	UseFake                     bool   `inject:"config:oauth.useFake"`
 	PreventSimultaneousSessions bool   `inject:"config:oauth.preventSimultaneousSessions"`
 	SessionBackend              string `inject:"config:session.backend"`
+	SessionIndexBackend         string `inject:"config:oauth.sessionIndex.backend,optional"`
 }
 
 // Configure core.auth module
@@ -24,6 +27,24 @@ func (m *Module) Configure(injector *dingo.Injector) {
 	injector.Bind(application.AuthManager{}).In(dingo.ChildSingleton)
 	injector.Bind(new(interfaces.LogoutRedirectAware)).To(interfaces.DefaultLogoutRedirect{})
 	flamingo.BindEventSubscriber(injector).To(&application.EventHandler{})
+	flamingo.BindSessionKeys(injector, application.SessionKeys...)
+	flamingo.BindSessionKeys(injector, flamingo.SessionKey{Key: fakeService.UserSessionKey, Type: domain.User{}, Version: 1})
+
+	// without an explicitly configured index the sessions are indexed where they are stored
+	indexBackend := m.SessionIndexBackend
+	if indexBackend == "" && m.SessionBackend == "redis" {
+		indexBackend = "redis"
+	}
+
+	switch indexBackend {
+	case "redis":
+		injector.Bind(infrastructure.RedisSessionIndex{}).In(dingo.Singleton)
+		injector.Bind(new(domain.SessionIndex)).To(infrastructure.RedisSessionIndex{})
+	default:
+		injector.Bind(infrastructure.MemorySessionIndex{}).In(dingo.Singleton)
+		injector.Bind(new(domain.SessionIndex)).To(infrastructure.MemorySessionIndex{})
+	}
+
 	if !m.UseFake {
 		injector.Bind(new(application.UserServiceInterface)).To(application.UserService{})
 		injector.Bind(new(interfaces.LoginControllerInterface)).To(interfaces.LoginController{})
@@ -67,6 +88,15 @@ func (m *Module) DefaultConfig() config.Map {
 				},
 			},
 			"preventSimultaneousSessions": false,
+			"sessionIndex": config.Map{
+				"backend": "",
+				"redis": config.Map{
+					"host":        "",
+					"password":    "",
+					"prefix":      "flamingo:oauth:sessions:",
+					"idleTimeout": float64(240000),
+				},
+			},
 		},
 	}
 }
@@ -5,7 +5,10 @@ import (
 
 	"flamingo.me/dingo"
 	"flamingo.me/flamingo/v3/core/oauth"
+	"flamingo.me/flamingo/v3/core/oauth/domain"
+	"flamingo.me/flamingo/v3/core/oauth/infrastructure"
 	"flamingo.me/flamingo/v3/framework/config"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestModule_Configure(t *testing.T) {
@@ -14,8 +17,35 @@ func TestModule_Configure(t *testing.T) {
 	}
 
 	cfgModule.Map["session.backend"] = ""
+	cfgModule.Map["session.max.age"] = float64(3600)
 
 	if err := dingo.TryModule(cfgModule, new(oauth.Module)); err != nil {
 		t.Error(err)
 	}
 }
+
+func TestModule_SessionIndex(t *testing.T) {
+	for name, tt := range map[string]struct {
+		sessionBackend string
+		indexBackend   string
+		expected       domain.SessionIndex
+	}{
+		"memory sessions":       {sessionBackend: "memory", expected: new(infrastructure.MemorySessionIndex)},
+		"redis sessions":        {sessionBackend: "redis", expected: new(infrastructure.RedisSessionIndex)},
+		"explicit memory index": {sessionBackend: "redis", indexBackend: "memory", expected: new(infrastructure.MemorySessionIndex)},
+		"explicit redis index":  {sessionBackend: "cookie", indexBackend: "redis", expected: new(infrastructure.RedisSessionIndex)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			cfg := make(config.Map)
+			assert.NoError(t, cfg.Add(new(oauth.Module).DefaultConfig()))
+			assert.NoError(t, cfg.Add(config.Map{
+				"session.backend":            tt.sessionBackend,
+				"session.max.age":            float64(3600),
+				"oauth.sessionIndex.backend": tt.indexBackend,
+			}))
+
+			injector := dingo.NewInjector(&config.Module{Map: cfg}, new(oauth.Module))
+			assert.IsType(t, tt.expected, injector.GetInstance(new(domain.SessionIndex)))
+		})
+	}
+}
@@ -5,17 +5,37 @@ import (
 	"errors"
 	"net/http"
 	"sync"
+	"time"
 
+	"flamingo.me/flamingo/v3/framework/flamingo"
 	"flamingo.me/flamingo/v3/framework/web"
 	"go.opencensus.io/trace"
 )
 
 type (
-	filter struct{}
-	rKey   string
+	filter struct {
+		logger  flamingo.Logger
+		limit   int
+		timeout time.Duration
+	}
+
+	rKey string
+
+	// requestTasks is the state of the tasks of a request
+	requestTasks struct {
+		wg      sync.WaitGroup
+		limit   int
+		timeout time.Duration
+		// sem limits the concurrently running tasks of all groups and TryDo calls of the request
+		sem chan struct{}
+
+		mutex  sync.Mutex
+		after  []func(ctx context.Context) error
+		closed bool
+	}
 )
 
-const wg rKey = "requestTaskWg"
+const tasksKey rKey = "requestTasks"
 
 // Do runs a background task in the current request scope
 func Do(ctx context.Context, r *web.Request, task func(ctx context.Context, r *web.Request)) {
@@ -24,35 +44,161 @@ func Do(ctx context.Context, r *web.Request, task func(ctx context.Context, r *w
 	}
 }
 
-// TryDo tries to schedule an async task in the background
+// TryDo tries to schedule an async task in the background.
+// It fails if the request is unable to run background tasks, or if the concurrency limit of the request is reached.
 func TryDo(ctx context.Context, r *web.Request, task func(ctx context.Context, r *web.Request)) error {
-	wg, _ := r.Values.Load(wg)
-	if wg, ok := wg.(*sync.WaitGroup); ok {
-		wg.Add(1)
+	tasks := requestTasksOf(r)
+	if tasks == nil {
+		return errors.New("the current request is unable to schedule background tasks")
+	}
 
-		go func() {
-			ctx, span := trace.StartSpan(ctx, "requestTask")
-			task(ctx, r)
-			span.End()
-			wg.Done()
-		}()
+	if !tasks.tryAcquire() {
+		return errors.New("the concurrency limit of the request is reached")
+	}
+	tasks.wg.Add(1)
+
+	go func() {
+		ctx, span := trace.StartSpan(ctx, "requestTask")
+		task(ctx, r)
+		span.End()
+		tasks.release()
+		tasks.wg.Done()
+	}()
+
+	return nil
+}
+
+// AfterResponse schedules a task to run once the response has been applied, so the client is not delayed by it.
+// The task's context keeps the values of the request context, but it is not canceled when the request is done.
+// Failing tasks are logged.
+func AfterResponse(r *web.Request, task func(ctx context.Context) error) error {
+	tasks := requestTasksOf(r)
+	if tasks == nil {
+		return errors.New("the current request is unable to schedule tasks after the response")
+	}
 
+	tasks.mutex.Lock()
+	defer tasks.mutex.Unlock()
+
+	if tasks.closed {
+		return errors.New("the response has already been applied")
+	}
+	tasks.after = append(tasks.after, task)
+
+	return nil
+}
+
+func requestTasksOf(r *web.Request) *requestTasks {
+	if r == nil {
 		return nil
 	}
 
-	return errors.New("the current request is unable to schedule background tasks")
+	tasks, _ := r.Values.Load(tasksKey)
+	if tasks, ok := tasks.(*requestTasks); ok {
+		return tasks
+	}
+	return nil
+}
+
+func newRequestTasks(limit int, timeout time.Duration) *requestTasks {
+	tasks := &requestTasks{limit: limit, timeout: timeout}
+	if limit > 0 {
+		tasks.sem = make(chan struct{}, limit)
+	}
+	return tasks
+}
+
+// acquire a slot of the concurrency limit, it fails if ctx is done first
+func (t *requestTasks) acquire(ctx context.Context) error {
+	if t.sem == nil {
+		return nil
+	}
+
+	select {
+	case t.sem <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// tryAcquire a slot of the concurrency limit without blocking
+func (t *requestTasks) tryAcquire() bool {
+	if t.sem == nil {
+		return true
+	}
+
+	select {
+	case t.sem <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// release an acquired slot
+func (t *requestTasks) release() {
+	if t.sem != nil {
+		<-t.sem
+	}
+}
+
+// Inject dependencies
+func (f *filter) Inject(
+	logger flamingo.Logger,
+	cfg *struct {
+		Concurrency float64 `inject:"config:requesttask.concurrency"`
+		Timeout     float64 `inject:"config:requesttask.timeout"`
+	},
+) *filter {
+	f.logger = logger.WithField(flamingo.LogKeyCategory, "requesttask")
+	if cfg != nil {
+		f.limit = int(cfg.Concurrency)
+		f.timeout = time.Duration(cfg.Timeout) * time.Millisecond
+	}
+	return f
 }
 
 // Filter waits for running tasks to finish before the request processing is done
 func (f *filter) Filter(ctx context.Context, r *web.Request, w http.ResponseWriter, fc *web.FilterChain) web.Result {
-	r.Values.Store(wg, new(sync.WaitGroup))
+	tasks := newRequestTasks(f.limit, f.timeout)
+	r.Values.Store(tasksKey, tasks)
+
+	fc.AddPostApply(func(error, web.Result) {
+		f.runAfterResponse(ctx, tasks)
+	})
+
 	response := fc.Next(ctx, r, w)
 
 	// wait for possible tasks to finish
-	wg, _ := r.Values.Load(wg)
-	if wg, ok := wg.(*sync.WaitGroup); ok {
-		wg.Wait()
-	}
+	tasks.wg.Wait()
 
 	return response
 }
+
+// runAfterResponse runs the scheduled tasks in the background, with the concurrency limit and timeout of the request
+func (f *filter) runAfterResponse(ctx context.Context, tasks *requestTasks) {
+	tasks.mutex.Lock()
+	after := tasks.after
+	tasks.after = nil
+	tasks.closed = true
+	tasks.mutex.Unlock()
+
+	if len(after) == 0 {
+		return
+	}
+
+	go func() {
+		group := (&Group{limit: tasks.limit, timeout: tasks.timeout}).init(flamingo.DetachedContext(ctx))
+		for _, task := range after {
+			task := task
+			group.Go(func(ctx context.Context) (interface{}, error) {
+				return nil, task(ctx)
+			})
+		}
+
+		if _, err := group.Wait(); err != nil && f.logger != nil {
+			f.logger.WithContext(ctx).Error("tasks after response failed: ", err)
+		}
+	}()
+}
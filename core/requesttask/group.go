@@ -0,0 +1,218 @@
+package requesttask
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"flamingo.me/flamingo/v3/framework/web"
+	"go.opencensus.io/trace"
+)
+
+type (
+	// Task is run by a Group, its result is collected
+	Task func(ctx context.Context) (interface{}, error)
+
+	// Result of a task
+	Result struct {
+		Value interface{}
+		Err   error
+	}
+
+	// Errors are the collected errors of all failed tasks of a Group
+	Errors []error
+
+	// GroupOption configures a Group
+	GroupOption func(*Group)
+
+	// Group runs tasks concurrently with a concurrency limit and a timeout per task, and collects their results.
+	// Tasks of a group created for a request share the concurrency limit of the request, and are awaited by the requesttask filter.
+	Group struct {
+		ctx     context.Context
+		cancel  context.CancelFunc
+		limit   int
+		timeout time.Duration
+		sem     chan struct{}
+		request *requestTasks
+
+		wg      sync.WaitGroup
+		mutex   sync.Mutex
+		results []Result
+	}
+)
+
+// WithLimit sets the maximum number of concurrently running tasks of the group, 0 means unlimited.
+// The concurrency limit of the request applies in addition.
+func WithLimit(limit int) GroupOption {
+	return func(g *Group) {
+		g.limit = limit
+	}
+}
+
+// WithTimeout sets the timeout of every task, 0 means the tasks are only limited by the context of the group
+func WithTimeout(timeout time.Duration) GroupOption {
+	return func(g *Group) {
+		g.timeout = timeout
+	}
+}
+
+// NewGroup creates a Group for the request. Its tasks share the concurrency limit `requesttask.concurrency`
+// with all other tasks of the request, the timeout defaults to `requesttask.timeout`.
+// Without a request the group is unlimited, unless WithLimit is used.
+// The tasks get a context derived from ctx, which is canceled by Cancel or when Wait returns.
+func NewGroup(ctx context.Context, r *web.Request, options ...GroupOption) *Group {
+	g := new(Group)
+
+	if tasks := requestTasksOf(r); tasks != nil {
+		g.request = tasks
+		g.timeout = tasks.timeout
+	}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	return g.init(ctx)
+}
+
+func (g *Group) init(ctx context.Context) *Group {
+	g.ctx, g.cancel = context.WithCancel(ctx)
+	if g.limit > 0 {
+		g.sem = make(chan struct{}, g.limit)
+	}
+	return g
+}
+
+// Go runs the task in the background. If the concurrency limit of the group or the request is reached,
+// Go blocks until a running task has finished. If the group is canceled meanwhile, the task is not run and its result is the context error.
+// A task waiting for another group of the same request can deadlock, once all slots of the request are taken.
+func (g *Group) Go(task Task) {
+	g.mutex.Lock()
+	index := len(g.results)
+	g.results = append(g.results, Result{})
+	g.mutex.Unlock()
+
+	g.wg.Add(1)
+	if g.request != nil {
+		g.request.wg.Add(1)
+	}
+
+	if err := g.acquire(); err != nil {
+		g.done(index, Result{Err: err})
+		return
+	}
+
+	go func() {
+		result := g.run(task)
+		g.release()
+		g.done(index, result)
+	}()
+}
+
+// acquire a slot of the group and of the request
+func (g *Group) acquire() error {
+	if g.sem != nil {
+		select {
+		case g.sem <- struct{}{}:
+		case <-g.ctx.Done():
+			return g.ctx.Err()
+		}
+	}
+
+	if g.request != nil {
+		if err := g.request.acquire(g.ctx); err != nil {
+			if g.sem != nil {
+				<-g.sem
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+// release the slots of the group and of the request
+func (g *Group) release() {
+	if g.request != nil {
+		g.request.release()
+	}
+	if g.sem != nil {
+		<-g.sem
+	}
+}
+
+func (g *Group) run(task Task) (result Result) {
+	ctx := g.ctx
+	if g.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, g.timeout)
+		defer cancel()
+	}
+
+	ctx, span := trace.StartSpan(ctx, "requestTask")
+	defer span.End()
+
+	defer func() {
+		if p := recover(); p != nil {
+			result = Result{Err: fmt.Errorf("task panicked: %v", p)}
+		}
+		if result.Err != nil {
+			span.SetStatus(trace.Status{Code: trace.StatusCodeUnknown, Message: result.Err.Error()})
+		}
+	}()
+
+	value, err := task(ctx)
+	return Result{Value: value, Err: err}
+}
+
+func (g *Group) done(index int, result Result) {
+	g.mutex.Lock()
+	g.results[index] = result
+	g.mutex.Unlock()
+
+	g.wg.Done()
+	if g.request != nil {
+		g.request.wg.Done()
+	}
+}
+
+// Cancel the context of all tasks, tasks waiting for a free slot are not run anymore
+func (g *Group) Cancel() {
+	g.cancel()
+}
+
+// Wait for all tasks and return their results in the order the tasks have been added.
+// The error contains the errors of all failed tasks as Errors, or is nil if all tasks succeeded.
+func (g *Group) Wait() ([]Result, error) {
+	g.wg.Wait()
+	g.cancel()
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	results := make([]Result, len(g.results))
+	copy(results, g.results)
+
+	var errs Errors
+	for _, result := range results {
+		if result.Err != nil {
+			errs = append(errs, result.Err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return results, errs
+	}
+	return results, nil
+}
+
+// Error returns all error messages
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
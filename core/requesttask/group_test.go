@@ -0,0 +1,187 @@
+package requesttask
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"flamingo.me/flamingo/v3/framework/flamingo"
+	"flamingo.me/flamingo/v3/framework/web"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroup_Results(t *testing.T) {
+	group := NewGroup(context.Background(), nil)
+
+	group.Go(func(context.Context) (interface{}, error) {
+		time.Sleep(10 * time.Millisecond)
+		return "first", nil
+	})
+	group.Go(func(context.Context) (interface{}, error) {
+		return nil, errors.New("second failed")
+	})
+	group.Go(func(context.Context) (interface{}, error) {
+		panic("third")
+	})
+	group.Go(func(context.Context) (interface{}, error) {
+		return 4, nil
+	})
+
+	results, err := group.Wait()
+	assert.Len(t, results, 4)
+	assert.Equal(t, "first", results[0].Value)
+	assert.EqualError(t, results[1].Err, "second failed")
+	assert.Contains(t, results[2].Err.Error(), "third")
+	assert.Equal(t, 4, results[3].Value)
+
+	assert.Error(t, err)
+	assert.Len(t, err.(Errors), 2)
+}
+
+func TestGroup_Limit(t *testing.T) {
+	group := NewGroup(context.Background(), nil, WithLimit(2))
+
+	var running, max int32
+	for i := 0; i < 10; i++ {
+		group.Go(func(context.Context) (interface{}, error) {
+			current := atomic.AddInt32(&running, 1)
+			for {
+				m := atomic.LoadInt32(&max)
+				if current <= m || atomic.CompareAndSwapInt32(&max, m, current) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			return nil, nil
+		})
+	}
+
+	_, err := group.Wait()
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&max))
+}
+
+func TestGroup_TimeoutAndCancel(t *testing.T) {
+	blocking := func(ctx context.Context) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	group := NewGroup(context.Background(), nil, WithTimeout(10*time.Millisecond))
+	group.Go(blocking)
+	results, _ := group.Wait()
+	assert.Equal(t, context.DeadlineExceeded, results[0].Err)
+
+	group = NewGroup(context.Background(), nil, WithLimit(1))
+	group.Go(blocking)
+	group.Cancel()
+	group.Go(blocking)
+	results, _ = group.Wait()
+	assert.Equal(t, context.Canceled, results[0].Err)
+	assert.Equal(t, context.Canceled, results[1].Err)
+}
+
+func TestFilter(t *testing.T) {
+	f := new(filter).Inject(flamingo.NullLogger{}, &struct {
+		Concurrency float64 `inject:"config:requesttask.concurrency"`
+		Timeout     float64 `inject:"config:requesttask.timeout"`
+	}{Concurrency: 1, Timeout: 1000})
+
+	request := web.CreateRequest(httptest.NewRequest(http.MethodGet, "/", nil), nil)
+	assert.Error(t, AfterResponse(request, func(context.Context) error { return nil }), "no filter, no tasks after response")
+
+	var done int32
+	var after sync.WaitGroup
+	after.Add(2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	chain := web.NewFilterChain(func(ctx context.Context, r *web.Request, w http.ResponseWriter) web.Result {
+		group := NewGroup(ctx, r)
+		assert.Equal(t, 1, cap(group.request.sem), "limit of the request")
+		assert.Equal(t, time.Second, group.timeout, "timeout of the request")
+		group.Go(func(context.Context) (interface{}, error) {
+			time.Sleep(10 * time.Millisecond)
+			atomic.AddInt32(&done, 1)
+			return nil, nil
+		})
+
+		for i := 0; i < 2; i++ {
+			assert.NoError(t, AfterResponse(r, func(ctx context.Context) error {
+				defer after.Done()
+				assert.NoError(t, ctx.Err(), "tasks after response are not canceled with the request")
+				return nil
+			}))
+		}
+		return nil
+	})
+
+	f.Filter(ctx, request, httptest.NewRecorder(), chain)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&done), "filter waits for the group")
+
+	tasks := requestTasksOf(request)
+	assert.Len(t, tasks.after, 2, "tasks after response are not run before the response is applied")
+
+	// the request is done, so its context is canceled
+	cancel()
+	f.runAfterResponse(ctx, tasks)
+	after.Wait()
+
+	assert.Error(t, AfterResponse(request, func(context.Context) error { return nil }), "response is already applied")
+}
+
+func TestFilter_RequestLimit(t *testing.T) {
+	f := new(filter).Inject(flamingo.NullLogger{}, &struct {
+		Concurrency float64 `inject:"config:requesttask.concurrency"`
+		Timeout     float64 `inject:"config:requesttask.timeout"`
+	}{Concurrency: 2})
+
+	var running, max int32
+	task := func() {
+		current := atomic.AddInt32(&running, 1)
+		for {
+			m := atomic.LoadInt32(&max)
+			if current <= m || atomic.CompareAndSwapInt32(&max, m, current) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+	}
+
+	chain := web.NewFilterChain(func(ctx context.Context, r *web.Request, w http.ResponseWriter) web.Result {
+		groups := []*Group{NewGroup(ctx, r), NewGroup(ctx, r)}
+		for i := 0; i < 10; i++ {
+			groups[i%2].Go(func(context.Context) (interface{}, error) {
+				task()
+				return nil, nil
+			})
+		}
+
+		for _, group := range groups {
+			_, err := group.Wait()
+			assert.NoError(t, err)
+		}
+
+		// TryDo takes the slots of the limit as well, if none is left Do runs the task directly
+		release := make(chan struct{})
+		for i := 0; i < 2; i++ {
+			assert.NoError(t, TryDo(ctx, r, func(context.Context, *web.Request) { <-release }))
+		}
+		assert.Error(t, TryDo(ctx, r, func(context.Context, *web.Request) {}), "limit of the request reached")
+
+		var direct bool
+		Do(ctx, r, func(context.Context, *web.Request) { direct = true })
+		assert.True(t, direct, "Do runs the task directly")
+		close(release)
+		return nil
+	})
+
+	f.Filter(context.Background(), web.CreateRequest(httptest.NewRequest(http.MethodGet, "/", nil), nil), httptest.NewRecorder(), chain)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&max), "limit shared by the groups of the request")
+}
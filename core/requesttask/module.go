@@ -2,6 +2,7 @@ package requesttask
 
 import (
 	"flamingo.me/dingo"
+	"flamingo.me/flamingo/v3/framework/config"
 	"flamingo.me/flamingo/v3/framework/web"
 )
 
@@ -12,3 +13,13 @@ type Module struct{}
 func (m *Module) Configure(injector *dingo.Injector) {
 	injector.BindMulti(new(web.Filter)).To(new(filter))
 }
+
+// DefaultConfig for the module
+func (m *Module) DefaultConfig() config.Map {
+	return config.Map{
+		"requesttask": config.Map{
+			"concurrency": float64(10),
+			"timeout":     float64(0),
+		},
+	}
+}
@@ -1,13 +1,15 @@
 package requesttask_test
 
 import (
+	"testing"
+
 	"flamingo.me/dingo"
 	"flamingo.me/flamingo/v3/core/requesttask"
-	"testing"
+	"flamingo.me/flamingo/v3/framework/config"
 )
 
 func TestModule_Configure(t *testing.T) {
-	if err := dingo.TryModule(new(requesttask.Module)); err != nil {
+	if err := dingo.TryModule(&config.Module{Map: new(requesttask.Module).DefaultConfig()}, new(requesttask.Module)); err != nil {
 		t.Error(err)
 	}
 }
@@ -0,0 +1,365 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"flamingo.me/flamingo/v3/core/scheduler/domain"
+	"flamingo.me/flamingo/v3/framework/config"
+	"flamingo.me/flamingo/v3/framework/flamingo"
+	"github.com/pkg/errors"
+	"go.opencensus.io/trace"
+)
+
+type (
+	// JobConfig is the configuration of a job in `scheduler.jobs.<name>`, all durations are in milliseconds
+	JobConfig struct {
+		Cron     string
+		Interval float64
+		Jitter   float64
+		Timeout  float64
+		Lock     bool
+	}
+
+	// JobInfo describes a bound job
+	JobInfo struct {
+		Name     string
+		Schedule string
+		// Next run, zero if the job is not scheduled
+		Next    time.Time
+		Timeout time.Duration
+		Lock    bool
+	}
+
+	// Scheduler runs the configured jobs in the background while the server is running
+	Scheduler struct {
+		jobProvider     jobProvider
+		locker          domain.Locker
+		logger          flamingo.Logger
+		jobConfigs      config.Map
+		enabled         bool
+		shutdownTimeout time.Duration
+
+		mutex   sync.Mutex
+		running bool
+		stop    chan struct{}
+		cancel  context.CancelFunc
+		wg      sync.WaitGroup
+	}
+
+	jobProvider func() []domain.Job
+
+	scheduledJob struct {
+		job      domain.Job
+		config   JobConfig
+		schedule domain.Schedule
+	}
+)
+
+const defaultLockTTL = time.Hour
+
+// NewScheduler creates an enabled scheduler for the given jobs and their configuration, e.g. for usage without dependency injection
+func NewScheduler(jobs func() []domain.Job, locker domain.Locker, logger flamingo.Logger, shutdownTimeout time.Duration, jobConfigs config.Map) *Scheduler {
+	return new(Scheduler).init(jobs, locker, logger, true, shutdownTimeout, jobConfigs)
+}
+
+// Inject dependencies
+func (s *Scheduler) Inject(
+	jobProvider jobProvider,
+	locker domain.Locker,
+	logger flamingo.Logger,
+	cfg *struct {
+		Enabled         bool       `inject:"config:scheduler.enabled"`
+		ShutdownTimeout float64    `inject:"config:scheduler.shutdownTimeout"`
+		Jobs            config.Map `inject:"config:scheduler.jobs"`
+	},
+) *Scheduler {
+	if cfg == nil {
+		return s.init(jobProvider, locker, logger, false, 0, nil)
+	}
+	return s.init(jobProvider, locker, logger, cfg.Enabled, time.Duration(cfg.ShutdownTimeout)*time.Millisecond, cfg.Jobs)
+}
+
+func (s *Scheduler) init(jobs func() []domain.Job, locker domain.Locker, logger flamingo.Logger, enabled bool, shutdownTimeout time.Duration, jobConfigs config.Map) *Scheduler {
+	s.jobProvider = jobs
+	s.locker = locker
+	s.logger = logger.WithField(flamingo.LogKeyCategory, "scheduler")
+	s.enabled = enabled
+	s.shutdownTimeout = shutdownTimeout
+	s.jobConfigs = jobConfigs
+	return s
+}
+
+// Notify starts the scheduler with the server (see ServerStartEvent, dispatched by the serve commands) and stops it on shutdown
+func (s *Scheduler) Notify(ctx context.Context, event flamingo.Event) {
+	switch event.(type) {
+	case *flamingo.ServerStartEvent:
+		if !s.enabled {
+			return
+		}
+		if err := s.Start(); err != nil {
+			s.logger.Error(err)
+		}
+
+	case *flamingo.ShutdownEvent:
+		if s.shutdownTimeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, s.shutdownTimeout)
+			defer cancel()
+		}
+		s.Stop(ctx)
+	}
+}
+
+// EventTypes of the Scheduler
+func (s *Scheduler) EventTypes() []flamingo.Event {
+	return []flamingo.Event{new(flamingo.ServerStartEvent), new(flamingo.ShutdownEvent)}
+}
+
+// Start running all scheduled jobs in the background
+func (s *Scheduler) Start() error {
+	jobs, err := s.jobs()
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.running {
+		return nil
+	}
+	s.running = true
+	s.stop = make(chan struct{})
+
+	var ctx context.Context
+	ctx, s.cancel = context.WithCancel(context.Background())
+
+	for _, job := range jobs {
+		if job.schedule == nil {
+			continue
+		}
+
+		s.logger.Info("scheduling job ", job.job.Name())
+		s.wg.Add(1)
+		go s.loop(ctx, job)
+	}
+
+	return nil
+}
+
+// Stop scheduling jobs and wait for the running jobs. When ctx is done, the context of the running jobs is canceled.
+func (s *Scheduler) Stop(ctx context.Context) {
+	s.mutex.Lock()
+	if !s.running {
+		s.mutex.Unlock()
+		return
+	}
+	s.running = false
+	close(s.stop)
+	s.mutex.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		s.logger.Warn("canceling running jobs")
+		s.cancel()
+		<-done
+	}
+	s.cancel()
+}
+
+// Run a job once, regardless of its schedule
+func (s *Scheduler) Run(ctx context.Context, name string) error {
+	jobs, err := s.jobs()
+	if err != nil {
+		return err
+	}
+
+	for _, job := range jobs {
+		if job.job.Name() == name {
+			return s.execute(ctx, job)
+		}
+	}
+
+	return errors.Errorf("job %q not found", name)
+}
+
+// Jobs returns information about all bound jobs, sorted by name
+func (s *Scheduler) Jobs() ([]JobInfo, error) {
+	jobs, err := s.jobs()
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]JobInfo, len(jobs))
+	for i, job := range jobs {
+		infos[i] = JobInfo{
+			Name:     job.job.Name(),
+			Schedule: "-",
+			Timeout:  milliseconds(job.config.Timeout),
+			Lock:     job.config.Lock,
+		}
+
+		switch {
+		case job.config.Cron != "":
+			infos[i].Schedule = job.config.Cron
+		case job.config.Interval > 0:
+			infos[i].Schedule = fmt.Sprintf("every %s", milliseconds(job.config.Interval))
+		}
+
+		if job.schedule != nil {
+			infos[i].Next = job.schedule.Next(time.Now())
+		}
+	}
+
+	return infos, nil
+}
+
+// jobs returns the bound jobs with their configuration, sorted by name
+func (s *Scheduler) jobs() ([]*scheduledJob, error) {
+	var bound []domain.Job
+	if s.jobProvider != nil {
+		bound = s.jobProvider()
+	}
+
+	jobs := make([]*scheduledJob, 0, len(bound))
+	names := make(map[string]bool, len(bound))
+	for _, job := range bound {
+		name := job.Name()
+		if names[name] {
+			return nil, errors.Errorf("job %q is bound more than once", name)
+		}
+		names[name] = true
+
+		scheduled := &scheduledJob{job: job}
+		if cfg, ok := s.jobConfigs[name].(config.Map); ok {
+			if err := cfg.MapInto(&scheduled.config); err != nil {
+				return nil, errors.Wrapf(err, "job %q", name)
+			}
+		}
+
+		var err error
+		switch {
+		case scheduled.config.Cron != "" && scheduled.config.Interval > 0:
+			return nil, errors.Errorf("job %q: either cron or interval can be configured", name)
+		case scheduled.config.Cron != "":
+			if scheduled.schedule, err = domain.ParseCron(scheduled.config.Cron); err != nil {
+				return nil, errors.Wrapf(err, "job %q", name)
+			}
+		case scheduled.config.Interval > 0:
+			scheduled.schedule = &domain.IntervalSchedule{Interval: milliseconds(scheduled.config.Interval)}
+		}
+
+		jobs = append(jobs, scheduled)
+	}
+
+	for name := range s.jobConfigs {
+		if !names[name] {
+			s.logger.Warn("job ", name, " is configured, but not bound")
+		}
+	}
+
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].job.Name() < jobs[j].job.Name()
+	})
+
+	return jobs, nil
+}
+
+func (s *Scheduler) loop(ctx context.Context, job *scheduledJob) {
+	defer s.wg.Done()
+
+	name := job.job.Name()
+	for {
+		next := job.schedule.Next(time.Now())
+		if next.IsZero() {
+			s.logger.Warn("job ", name, " has no next run")
+			return
+		}
+		if jitter := milliseconds(job.config.Jitter); jitter > 0 {
+			next = next.Add(time.Duration(rand.Int63n(int64(jitter))))
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-s.stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		// the timer might have fired at the same time as the scheduler was stopped
+		select {
+		case <-s.stop:
+			return
+		default:
+		}
+
+		start := time.Now()
+		err := s.execute(ctx, job)
+		switch {
+		case err == domain.ErrLocked:
+			s.logger.Debug("job ", name, " skipped, it is locked")
+		case err != nil:
+			s.logger.Error("job ", name, " failed: ", err)
+		default:
+			s.logger.Debug("job ", name, " done in ", time.Since(start))
+		}
+	}
+}
+
+// execute a job with its timeout and lock, panics are returned as error
+func (s *Scheduler) execute(ctx context.Context, job *scheduledJob) (err error) {
+	name := job.job.Name()
+
+	ctx, span := trace.StartSpan(ctx, "scheduler/"+name)
+	defer func() {
+		if err != nil {
+			span.SetStatus(trace.Status{Code: trace.StatusCodeUnknown, Message: err.Error()})
+		}
+		span.End()
+	}()
+
+	timeout := milliseconds(job.config.Timeout)
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	if job.config.Lock && s.locker != nil {
+		ttl := timeout
+		if ttl <= 0 {
+			ttl = defaultLockTTL
+		}
+
+		release, err := s.locker.Lock(ctx, "scheduler:"+name, ttl)
+		if err != nil {
+			return err
+		}
+		defer release()
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			err = errors.Errorf("job %q panicked: %v", name, p)
+		}
+	}()
+
+	return job.job.Run(ctx)
+}
+
+func milliseconds(ms float64) time.Duration {
+	return time.Duration(ms * float64(time.Millisecond))
+}
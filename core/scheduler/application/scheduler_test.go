@@ -0,0 +1,190 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"flamingo.me/flamingo/v3/core/scheduler/domain"
+	"flamingo.me/flamingo/v3/core/scheduler/infrastructure"
+	"flamingo.me/flamingo/v3/framework/config"
+	"flamingo.me/flamingo/v3/framework/flamingo"
+	"github.com/stretchr/testify/assert"
+)
+
+type testJob struct {
+	name string
+	runs int32
+	run  func(ctx context.Context) error
+}
+
+func (j *testJob) Name() string {
+	return j.name
+}
+
+func (j *testJob) Run(ctx context.Context) error {
+	atomic.AddInt32(&j.runs, 1)
+	if j.run != nil {
+		return j.run(ctx)
+	}
+	return nil
+}
+
+func (j *testJob) count() int {
+	return int(atomic.LoadInt32(&j.runs))
+}
+
+func newTestScheduler(jobs []domain.Job, jobConfigs config.Map) *Scheduler {
+	return NewScheduler(func() []domain.Job { return jobs }, new(infrastructure.MemoryLocker), flamingo.NullLogger{}, time.Second, jobConfigs)
+}
+
+func TestScheduler_Run(t *testing.T) {
+	blocking := &testJob{name: "blocking", run: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}}
+	failing := &testJob{name: "failing", run: func(context.Context) error {
+		return errors.New("failed")
+	}}
+	panicking := &testJob{name: "panicking", run: func(context.Context) error {
+		panic("oops")
+	}}
+
+	scheduler := newTestScheduler(
+		[]domain.Job{blocking, failing, panicking},
+		config.Map{"blocking": config.Map{"timeout": float64(10)}},
+	)
+
+	assert.Equal(t, context.DeadlineExceeded, scheduler.Run(context.Background(), "blocking"))
+	assert.EqualError(t, scheduler.Run(context.Background(), "failing"), "failed")
+	assert.Contains(t, scheduler.Run(context.Background(), "panicking").Error(), "oops")
+	assert.Error(t, scheduler.Run(context.Background(), "unknown"))
+}
+
+func TestScheduler_Lock(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	job := &testJob{name: "locked", run: func(context.Context) error {
+		close(started)
+		<-release
+		return nil
+	}}
+
+	scheduler := newTestScheduler([]domain.Job{job}, config.Map{"locked": config.Map{"lock": true}})
+
+	done := make(chan error)
+	go func() { done <- scheduler.Run(context.Background(), "locked") }()
+	<-started
+
+	assert.Equal(t, domain.ErrLocked, scheduler.Run(context.Background(), "locked"))
+
+	close(release)
+	assert.NoError(t, <-done)
+	assert.Equal(t, 1, job.count())
+}
+
+func TestScheduler_StartStop(t *testing.T) {
+	interval := &testJob{name: "interval"}
+	manual := &testJob{name: "manual"}
+
+	scheduler := newTestScheduler(
+		[]domain.Job{interval, manual},
+		config.Map{"interval": config.Map{"interval": float64(5), "jitter": float64(1)}},
+	)
+
+	scheduler.Notify(context.Background(), new(flamingo.ServerStartEvent))
+
+	deadline := time.Now().Add(time.Second)
+	for interval.count() < 3 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	scheduler.Notify(context.Background(), new(flamingo.ShutdownEvent))
+	runs := interval.count()
+
+	assert.True(t, runs >= 3, "interval job runs")
+	assert.Equal(t, 0, manual.count(), "jobs without schedule are not run")
+
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, runs, interval.count(), "no runs after stop")
+}
+
+func TestScheduler_FractionalJitter(t *testing.T) {
+	interval := &testJob{name: "interval"}
+
+	scheduler := newTestScheduler([]domain.Job{interval}, config.Map{"interval": config.Map{"interval": float64(5), "jitter": 0.5}})
+
+	scheduler.Notify(context.Background(), new(flamingo.ServerStartEvent))
+
+	deadline := time.Now().Add(time.Second)
+	for interval.count() < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	scheduler.Notify(context.Background(), new(flamingo.ShutdownEvent))
+	assert.True(t, interval.count() >= 2, "jobs with a jitter below one millisecond run")
+}
+
+func TestScheduler_StopCancelsRunningJobs(t *testing.T) {
+	started := make(chan struct{}, 1)
+	job := &testJob{name: "blocking", run: func(ctx context.Context) error {
+		started <- struct{}{}
+		<-ctx.Done()
+		return ctx.Err()
+	}}
+
+	scheduler := newTestScheduler([]domain.Job{job}, config.Map{"blocking": config.Map{"interval": float64(1)}})
+	assert.NoError(t, scheduler.Start())
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	scheduler.Stop(ctx)
+
+	assert.Equal(t, 1, job.count())
+}
+
+func TestScheduler_Jobs(t *testing.T) {
+	scheduler := newTestScheduler(
+		[]domain.Job{&testJob{name: "b"}, &testJob{name: "a"}, &testJob{name: "c"}},
+		config.Map{
+			"a": config.Map{"cron": "@hourly", "timeout": float64(1000), "lock": true},
+			"b": config.Map{"interval": float64(60000)},
+		},
+	)
+
+	jobs, err := scheduler.Jobs()
+	assert.NoError(t, err)
+	assert.Len(t, jobs, 3)
+
+	assert.Equal(t, "a", jobs[0].Name)
+	assert.Equal(t, "@hourly", jobs[0].Schedule)
+	assert.Equal(t, time.Second, jobs[0].Timeout)
+	assert.True(t, jobs[0].Lock)
+	assert.False(t, jobs[0].Next.IsZero())
+
+	assert.Equal(t, "b", jobs[1].Name)
+	assert.Equal(t, "every 1m0s", jobs[1].Schedule)
+
+	assert.Equal(t, "c", jobs[2].Name)
+	assert.Equal(t, "-", jobs[2].Schedule)
+	assert.True(t, jobs[2].Next.IsZero())
+}
+
+func TestScheduler_InvalidConfig(t *testing.T) {
+	job := &testJob{name: "job"}
+
+	for _, cfg := range []config.Map{
+		{"job": config.Map{"cron": "not a cron"}},
+		{"job": config.Map{"cron": "@daily", "interval": float64(1000)}},
+	} {
+		scheduler := newTestScheduler([]domain.Job{job}, cfg)
+		assert.Error(t, scheduler.Start())
+	}
+
+	scheduler := newTestScheduler([]domain.Job{job, &testJob{name: "job"}}, nil)
+	assert.Error(t, scheduler.Start(), "duplicate job names")
+}
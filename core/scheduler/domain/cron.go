@@ -0,0 +1,211 @@
+package domain
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type (
+	// cronSchedule is a parsed cron expression, every field is a bitset of the allowed values
+	cronSchedule struct {
+		minute, hour, dom, month, dow uint64
+		// day of month and day of week are combined with OR, unless one of them is unrestricted
+		domStar, dowStar bool
+	}
+
+	cronField struct {
+		min, max int
+		names    map[string]int
+	}
+)
+
+var (
+	cronDescriptors = map[string]string{
+		"@yearly":   "0 0 1 1 *",
+		"@annually": "0 0 1 1 *",
+		"@monthly":  "0 0 1 * *",
+		"@weekly":   "0 0 * * 0",
+		"@daily":    "0 0 * * *",
+		"@midnight": "0 0 * * *",
+		"@hourly":   "0 * * * *",
+	}
+
+	minuteField = cronField{min: 0, max: 59}
+	hourField   = cronField{min: 0, max: 23}
+	domField    = cronField{min: 1, max: 31}
+	monthField  = cronField{min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// day of week allows 7 for sunday as well
+	dowField = cronField{min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+// ParseCron parses a cron expression with the five fields minute, hour, day of month, month and day of week.
+// Fields support `*`, lists, ranges, steps and names of months and weekdays, e.g. `*/15 8-18 * * mon-fri`.
+// The descriptors `@yearly`, `@monthly`, `@weekly`, `@daily`, `@hourly` and `@every <duration>` are supported as well.
+func ParseCron(expr string) (Schedule, error) {
+	expr = strings.TrimSpace(expr)
+
+	if strings.HasPrefix(expr, "@every ") {
+		interval, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(expr, "@every ")))
+		if err != nil {
+			return nil, errors.Wrapf(err, "cron expression %q", expr)
+		}
+		if interval <= 0 {
+			return nil, errors.Errorf("cron expression %q: interval must be positive", expr)
+		}
+		return &IntervalSchedule{Interval: interval}, nil
+	}
+
+	if descriptor, ok := cronDescriptors[expr]; ok {
+		expr = descriptor
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, errors.Errorf("cron expression %q: expected 5 fields, got %d", expr, len(fields))
+	}
+
+	schedule := new(cronSchedule)
+	var err error
+	if schedule.minute, err = minuteField.parse(fields[0]); err != nil {
+		return nil, errors.Wrapf(err, "cron expression %q: minute", expr)
+	}
+	if schedule.hour, err = hourField.parse(fields[1]); err != nil {
+		return nil, errors.Wrapf(err, "cron expression %q: hour", expr)
+	}
+	if schedule.dom, err = domField.parse(fields[2]); err != nil {
+		return nil, errors.Wrapf(err, "cron expression %q: day of month", expr)
+	}
+	if schedule.month, err = monthField.parse(fields[3]); err != nil {
+		return nil, errors.Wrapf(err, "cron expression %q: month", expr)
+	}
+	if schedule.dow, err = dowField.parse(fields[4]); err != nil {
+		return nil, errors.Wrapf(err, "cron expression %q: day of week", expr)
+	}
+
+	if schedule.dow&(1<<7) != 0 {
+		schedule.dow |= 1
+	}
+	schedule.domStar = fields[2] == "*"
+	schedule.dowStar = fields[4] == "*"
+
+	return schedule, nil
+}
+
+// parse a field into a bitset
+func (f cronField) parse(field string) (uint64, error) {
+	var bits uint64
+
+	for _, part := range strings.Split(field, ",") {
+		rangePart, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			var err error
+			rangePart = part[:i]
+			if step, err = strconv.Atoi(part[i+1:]); err != nil || step <= 0 {
+				return 0, errors.Errorf("invalid step in %q", part)
+			}
+		}
+
+		var from, to int
+		switch {
+		case rangePart == "*":
+			from, to = f.min, f.max
+		case strings.Contains(rangePart, "-"):
+			bounds := strings.SplitN(rangePart, "-", 2)
+			var err error
+			if from, err = f.value(bounds[0]); err != nil {
+				return 0, err
+			}
+			if to, err = f.value(bounds[1]); err != nil {
+				return 0, err
+			}
+			if from > to {
+				return 0, errors.Errorf("invalid range %q", rangePart)
+			}
+		default:
+			var err error
+			if from, err = f.value(rangePart); err != nil {
+				return 0, err
+			}
+			to = from
+			// a single value with a step, e.g. `5/10`, means every step starting at the value
+			if step > 1 {
+				to = f.max
+			}
+		}
+
+		for v := from; v <= to; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+
+	return bits, nil
+}
+
+func (f cronField) value(s string) (int, error) {
+	if v, ok := f.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, errors.Errorf("invalid value %q", s)
+	}
+	if v < f.min || v > f.max {
+		return 0, errors.Errorf("value %d out of range %d-%d", v, f.min, f.max)
+	}
+	return v, nil
+}
+
+// Next returns the first matching minute after from, in the location of from.
+// It returns the zero time if there is no match within the next five years, e.g. for `0 0 30 2 *`.
+func (s *cronSchedule) Next(from time.Time) time.Time {
+	loc := from.Location()
+	t := from.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		year, month, day := t.Date()
+
+		if s.month&(1<<uint(month)) == 0 {
+			t = time.Date(year, month+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+
+		if !s.matchesDay(t) {
+			t = time.Date(year, month, day+1, 0, 0, 0, 0, loc)
+			continue
+		}
+
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(year, month, day, t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = time.Date(year, month, day, t.Hour(), t.Minute()+1, 0, 0, loc)
+			continue
+		}
+
+		return t
+	}
+
+	return time.Time{}
+}
+
+func (s *cronSchedule) matchesDay(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
@@ -0,0 +1,102 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCron(t *testing.T) {
+	from := time.Date(2019, 3, 14, 10, 17, 42, 0, time.UTC) // a thursday
+
+	tests := []struct {
+		expr string
+		next []time.Time
+	}{
+		{
+			expr: "* * * * *",
+			next: []time.Time{time.Date(2019, 3, 14, 10, 18, 0, 0, time.UTC), time.Date(2019, 3, 14, 10, 19, 0, 0, time.UTC)},
+		},
+		{
+			expr: "*/15 * * * *",
+			next: []time.Time{time.Date(2019, 3, 14, 10, 30, 0, 0, time.UTC), time.Date(2019, 3, 14, 10, 45, 0, 0, time.UTC), time.Date(2019, 3, 14, 11, 0, 0, 0, time.UTC)},
+		},
+		{
+			expr: "5,10 8-9 * * *",
+			next: []time.Time{time.Date(2019, 3, 15, 8, 5, 0, 0, time.UTC), time.Date(2019, 3, 15, 8, 10, 0, 0, time.UTC), time.Date(2019, 3, 15, 9, 5, 0, 0, time.UTC)},
+		},
+		{
+			expr: "0 12 * * sat,sun",
+			next: []time.Time{time.Date(2019, 3, 16, 12, 0, 0, 0, time.UTC), time.Date(2019, 3, 17, 12, 0, 0, 0, time.UTC), time.Date(2019, 3, 23, 12, 0, 0, 0, time.UTC)},
+		},
+		{
+			expr: "0 0 * * 7",
+			next: []time.Time{time.Date(2019, 3, 17, 0, 0, 0, 0, time.UTC)},
+		},
+		{
+			expr: "0 0 1 jan-feb/1 *",
+			next: []time.Time{time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC)},
+		},
+		{
+			// day of month and day of week are combined with OR
+			expr: "0 0 20 * mon",
+			next: []time.Time{time.Date(2019, 3, 18, 0, 0, 0, 0, time.UTC), time.Date(2019, 3, 20, 0, 0, 0, 0, time.UTC), time.Date(2019, 3, 25, 0, 0, 0, 0, time.UTC)},
+		},
+		{
+			expr: "0 0 29 2 *",
+			next: []time.Time{time.Date(2020, 2, 29, 0, 0, 0, 0, time.UTC), time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		},
+		{
+			expr: "@daily",
+			next: []time.Time{time.Date(2019, 3, 15, 0, 0, 0, 0, time.UTC)},
+		},
+		{
+			expr: "@every 90s",
+			next: []time.Time{time.Date(2019, 3, 14, 10, 19, 12, 0, time.UTC), time.Date(2019, 3, 14, 10, 20, 42, 0, time.UTC)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			schedule, err := ParseCron(tt.expr)
+			assert.NoError(t, err)
+			if err != nil {
+				return
+			}
+
+			current := from
+			for _, expected := range tt.next {
+				current = schedule.Next(current)
+				assert.Equal(t, expected, current)
+			}
+		})
+	}
+}
+
+func TestParseCron_Invalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"10-5 * * * *",
+		"a * * * *",
+		"@every",
+		"@every -1m",
+		"@often",
+	} {
+		_, err := ParseCron(expr)
+		assert.Error(t, err, expr)
+	}
+}
+
+func TestCronSchedule_NoMatch(t *testing.T) {
+	schedule, err := ParseCron("0 0 30 2 *")
+	assert.NoError(t, err)
+	assert.True(t, schedule.Next(time.Now()).IsZero())
+}
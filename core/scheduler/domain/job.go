@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type (
+	// Job is a named background task, which is run by the scheduler
+	Job interface {
+		// Name of the job, used for its configuration in `scheduler.jobs`
+		Name() string
+		Run(ctx context.Context) error
+	}
+
+	// Schedule computes the next run of a job
+	Schedule interface {
+		// Next returns the first run time after from
+		Next(from time.Time) time.Time
+	}
+
+	// Locker ensures that a job is only run by a single instance at the same time
+	Locker interface {
+		// Lock tries to acquire the lock for the key, it returns ErrLocked if the lock is held by someone else.
+		// The lock expires after the ttl, if it is not released earlier.
+		Lock(ctx context.Context, key string, ttl time.Duration) (release func(), err error)
+	}
+
+	// IntervalSchedule runs a job in a fixed interval
+	IntervalSchedule struct {
+		Interval time.Duration
+	}
+)
+
+// ErrLocked is returned by a Locker if the lock is held by someone else
+var ErrLocked = errors.New("lock is held by someone else")
+
+// Next run after the interval
+func (s *IntervalSchedule) Next(from time.Time) time.Time {
+	return from.Add(s.Interval)
+}
@@ -0,0 +1,53 @@
+package infrastructure
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"flamingo.me/flamingo/v3/core/scheduler/domain"
+)
+
+type (
+	// MemoryLocker locks jobs within the current process, e.g. to prevent overlapping runs of a job
+	MemoryLocker struct {
+		mutex sync.Mutex
+		locks map[string]memoryLock
+		seq   uint64
+	}
+
+	memoryLock struct {
+		id      uint64
+		expires time.Time
+	}
+)
+
+var _ domain.Locker = new(MemoryLocker)
+
+// Lock the key until it is released or the ttl expired
+func (l *MemoryLocker) Lock(_ context.Context, key string, ttl time.Duration) (func(), error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.locks == nil {
+		l.locks = make(map[string]memoryLock)
+	}
+
+	if lock, ok := l.locks[key]; ok && time.Now().Before(lock.expires) {
+		return nil, domain.ErrLocked
+	}
+
+	l.seq++
+	id := l.seq
+	l.locks[key] = memoryLock{id: id, expires: time.Now().Add(ttl)}
+
+	return func() {
+		l.mutex.Lock()
+		defer l.mutex.Unlock()
+
+		// the lock might have expired and been acquired by someone else meanwhile
+		if lock, ok := l.locks[key]; ok && lock.id == id {
+			delete(l.locks, key)
+		}
+	}, nil
+}
@@ -0,0 +1,79 @@
+package infrastructure
+
+import (
+	"context"
+	"time"
+
+	"flamingo.me/flamingo/v3/core/scheduler/domain"
+	"flamingo.me/flamingo/v3/framework/flamingo"
+	"github.com/gomodule/redigo/redis"
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+type (
+	// RedisLocker locks jobs across all instances sharing the same redis
+	RedisLocker struct {
+		pool   *redis.Pool
+		prefix string
+		logger flamingo.Logger
+	}
+)
+
+var (
+	_ domain.Locker = new(RedisLocker)
+
+	// releaseScript only deletes the lock if it is still held with the same token
+	releaseScript = redis.NewScript(1, `if redis.call("get", KEYS[1]) == ARGV[1] then return redis.call("del", KEYS[1]) else return 0 end`)
+)
+
+// Inject dependencies
+func (l *RedisLocker) Inject(
+	logger flamingo.Logger,
+	cfg *struct {
+		Host        string  `inject:"config:scheduler.lock.redis.host"`
+		Password    string  `inject:"config:scheduler.lock.redis.password"`
+		Prefix      string  `inject:"config:scheduler.lock.redis.prefix"`
+		IdleTimeout float64 `inject:"config:scheduler.lock.redis.idleTimeout"`
+	},
+) *RedisLocker {
+	l.logger = logger.WithField(flamingo.LogKeyCategory, "scheduler")
+	l.prefix = cfg.Prefix
+	l.pool = &redis.Pool{
+		MaxIdle:     2,
+		IdleTimeout: time.Duration(cfg.IdleTimeout) * time.Millisecond,
+		Dial: func() (redis.Conn, error) {
+			return redis.Dial("tcp", cfg.Host, redis.DialPassword(cfg.Password))
+		},
+	}
+	return l
+}
+
+// Lock the key in redis until it is released or the ttl expired
+func (l *RedisLocker) Lock(ctx context.Context, key string, ttl time.Duration) (func(), error) {
+	conn, err := l.pool.GetContext(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "redis locker")
+	}
+	defer conn.Close()
+
+	key = l.prefix + key
+	token := uuid.NewV4().String()
+
+	_, err = redis.String(conn.Do("SET", key, token, "NX", "PX", int64(ttl/time.Millisecond)))
+	if err == redis.ErrNil {
+		return nil, domain.ErrLocked
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "redis locker")
+	}
+
+	return func() {
+		conn := l.pool.Get()
+		defer conn.Close()
+
+		if _, err := releaseScript.Do(conn, key, token); err != nil {
+			l.logger.Warn("unable to release lock ", key, ": ", err)
+		}
+	}, nil
+}
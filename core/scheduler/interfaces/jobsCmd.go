@@ -0,0 +1,61 @@
+package interfaces
+
+import (
+	"context"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"flamingo.me/flamingo/v3/core/scheduler/application"
+	"github.com/spf13/cobra"
+)
+
+// JobsCmd lists the scheduler jobs and runs them manually
+func JobsCmd(scheduler *application.Scheduler) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "jobs",
+		Short: "List and run scheduler jobs",
+	}
+
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:   "list",
+			Short: "List all jobs with their schedule and next run",
+			RunE: func(cmd *cobra.Command, args []string) error {
+				jobs, err := scheduler.Jobs()
+				if err != nil {
+					return err
+				}
+
+				w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 8, 2, ' ', 0)
+				fmt.Fprintln(w, "NAME\tSCHEDULE\tNEXT RUN\tTIMEOUT\tLOCK")
+				for _, job := range jobs {
+					next, timeout := "-", "-"
+					if !job.Next.IsZero() {
+						next = job.Next.Format(time.RFC3339)
+					}
+					if job.Timeout > 0 {
+						timeout = job.Timeout.String()
+					}
+					fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%t\n", job.Name, job.Schedule, next, timeout, job.Lock)
+				}
+				return w.Flush()
+			},
+		},
+		&cobra.Command{
+			Use:   "run <name>",
+			Short: "Run a job once, regardless of its schedule",
+			Args:  cobra.ExactArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				start := time.Now()
+				if err := scheduler.Run(context.Background(), args[0]); err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "job %s done in %s\n", args[0], time.Since(start))
+				return nil
+			},
+		},
+	)
+
+	return cmd
+}
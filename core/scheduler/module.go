@@ -0,0 +1,55 @@
+// Package scheduler runs background jobs, scheduled by cron expressions or intervals
+package scheduler
+
+import (
+	"flamingo.me/dingo"
+	"flamingo.me/flamingo/v3/core/scheduler/application"
+	"flamingo.me/flamingo/v3/core/scheduler/domain"
+	"flamingo.me/flamingo/v3/core/scheduler/infrastructure"
+	"flamingo.me/flamingo/v3/core/scheduler/interfaces"
+	"flamingo.me/flamingo/v3/framework/config"
+	"flamingo.me/flamingo/v3/framework/flamingo"
+	"github.com/spf13/cobra"
+)
+
+// Module for the scheduler
+type Module struct {
+	LockBackend string `inject:"config:scheduler.lock.backend"`
+}
+
+// Configure DI
+func (m *Module) Configure(injector *dingo.Injector) {
+	injector.Bind(application.Scheduler{}).In(dingo.Singleton)
+	flamingo.BindEventSubscriber(injector).To(application.Scheduler{})
+
+	switch m.LockBackend {
+	case "redis":
+		injector.Bind(infrastructure.RedisLocker{}).In(dingo.Singleton)
+		injector.Bind(new(domain.Locker)).To(infrastructure.RedisLocker{})
+	case "memory":
+		injector.Bind(infrastructure.MemoryLocker{}).In(dingo.Singleton)
+		injector.Bind(new(domain.Locker)).To(infrastructure.MemoryLocker{})
+	}
+
+	injector.BindMulti(new(cobra.Command)).ToProvider(interfaces.JobsCmd)
+}
+
+// DefaultConfig for the module
+func (m *Module) DefaultConfig() config.Map {
+	return config.Map{
+		"scheduler": config.Map{
+			"enabled":         true,
+			"shutdownTimeout": float64(10000),
+			"jobs":            config.Map{},
+			"lock": config.Map{
+				"backend": "memory",
+				"redis": config.Map{
+					"host":        "localhost:6379",
+					"password":    "",
+					"prefix":      "flamingo:",
+					"idleTimeout": float64(240000),
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,23 @@
+package scheduler_test
+
+import (
+	"testing"
+
+	"flamingo.me/dingo"
+	"flamingo.me/flamingo/v3/core/scheduler"
+	"flamingo.me/flamingo/v3/framework/config"
+)
+
+func TestModule_Configure(t *testing.T) {
+	for _, backend := range []string{"memory", "redis"} {
+		cfgModule := &config.Module{
+			Map: new(scheduler.Module).DefaultConfig(),
+		}
+
+		cfgModule.Map["scheduler"].(config.Map)["lock"].(config.Map)["backend"] = backend
+
+		if err := dingo.TryModule(cfgModule, new(scheduler.Module)); err != nil {
+			t.Error(err)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// completionCmd generates shell completion scripts for the root command
+func completionCmd(rootCmd *cobra.Command) *cobra.Command {
+	return &cobra.Command{
+		Use:   "completion bash|zsh",
+		Short: "Generate shell completion scripts",
+		Long: `Generate shell completion scripts, e.g. to load the completion in the current bash session:
+
+  source <(` + rootCmd.Use + ` completion bash)`,
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: []string{"bash", "zsh"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			switch args[0] {
+			case "bash":
+				return rootCmd.GenBashCompletion(cmd.OutOrStdout())
+			case "zsh":
+				return rootCmd.GenZshCompletion(cmd.OutOrStdout())
+			}
+			return WithExitCode(errors.Errorf("unsupported shell %q, use bash or zsh", args[0]), ExitUsage)
+		},
+	}
+}
@@ -0,0 +1,54 @@
+package cmd
+
+// Exit codes used by flamingo
+const (
+	ExitOK          = 0
+	ExitError       = 1
+	ExitUsage       = 2
+	ExitInterrupted = 130
+)
+
+type (
+	// exitError annotates an error with the exit code the process should exit with
+	exitError struct {
+		err  error
+		code int
+	}
+
+	causer interface {
+		Cause() error
+	}
+)
+
+// WithExitCode annotates an error with an exit code, commands return it to exit with a specific code
+func WithExitCode(err error, code int) error {
+	if err == nil {
+		return nil
+	}
+	return &exitError{err: err, code: code}
+}
+
+// ExitCode returns the exit code for an error returned by Run: 0 for nil, the code of WithExitCode, or 1 for every other error.
+// Errors wrapped by github.com/pkg/errors are unwrapped to find the exit code.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+
+	for err != nil {
+		if e, ok := err.(*exitError); ok {
+			return e.code
+		}
+		c, ok := err.(causer)
+		if !ok {
+			break
+		}
+		err = c.Cause()
+	}
+
+	return ExitError
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
@@ -2,9 +2,11 @@ package cmd
 
 import (
 	"context"
+	"io"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
@@ -12,50 +14,63 @@ import (
 	"flamingo.me/dingo"
 	"flamingo.me/flamingo/v3/framework/config"
 	"flamingo.me/flamingo/v3/framework/flamingo"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
 
-// Module for DI
-type Module struct{}
+type (
+	// Module for DI
+	Module struct{}
 
-var once = sync.Once{}
+	// RunOption configures Run
+	RunOption func(*runConfig)
 
-type eventRouterProvider func() flamingo.EventRouter
+	runConfig struct {
+		args   []string
+		output io.Writer
+	}
+
+	eventRouterProvider func() flamingo.EventRouter
+)
+
+const (
+	areaFlag = "area"
+
+	// hardShutdownTimeout is the time a graceful shutdown after a signal may take
+	hardShutdownTimeout = 30 * time.Second
+)
 
 // Configure DI
 func (m *Module) Configure(injector *dingo.Injector) {
-	injector.Bind(new(cobra.Command)).AnnotatedWith("flamingo").ToProvider(
-		func(
-			commands []*cobra.Command,
-			eventRouterProvider eventRouterProvider,
-			logger flamingo.Logger,
-			config *struct {
-				Name string `inject:"config:cmd.name"`
-			}) *cobra.Command {
-			signals := make(chan os.Signal, 1)
-			shutdownComplete := make(chan struct{}, 1)
-			signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
-
-			once.Do(func() {
-				go shutdown(eventRouterProvider(), signals, shutdownComplete, logger)
-			})
-
-			rootCmd := &cobra.Command{
-				Use:              config.Name,
-				Short:            "Flamingo " + config.Name,
-				TraverseChildren: true,
-				PersistentPostRun: func(cmd *cobra.Command, args []string) {
-					signals <- syscall.SIGTERM
-					<-shutdownComplete
-				},
+	injector.Bind(new(cobra.Command)).AnnotatedWith("flamingo").ToProvider(rootCmdProvider)
+}
+
+func rootCmdProvider(
+	commands []*cobra.Command,
+	config *struct {
+		Name string `inject:"config:cmd.name"`
+	},
+) *cobra.Command {
+	rootCmd := &cobra.Command{
+		Use:              config.Name,
+		Short:            "Flamingo " + config.Name,
+		TraverseChildren: true,
+		SilenceErrors:    true,
+		SilenceUsage:     true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				return WithExitCode(errors.Errorf("unknown command %q for %q", args[0], cmd.CommandPath()), ExitUsage)
 			}
-			rootCmd.FParseErrWhitelist.UnknownFlags = true
+			return cmd.Help()
+		},
+	}
+	rootCmd.FParseErrWhitelist.UnknownFlags = true
+	rootCmd.PersistentFlags().String(areaFlag, "", "run the command in a child area, e.g. `de` or `root/de`")
 
-			rootCmd.AddCommand(commands...)
+	rootCmd.AddCommand(commands...)
+	rootCmd.AddCommand(completionCmd(rootCmd))
 
-			return rootCmd
-		},
-	)
+	return rootCmd
 }
 
 // DefaultConfig specifies the command name
@@ -65,35 +80,150 @@ func (m *Module) DefaultConfig() config.Map {
 	}
 }
 
-func shutdown(eventRouter flamingo.EventRouter, signals <-chan os.Signal, complete chan<- struct{}, logger flamingo.Logger) {
-	<-signals
+// Args sets the arguments of the command, instead of the arguments of the process
+func Args(args ...string) RunOption {
+	return func(cfg *runConfig) {
+		cfg.args = args
+	}
+}
+
+// Output sets the writer for the output of the commands, instead of stdout
+func Output(w io.Writer) RunOption {
+	return func(cfg *runConfig) {
+		cfg.output = w
+	}
+}
+
+// Run the root command. The lifecycle hooks are started first, and the application is shut down after the command has finished,
+// or when the process receives SIGINT or SIGTERM. The error of the command is returned, see ExitCode for the exit code.
+// Run can be used in-process, e.g. in tests, with the Args and Output options.
+func Run(injector *dingo.Injector, options ...RunOption) error {
+	cfg := &runConfig{args: os.Args[1:]}
+	for _, option := range options {
+		option(cfg)
+	}
+
+	areaName, err := areaArg(cfg.args)
+	if err != nil {
+		return WithExitCode(err, ExitUsage)
+	}
+	if areaName != "" {
+		area, err := findArea(injector.GetInstance(config.Area{}).(*config.Area), areaName)
+		if err != nil {
+			return WithExitCode(err, ExitUsage)
+		}
+		injector = area.Injector
+	}
+
+	return execute(
+		injector.GetAnnotatedInstance(new(cobra.Command), "flamingo").(*cobra.Command),
+		injector.GetInstance(new(eventRouterProvider)).(eventRouterProvider)(),
+		injector.GetInstance(new(flamingo.Logger)).(flamingo.Logger),
+		injector.GetInstance(new(flamingo.Lifecycle)).(*flamingo.Lifecycle),
+		cfg,
+	)
+}
+
+// execute the root command between startup and shutdown of the application
+func execute(rootCmd *cobra.Command, eventRouter flamingo.EventRouter, logger flamingo.Logger, lifecycle *flamingo.Lifecycle, cfg *runConfig) error {
+	rootCmd.SetArgs(cfg.args)
+	if cfg.output != nil {
+		rootCmd.SetOutput(cfg.output)
+	}
+
+	if err := lifecycle.Start(context.Background()); err != nil {
+		return err
+	}
+	eventRouter.Dispatch(context.Background(), &flamingo.StartupEvent{})
+
+	var shutdownOnce sync.Once
+	shutdown := func() {
+		shutdownOnce.Do(func() {
+			eventRouter.Dispatch(context.Background(), &flamingo.ShutdownEvent{})
+		})
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	done := make(chan struct{})
+	go handleSignals(signals, done, shutdown, logger)
+
+	err := rootCmd.Execute()
+	close(done)
+
+	shutdown()
+
+	return err
+}
+
+// handleSignals shuts down gracefully on the first signal, and hard on a second signal or if the graceful shutdown takes too long
+func handleSignals(signals <-chan os.Signal, done <-chan struct{}, shutdown func(), logger flamingo.Logger) {
+	select {
+	case <-done:
+		return
+	case <-signals:
+	}
+
 	logger.Info("start graceful shutdown")
 
 	stopper := make(chan struct{})
-
 	go func() {
-		eventRouter.Dispatch(context.Background(), &flamingo.ShutdownEvent{})
+		shutdown()
 		close(stopper)
 	}()
 
 	select {
 	case <-signals:
 		logger.Info("second interrupt signal received, hard shutdown")
-		os.Exit(130)
-	case <-time.After(30 * time.Second):
+		os.Exit(ExitInterrupted)
+	case <-time.After(hardShutdownTimeout):
 		logger.Info("time limit reached, hard shutdown")
-		os.Exit(130)
+		os.Exit(ExitInterrupted)
 	case <-stopper:
 		logger.Info("graceful shutdown complete")
-		complete <- struct{}{}
-		os.Exit(0)
 	}
 }
 
-// Run the root command
-func Run(injector *dingo.Injector) error {
-	cmd := injector.GetAnnotatedInstance(new(cobra.Command), "flamingo").(*cobra.Command)
-	injector.GetInstance(new(eventRouterProvider)).(eventRouterProvider)().Dispatch(context.Background(), &flamingo.StartupEvent{})
+// areaArg returns the value of the --area flag, which has to be known before the root command is created
+func areaArg(args []string) (string, error) {
+	for i, arg := range args {
+		switch {
+		case arg == "--":
+			return "", nil
+		case arg == "--"+areaFlag:
+			if i+1 >= len(args) {
+				return "", errors.Errorf("flag needs an argument: --%s", areaFlag)
+			}
+			return args[i+1], nil
+		case strings.HasPrefix(arg, "--"+areaFlag+"="):
+			return strings.TrimPrefix(arg, "--"+areaFlag+"="), nil
+		}
+	}
+	return "", nil
+}
 
-	return cmd.Execute()
+// findArea by its name or path, e.g. `de` or `root/de`
+func findArea(root *config.Area, name string) (*config.Area, error) {
+	var found *config.Area
+	var walk func(area *config.Area, path string)
+	walk = func(area *config.Area, path string) {
+		if found != nil {
+			return
+		}
+		if area.Name == name || path == name {
+			found = area
+			return
+		}
+		for _, child := range area.Childs {
+			walk(child, path+"/"+child.Name)
+		}
+	}
+	walk(root, root.Name)
+
+	if found == nil || found.Injector == nil {
+		return nil, errors.Errorf("area %q not found", name)
+	}
+	return found, nil
 }
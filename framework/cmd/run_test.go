@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"flamingo.me/dingo"
+	"flamingo.me/flamingo/v3/framework/config"
+	"flamingo.me/flamingo/v3/framework/flamingo"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+type (
+	recordingEventRouter struct {
+		events []string
+	}
+
+	failingHook struct{}
+)
+
+func (r *recordingEventRouter) Dispatch(_ context.Context, event flamingo.Event) {
+	r.events = append(r.events, fmt.Sprintf("%T", event))
+}
+
+func (*failingHook) Start(context.Context) error {
+	return errors.New("hook failed")
+}
+
+func (*failingHook) Stop(context.Context) error {
+	return nil
+}
+
+func testRootCmd() *cobra.Command {
+	return rootCmdProvider(
+		[]*cobra.Command{
+			{
+				Use: "hello",
+				Run: func(cmd *cobra.Command, args []string) {
+					fmt.Fprint(cmd.OutOrStdout(), "hello")
+				},
+			},
+			{
+				Use: "fail",
+				RunE: func(*cobra.Command, []string) error {
+					return errors.Wrap(WithExitCode(errors.New("failed"), 3), "fail command")
+				},
+			},
+		},
+		&struct {
+			Name string `inject:"config:cmd.name"`
+		}{Name: "flamingo"},
+	)
+}
+
+func testRun(lifecycle *flamingo.Lifecycle, args ...string) (string, []string, error) {
+	router := new(recordingEventRouter)
+	out := new(bytes.Buffer)
+
+	cfg := new(runConfig)
+	Args(args...)(cfg)
+	Output(out)(cfg)
+
+	err := execute(testRootCmd(), router, flamingo.NullLogger{}, lifecycle, cfg)
+	return out.String(), router.events, err
+}
+
+func TestExecute(t *testing.T) {
+	t.Run("successful command", func(t *testing.T) {
+		out, events, err := testRun(new(flamingo.Lifecycle), "hello")
+		assert.NoError(t, err)
+		assert.Equal(t, ExitOK, ExitCode(err))
+		assert.Equal(t, "hello", out)
+		assert.Equal(t, []string{"*flamingo.StartupEvent", "*flamingo.ShutdownEvent"}, events)
+	})
+
+	t.Run("failing command", func(t *testing.T) {
+		_, events, err := testRun(new(flamingo.Lifecycle), "fail")
+		assert.EqualError(t, err, "fail command: failed")
+		assert.Equal(t, 3, ExitCode(err))
+		assert.Equal(t, []string{"*flamingo.StartupEvent", "*flamingo.ShutdownEvent"}, events, "shutdown after failed commands")
+	})
+
+	t.Run("unknown command", func(t *testing.T) {
+		_, _, err := testRun(new(flamingo.Lifecycle), "unknown")
+		assert.Error(t, err)
+		assert.Equal(t, ExitUsage, ExitCode(err))
+
+		out, _, err := testRun(new(flamingo.Lifecycle))
+		assert.NoError(t, err)
+		assert.Contains(t, out, "Available Commands")
+	})
+
+	t.Run("failing lifecycle hook", func(t *testing.T) {
+		lifecycle := flamingo.NewLifecycle(map[string]flamingo.LifecycleHook{"failing": new(failingHook)}, flamingo.NullLogger{})
+
+		out, events, err := testRun(lifecycle, "hello")
+		assert.Error(t, err)
+		assert.Empty(t, out)
+		assert.Empty(t, events)
+	})
+
+	t.Run("completion", func(t *testing.T) {
+		out, _, err := testRun(new(flamingo.Lifecycle), "completion", "bash")
+		assert.NoError(t, err)
+		assert.Contains(t, out, "bash completion for flamingo")
+
+		out, _, err = testRun(new(flamingo.Lifecycle), "completion", "zsh")
+		assert.NoError(t, err)
+		assert.Contains(t, out, "#compdef flamingo")
+
+		_, _, err = testRun(new(flamingo.Lifecycle), "completion", "tcsh")
+		assert.Equal(t, ExitUsage, ExitCode(err))
+	})
+
+	t.Run("area flag is accepted", func(t *testing.T) {
+		out, _, err := testRun(new(flamingo.Lifecycle), "hello", "--area", "child")
+		assert.NoError(t, err)
+		assert.Equal(t, "hello", out)
+	})
+}
+
+func TestAreaArg(t *testing.T) {
+	for _, tt := range []struct {
+		args []string
+		area string
+		err  bool
+	}{
+		{args: []string{"serve"}},
+		{args: []string{"--area", "de", "serve"}, area: "de"},
+		{args: []string{"serve", "--area=root/de"}, area: "root/de"},
+		{args: []string{"serve", "--", "--area", "de"}},
+		{args: []string{"serve", "--area"}, err: true},
+	} {
+		area, err := areaArg(tt.args)
+		assert.Equal(t, tt.area, area, "%v", tt.args)
+		assert.Equal(t, tt.err, err != nil, "%v", tt.args)
+	}
+}
+
+func TestFindArea(t *testing.T) {
+	de := &config.Area{Name: "de", Injector: new(dingo.Injector)}
+	root := config.NewArea("root", nil, config.NewArea("eu", nil, de))
+	root.Injector = new(dingo.Injector)
+
+	for _, name := range []string{"de", "root/eu/de"} {
+		area, err := findArea(root, name)
+		assert.NoError(t, err)
+		assert.Equal(t, de, area)
+	}
+
+	_, err := findArea(root, "eu")
+	assert.Error(t, err, "areas without injector are not initialized")
+
+	_, err = findArea(root, "fr")
+	assert.Error(t, err)
+}
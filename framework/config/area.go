@@ -25,6 +25,9 @@ type (
 		Routes        []Route
 		Configuration Map
 		LoadedConfig  Map
+
+		providers []Provider
+		provided  Map
 	}
 
 	// Map contains configuration
@@ -48,6 +51,14 @@ type (
 		Path       string
 		Controller string
 		Name       string
+		// Methods restricts the route to the given HTTP methods, all methods are allowed if empty
+		Methods []string
+		// Filters are the names of additional filters applied to this route only
+		Filters []string
+		// Args are fixed parameters passed to the controller, in addition to the ones of `controller(params)`
+		Args map[string]interface{}
+		// NoSession disables loading and saving the session for this route
+		NoSession bool
 	}
 )
 
@@ -251,51 +262,95 @@ func (area *Area) GetInitializedInjector() (*dingo.Injector, error) {
 	}
 	injector.Bind(Area{}).ToInstance(area)
 
+	if err := area.configure(area.Modules); err != nil {
+		return nil, err
+	}
+
+	// disabled modules must neither be initialized nor contribute their default config
+	enabled, disabled := area.filterModules()
+	if len(disabled) > 0 {
+		if err := area.configure(enabled); err != nil {
+			return nil, err
+		}
+	}
+
+	for k, v := range area.Configuration.Flat() {
+		if v == nil {
+			continue
+		}
+		injector.Bind(v).AnnotatedWith("config:" + k).ToInstance(v)
+	}
+
+	injector.InitModules(enabled...)
+
+	return injector, nil
+}
+
+// configure builds the configuration of the area from the defaults of the modules, the loaded config and the overrides of the modules
+func (area *Area) configure(modules []dingo.Module) error {
 	area.Configuration = make(Map)
-	for _, module := range area.Modules {
+	for _, module := range modules {
 		if cfgmodule, ok := module.(DefaultConfigModule); ok {
 			if err := area.Configuration.Add(cfgmodule.DefaultConfig()); err != nil {
-				return nil, err
+				return err
 			}
 		}
 	}
 
 	if err := area.Configuration.Add(Map{"area": area.Name}); err != nil {
-		return nil, err
+		return err
 	}
 	if err := area.Configuration.Add(area.LoadedConfig); err != nil {
-		return nil, err
+		return err
 	}
 
-	for _, module := range area.Modules {
+	for _, module := range modules {
 		if cfgmodule, ok := module.(OverrideConfigModule); ok {
 			if err := area.Configuration.Add(cfgmodule.OverrideConfig(area.Configuration)); err != nil {
-				return nil, err
+				return err
 			}
 		}
 	}
 
-	for k, v := range area.Configuration.Flat() {
-		if v == nil {
-			continue
-		}
-		injector.Bind(v).AnnotatedWith("config:" + k).ToInstance(v)
-	}
+	return nil
+}
 
+// DisabledModules returns the modules of the area which are disabled by `flamingo.modules.disabled`
+func (area *Area) DisabledModules() []dingo.Module {
+	_, disabled := area.filterModules()
+	return disabled
+}
+
+// filterModules splits the modules of the area into enabled and disabled ones, without modifying area.Modules
+func (area *Area) filterModules() (enabled, disabled []dingo.Module) {
+	names := make(map[string]bool)
 	if config, ok := area.Configuration.Get("flamingo.modules.disabled"); ok {
-		for _, disabled := range config.(Slice) {
-			for i, module := range area.Modules {
-				tm := reflect.TypeOf(module).Elem()
-				if tm.PkgPath()+"."+tm.Name() == disabled.(string) {
-					area.Modules = append(area.Modules[:i], area.Modules[i+1:]...)
-				}
+		list, _ := config.(Slice)
+		for _, name := range list {
+			if name, ok := name.(string); ok {
+				names[name] = true
 			}
 		}
 	}
 
-	injector.InitModules(area.Modules...)
+	for _, module := range area.Modules {
+		if names[ModuleName(module)] {
+			disabled = append(disabled, module)
+		} else {
+			enabled = append(enabled, module)
+		}
+	}
 
-	return injector, nil
+	return enabled, disabled
+}
+
+// ModuleName returns the name of a module as used by `flamingo.modules.disabled`, e.g. `flamingo.me/flamingo/v3/core/healthcheck.Module`
+func ModuleName(module dingo.Module) string {
+	tm := reflect.TypeOf(module)
+	if tm.Kind() == reflect.Ptr {
+		tm = tm.Elem()
+	}
+	return tm.PkgPath() + "." + tm.Name()
 }
 
 // Flat returns a map of name->*Area of contexts, were all values have been inherited (yet overriden) of the parent context tree.
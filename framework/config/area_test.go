@@ -6,6 +6,7 @@ import (
 	"reflect"
 	"testing"
 
+	"flamingo.me/dingo"
 	"github.com/ghodss/yaml"
 	"github.com/stretchr/testify/assert"
 )
@@ -328,3 +329,30 @@ func TestMap_Add(t *testing.T) {
 		})
 	}
 }
+
+type (
+	testModuleA struct{}
+	testModuleB struct{}
+	testModuleC struct{}
+)
+
+func (*testModuleA) Configure(*dingo.Injector) {}
+func (*testModuleB) Configure(*dingo.Injector) {}
+func (*testModuleC) Configure(*dingo.Injector) {}
+
+func TestArea_DisabledModules(t *testing.T) {
+	a, b, c := new(testModuleA), new(testModuleB), new(testModuleC)
+	area := NewArea("root", []dingo.Module{a, b, c})
+	area.Configuration = Map{"flamingo": Map{"modules": Map{"disabled": Slice{
+		"flamingo.me/flamingo/v3/framework/config.testModuleA",
+		"flamingo.me/flamingo/v3/framework/config.testModuleB",
+	}}}}
+
+	enabled, disabled := area.filterModules()
+	assert.Equal(t, []dingo.Module{c}, enabled)
+	assert.Equal(t, []dingo.Module{a, b}, disabled)
+	assert.Equal(t, []dingo.Module{a, b, c}, area.Modules, "modules of the area are not modified")
+	assert.Equal(t, disabled, area.DisabledModules())
+
+	assert.Equal(t, "flamingo.me/flamingo/v3/framework/config.testModuleC", ModuleName(c))
+}
@@ -0,0 +1,211 @@
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+
+	"flamingo.me/dingo"
+	"github.com/pkg/errors"
+)
+
+type (
+	// areaDefinition is the configuration of an area declared in `flamingo.areas`
+	areaDefinition struct {
+		Parent  string
+		BaseURL string
+		Modules struct {
+			Enable  []string
+			Disable []string
+		}
+		Routes []Route
+		Config Map
+	}
+)
+
+var (
+	moduleRegistry   = make(map[string]func() dingo.Module)
+	moduleRegistryMu sync.RWMutex
+)
+
+// RegisterModule registers a module constructor by name, so it can be enabled for areas declared in the configuration.
+// Registering the same constructor again is a no-op, another constructor with the same name panics.
+func RegisterModule(name string, constructor func() dingo.Module) {
+	moduleRegistryMu.Lock()
+	defer moduleRegistryMu.Unlock()
+
+	if registered, ok := moduleRegistry[name]; ok {
+		if reflect.ValueOf(registered).Pointer() == reflect.ValueOf(constructor).Pointer() {
+			return
+		}
+		panic(errors.Errorf("module %q is already registered", name))
+	}
+	moduleRegistry[name] = constructor
+}
+
+func registeredModule(name string) (func() dingo.Module, bool) {
+	moduleRegistryMu.RLock()
+	defer moduleRegistryMu.RUnlock()
+
+	constructor, ok := moduleRegistry[name]
+	return constructor, ok
+}
+
+// loadAreas creates the areas declared in `flamingo.areas`, or extends them if they already exist
+func loadAreas(root *Area, basedir string) error {
+	cfg, ok := root.LoadedConfig.Get("flamingo.areas")
+	if !ok || cfg == nil {
+		return nil
+	}
+
+	areas, ok := cfg.(Map)
+	if !ok {
+		return errors.Errorf("flamingo.areas must be a map of area names to area definitions, got %T", cfg)
+	}
+
+	definitions := make(map[string]*areaDefinition, len(areas))
+	pending := make([]string, 0, len(areas))
+	for name, def := range areas {
+		definition := new(areaDefinition)
+		if def, ok := def.(Map); ok {
+			if err := def.MapInto(definition); err != nil {
+				return errors.Wrapf(err, "area %q", name)
+			}
+		}
+		definitions[name] = definition
+		pending = append(pending, name)
+	}
+	sort.Strings(pending)
+
+	// areas are created in passes, so they can use other declared areas as parent regardless of the order
+	for len(pending) > 0 {
+		var next []string
+		for _, name := range pending {
+			definition := definitions[name]
+
+			parent := root
+			if definition.Parent != "" && definition.Parent != root.Name {
+				if parent = root.find(definition.Parent); parent == nil {
+					if _, declared := definitions[definition.Parent]; declared {
+						next = append(next, name)
+						continue
+					}
+					return errors.Errorf("area %q: unknown parent area %q", name, definition.Parent)
+				}
+			}
+
+			area, created, err := declareArea(root, parent, name, definition)
+			if err != nil {
+				return err
+			}
+
+			if created {
+				load(area, basedir, area.path())
+			}
+
+			if err := applyAreaDefinition(area, definition); err != nil {
+				return errors.Wrapf(err, "area %q", name)
+			}
+		}
+
+		if len(next) == len(pending) {
+			return errors.Errorf("areas %v: circular parent definition", next)
+		}
+		pending = next
+	}
+
+	return nil
+}
+
+// declareArea creates the area of a definition or returns the existing one, and adds the enabled modules
+func declareArea(root, parent *Area, name string, definition *areaDefinition) (*Area, bool, error) {
+	area := root.find(name)
+	created := area == nil
+	if created {
+		area = &Area{
+			Name:          name,
+			Parent:        parent,
+			Configuration: make(Map),
+		}
+		parent.Childs = append(parent.Childs, area)
+	} else if definition.Parent != "" && area.Parent != parent {
+		return nil, false, errors.Errorf("area %q: already defined with a different parent", name)
+	}
+
+	for _, moduleName := range definition.Modules.Enable {
+		constructor, ok := registeredModule(moduleName)
+		if !ok {
+			return nil, false, errors.Errorf("area %q: module %q is not registered", name, moduleName)
+		}
+		area.Modules = append(area.Modules, constructor())
+	}
+
+	return area, created, nil
+}
+
+// applyAreaDefinition applies the definition after the config files of the area have been loaded, for new and existing areas alike:
+// the config files override the declared config and baseurl, the declared routes and disabled modules are added to the ones of the files
+func applyAreaDefinition(area *Area, definition *areaDefinition) error {
+	declared := make(Map)
+	if err := declared.Add(definition.Config); err != nil {
+		return err
+	}
+	if definition.BaseURL != "" {
+		if err := declared.Add(Map{"prefixrouter.baseurl": definition.BaseURL}); err != nil {
+			return err
+		}
+	}
+	if err := declared.Add(area.LoadedConfig); err != nil {
+		return err
+	}
+	area.LoadedConfig = declared
+
+	area.Routes = append(area.Routes, definition.Routes...)
+
+	if len(definition.Modules.Disable) > 0 {
+		disabled, _ := area.LoadedConfig.Get("flamingo.modules.disabled")
+		list, _ := disabled.(Slice)
+		for _, moduleName := range definition.Modules.Disable {
+			list = append(list, moduleTypeName(moduleName))
+		}
+		if err := area.LoadedConfig.Add(Map{"flamingo.modules.disabled": list}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// moduleTypeName resolves a registered module name to the type name used by `flamingo.modules.disabled`
+func moduleTypeName(name string) string {
+	constructor, ok := registeredModule(name)
+	if !ok {
+		return name
+	}
+
+	return ModuleName(constructor())
+}
+
+// find an area by name in the tree
+func (area *Area) find(name string) *Area {
+	if area.Name == name {
+		return area
+	}
+
+	for _, child := range area.Childs {
+		if found := child.find(name); found != nil {
+			return found
+		}
+	}
+
+	return nil
+}
+
+// path of the area's config directory relative to the config basedir
+func (area *Area) path() string {
+	if area.Parent == nil {
+		return "/"
+	}
+	return filepath.Join(area.Parent.path(), area.Name)
+}
@@ -0,0 +1,103 @@
+package config
+
+import (
+	"testing"
+
+	"flamingo.me/dingo"
+	"github.com/stretchr/testify/assert"
+)
+
+type areaTestModule struct{}
+
+func (*areaTestModule) Configure(*dingo.Injector) {}
+
+func (*areaTestModule) DefaultConfig() Map {
+	return Map{"areatest.enabled": true}
+}
+
+func TestLoadAreas(t *testing.T) {
+	RegisterModule("areatest", func() dingo.Module { return new(areaTestModule) })
+
+	root := NewArea("root", nil, NewArea("fr", []dingo.Module{new(areaTestModule)}))
+	assert.NoError(t, Load(root, "testdata/areas"))
+
+	flat, err := root.GetFlatContexts()
+	assert.NoError(t, err)
+
+	areas := make(map[string]*Area, len(flat))
+	for _, area := range flat {
+		areas[area.Name] = area
+	}
+	if !assert.Contains(t, areas, "root/de") || !assert.Contains(t, areas, "root/de/de_b2b") || !assert.Contains(t, areas, "root/fr") {
+		return
+	}
+
+	t.Run("declared area", func(t *testing.T) {
+		de := areas["root/de"].Configuration
+		assert.Equal(t, "/de", de.Flat()["prefixrouter.baseurl"])
+		assert.Equal(t, true, de.Flat()["areatest.enabled"], "enabled module's default config must be present")
+		assert.Equal(t, true, de.Flat()["fromfile"], "area config directory must be loaded")
+	})
+
+	t.Run("declared area precedence", func(t *testing.T) {
+		de := areas["root/de"].Configuration
+		assert.Equal(t, "file", de.Flat()["precedence"], "area config files must override the declaration")
+		assert.Equal(t, true, de.Flat()["declaredonly"])
+		assert.Equal(t, []Route{{Path: "/file", Controller: "file"}, {Path: "/", Controller: "home"}}, areas["root/de"].Routes, "declared routes must be added after the routes files")
+	})
+
+	t.Run("declared child area", func(t *testing.T) {
+		b2b := areas["root/de/de_b2b"].Configuration
+		assert.Equal(t, "/de/b2b", b2b.Flat()["prefixrouter.baseurl"])
+		assert.Equal(t, "b2b", b2b.Flat()["customer.type"])
+		assert.Equal(t, "de", root.find("de_b2b").Parent.Name)
+	})
+
+	t.Run("existing area", func(t *testing.T) {
+		assert.NotContains(t, areas["root/fr"].Configuration.Flat(), "areatest.enabled", "disabled module must be removed")
+	})
+
+	t.Run("existing area precedence", func(t *testing.T) {
+		fr := areas["root/fr"].Configuration
+		assert.Equal(t, "file", fr.Flat()["precedence"], "area config files must override the declaration")
+		assert.Equal(t, true, fr.Flat()["declaredonly"])
+		assert.Equal(t, []Route{{Path: "/file", Controller: "file"}, {Path: "/", Controller: "home"}}, areas["root/fr"].Routes, "declared routes must be added after the routes files")
+	})
+}
+
+func TestLoadAreasErrors(t *testing.T) {
+	rootWith := func(cfg Map) *Area {
+		root := &Area{Name: "root", LoadedConfig: make(Map)}
+		assert.NoError(t, root.LoadedConfig.Add(cfg))
+		return root
+	}
+
+	t.Run("unknown module", func(t *testing.T) {
+		root := rootWith(Map{"flamingo.areas.de.modules.enable": Slice{"unknown"}})
+		assert.Error(t, loadAreas(root, "not-existing"))
+	})
+
+	t.Run("unknown parent", func(t *testing.T) {
+		root := rootWith(Map{"flamingo.areas.de.parent": "unknown"})
+		assert.Error(t, loadAreas(root, "not-existing"))
+	})
+
+	t.Run("circular parents", func(t *testing.T) {
+		root := rootWith(Map{
+			"flamingo.areas.a.parent": "b",
+			"flamingo.areas.b.parent": "a",
+		})
+		assert.Error(t, loadAreas(root, "not-existing"))
+	})
+}
+
+func newRegistryTestModule() dingo.Module { return new(areaTestModule) }
+
+func TestRegisterModule(t *testing.T) {
+	RegisterModule("registrytest", newRegistryTestModule)
+
+	assert.NotPanics(t, func() { RegisterModule("registrytest", newRegistryTestModule) }, "registering the same constructor again must be idempotent")
+	assert.Panics(t, func() {
+		RegisterModule("registrytest", func() dingo.Module { return new(areaTestModule) })
+	}, "another constructor must not replace a registered module")
+}
@@ -1,9 +1,18 @@
 package config
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
 
+	"flamingo.me/dingo"
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
 
@@ -16,12 +25,8 @@ func Cmd(area *Area) *cobra.Command {
 		Short: "Config dump",
 		Run: func(cmd *cobra.Command, args []string) {
 			if contextName != "" {
-				flatArea, _ := area.Flat()
-				for _, c := range flatArea {
-					if c.Name == contextName {
-						area = c
-						break
-					}
+				if c, err := findArea(area, contextName); err == nil {
+					area = c
 				}
 			}
 
@@ -47,9 +52,281 @@ func Cmd(area *Area) *cobra.Command {
 		"Name of the context (relative context path) - set this if you like to see only this context. Otherwise it will show all.",
 	)
 
+	cmd.AddCommand(diffCmd(area), exportCmd(area), defaultsCmd(area))
+
+	return cmd
+}
+
+func diffCmd(area *Area) *cobra.Command {
+	return &cobra.Command{
+		Use:   "diff <areaA> <areaB>",
+		Short: "Show the configuration differences between two areas",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			a, err := findArea(area, args[0])
+			if err != nil {
+				return err
+			}
+			b, err := findArea(area, args[1])
+			if err != nil {
+				return err
+			}
+
+			aConfig, err := a.resolvedConfig()
+			if err != nil {
+				return err
+			}
+			bConfig, err := b.resolvedConfig()
+			if err != nil {
+				return err
+			}
+
+			for _, line := range diffConfig(aConfig, bConfig) {
+				fmt.Fprintln(cmd.OutOrStdout(), line)
+			}
+			return nil
+		},
+	}
+}
+
+func exportCmd(area *Area) *cobra.Command {
+	var contextName, format string
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export the fully resolved configuration of an area",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			a := area
+			if contextName != "" {
+				var err error
+				if a, err = findArea(area, contextName); err != nil {
+					return err
+				}
+			}
+
+			cfg, err := a.resolvedConfig()
+			if err != nil {
+				return err
+			}
+
+			var out []byte
+			switch format {
+			case "json":
+				out, err = json.MarshalIndent(cfg, "", "  ")
+				out = append(out, '\n')
+			case "yaml", "yml":
+				out, err = yaml.Marshal(cfg)
+			case "env":
+				out = exportEnv(cfg)
+			default:
+				return errors.Errorf("unknown format %q, use yaml, json or env", format)
+			}
+			if err != nil {
+				return err
+			}
+
+			_, err = cmd.OutOrStdout().Write(out)
+			return err
+		},
+	}
+
+	cmd.Flags().StringVarP(&contextName, "context", "c", "", "Name of the area to export, defaults to the root area")
+	cmd.Flags().StringVarP(&format, "format", "f", "yaml", "Output format: yaml, json or env")
+
 	return cmd
 }
 
+func defaultsCmd(area *Area) *cobra.Command {
+	var contextName string
+
+	cmd := &cobra.Command{
+		Use:   "defaults",
+		Short: "List the default configuration keys of all modules",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			a := area
+			if contextName != "" {
+				var err error
+				if a, err = findArea(area, contextName); err != nil {
+					return err
+				}
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+			enabled, _ := a.filterModules()
+			for _, d := range moduleDefaults(enabled) {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", d.key, formatValue(d.value), d.module)
+			}
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().StringVarP(&contextName, "context", "c", "", "Name of the area, defaults to the root area")
+
+	return cmd
+}
+
+// findArea finds an area by its full name, e.g. `root/de`, or by its name
+func findArea(root *Area, name string) (*Area, error) {
+	flat, err := root.Flat()
+	if err != nil {
+		return nil, err
+	}
+
+	if a, ok := flat[name]; ok {
+		return a, nil
+	}
+
+	for _, a := range flat {
+		if a.Name == name {
+			return a, nil
+		}
+	}
+
+	return nil, errors.Errorf("area %q not found", name)
+}
+
+// resolvedConfig returns the configuration of the area including everything inherited from the parent areas
+func (area *Area) resolvedConfig() (Map, error) {
+	cfg := make(Map)
+	if area.Parent != nil {
+		parent, err := area.Parent.resolvedConfig()
+		if err != nil {
+			return nil, err
+		}
+		if err := cfg.Add(parent); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := cfg.Add(area.Configuration); err != nil {
+		return nil, errors.Wrapf(err, "area %q", area.Name)
+	}
+	return cfg, nil
+}
+
+// diffConfig returns the key-level differences of two configurations:
+// `- key` only in a, `+ key` only in b, `~ key` in both with different values
+func diffConfig(a, b Map) []string {
+	aFlat, bFlat := leafs(a), leafs(b)
+
+	keys := make(map[string]struct{}, len(aFlat)+len(bFlat))
+	for k := range aFlat {
+		keys[k] = struct{}{}
+	}
+	for k := range bFlat {
+		keys[k] = struct{}{}
+	}
+
+	var lines []string
+	for _, k := range sortedKeys(keys) {
+		av, inA := aFlat[k]
+		bv, inB := bFlat[k]
+		switch {
+		case !inB:
+			lines = append(lines, fmt.Sprintf("- %s: %s", k, formatValue(av)))
+		case !inA:
+			lines = append(lines, fmt.Sprintf("+ %s: %s", k, formatValue(bv)))
+		case !reflect.DeepEqual(av, bv):
+			lines = append(lines, fmt.Sprintf("~ %s: %s -> %s", k, formatValue(av), formatValue(bv)))
+		}
+	}
+
+	return lines
+}
+
+// exportEnv renders the configuration as environment variables, e.g. `FOO_BAR='baz'` for `foo.bar: baz`
+func exportEnv(m Map) []byte {
+	flat := leafs(m)
+	keys := make(map[string]struct{}, len(flat))
+	for k := range flat {
+		keys[k] = struct{}{}
+	}
+
+	var buf bytes.Buffer
+	for _, k := range sortedKeys(keys) {
+		name := strings.ToUpper(envReplacer.Replace(k))
+		value := strings.Replace(formatValue(flat[k]), "'", `'\''`, -1)
+		fmt.Fprintf(&buf, "%s='%s'\n", name, value)
+	}
+	return buf.Bytes()
+}
+
+var envReplacer = strings.NewReplacer(".", "_", "-", "_")
+
+type moduleDefault struct {
+	key    string
+	value  interface{}
+	module string
+}
+
+// moduleDefaults lists the default config keys of all modules, sorted by key
+func moduleDefaults(modules []dingo.Module) []moduleDefault {
+	var defaults []moduleDefault
+	for _, module := range modules {
+		cfgModule, ok := module.(DefaultConfigModule)
+		if !ok {
+			continue
+		}
+
+		cfg := make(Map)
+		if err := cfg.Add(cfgModule.DefaultConfig()); err != nil {
+			continue
+		}
+
+		for k, v := range leafs(cfg) {
+			defaults = append(defaults, moduleDefault{key: k, value: v, module: ModuleName(module)})
+		}
+	}
+
+	sort.Slice(defaults, func(i, j int) bool {
+		if defaults[i].key == defaults[j].key {
+			return defaults[i].module < defaults[j].module
+		}
+		return defaults[i].key < defaults[j].key
+	})
+
+	return defaults
+}
+
+// leafs returns the flat configuration without the intermediate maps
+func leafs(m Map) Map {
+	res := make(Map)
+	for k, v := range m.Flat() {
+		if _, ok := v.(Map); !ok {
+			res[k] = v
+		}
+	}
+	return res
+}
+
+func sortedKeys(m map[string]struct{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func formatValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
 func dumpConfigArea(a *Area) {
 	fmt.Println()
 	fmt.Println("**************************")
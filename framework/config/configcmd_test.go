@@ -0,0 +1,80 @@
+package config
+
+import (
+	"bytes"
+	"testing"
+
+	"flamingo.me/dingo"
+	"github.com/stretchr/testify/assert"
+)
+
+type defaultsTestModule struct{}
+
+func (*defaultsTestModule) Configure(*dingo.Injector) {}
+
+func (*defaultsTestModule) DefaultConfig() Map {
+	return Map{
+		"test.a": "a",
+		"test": Map{
+			"b": float64(1),
+		},
+	}
+}
+
+func runCmd(t *testing.T, area *Area, args ...string) string {
+	t.Helper()
+
+	cmd := Cmd(area)
+	out := new(bytes.Buffer)
+	cmd.SetOutput(out)
+	cmd.SetArgs(args)
+	assert.NoError(t, cmd.Execute())
+
+	return out.String()
+}
+
+func TestDiffConfig(t *testing.T) {
+	a := Map{"same": "x", "changed": float64(1), "removed": true, "nested": Map{"changed": "a"}}
+	b := Map{"same": "x", "changed": float64(2), "added": Slice{"a", "b"}, "nested": Map{"changed": "b"}}
+
+	assert.Equal(t, []string{
+		`+ added: ["a","b"]`,
+		"~ changed: 1 -> 2",
+		"~ nested.changed: a -> b",
+		"- removed: true",
+	}, diffConfig(a, b))
+}
+
+func TestExportEnv(t *testing.T) {
+	assert.Equal(t,
+		"FOO_BAR='baz'\nFOO_DASHED_KEY='it'\\''s'\nLIST='[1,2]'\nNUMBER='1.5'\n",
+		string(exportEnv(Map{
+			"foo":    Map{"bar": "baz", "dashed-key": "it's"},
+			"list":   Slice{float64(1), float64(2)},
+			"number": 1.5,
+		})),
+	)
+}
+
+func TestCmd(t *testing.T) {
+	root := NewArea("root", []dingo.Module{new(defaultsTestModule)}, NewArea("de", nil))
+	root.LoadedConfig = Map{"foo": "root"}
+	root.Childs[0].LoadedConfig = Map{"foo": "de"}
+	_, err := root.GetFlatContexts()
+	assert.NoError(t, err)
+
+	t.Run("diff", func(t *testing.T) {
+		assert.Equal(t, "~ area: root -> de\n~ foo: root -> de\n", runCmd(t, root, "diff", "root", "root/de"))
+	})
+
+	t.Run("export", func(t *testing.T) {
+		assert.Equal(t, "AREA='root'\nFOO='root'\nTEST_A='a'\nTEST_B='1'\n", runCmd(t, root, "export", "--format", "env"))
+		assert.Equal(t, "area: de\nfoo: de\ntest:\n  a: a\n  b: 1\n", runCmd(t, root, "export", "--context", "root/de"), "inherited config must be exported")
+	})
+
+	t.Run("defaults", func(t *testing.T) {
+		out := runCmd(t, root, "defaults")
+		assert.Contains(t, out, "test.a  a  flamingo.me/flamingo/v3/framework/config.defaultsTestModule")
+		assert.Contains(t, out, "test.b  1  flamingo.me/flamingo/v3/framework/config.defaultsTestModule")
+	})
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// DirProvider provides configuration from a local directory, with one file per area, e.g. `root.json` and `root/de.yml`.
+// It can be used as a stand-in for remote providers during development and in tests.
+type DirProvider struct {
+	Dir string
+}
+
+var _ Provider = new(DirProvider)
+
+// Load the configuration of an area from its files, missing files are skipped
+func (p *DirProvider) Load(_ context.Context, area string) (Map, error) {
+	cfg := make(Map)
+
+	for _, ext := range extensions {
+		data, err := ioutil.ReadFile(filepath.Join(p.Dir, filepath.FromSlash(area)+ext))
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		fileCfg := make(Map)
+		if err := unmarshal(data, ext, &fileCfg); err != nil {
+			return nil, err
+		}
+		if err := cfg.Add(fileCfg); err != nil {
+			return nil, err
+		}
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// HTTPProvider provides configuration from a HTTP endpoint, which serves a JSON object per area at `URL/<area>`, e.g. `/config/root/de`.
+// A 404 response is treated as an empty configuration.
+type HTTPProvider struct {
+	URL    string
+	Client *http.Client
+}
+
+var _ Provider = new(HTTPProvider)
+
+// Load the configuration of an area from the endpoint
+func (p *HTTPProvider) Load(ctx context.Context, area string) (Map, error) {
+	client := p.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	req, err := http.NewRequest(http.MethodGet, strings.TrimRight(p.URL, "/")+"/"+area, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return make(Map), nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("unexpected status %q from %s", resp.Status, req.URL)
+	}
+
+	cfg := make(Map)
+	if err := json.NewDecoder(resp.Body).Decode(&cfg); err != nil {
+		return nil, errors.Wrapf(err, "invalid config from %s", req.URL)
+	}
+	return cfg, nil
+}
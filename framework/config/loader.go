@@ -1,14 +1,18 @@
 package config
 
 import (
+	"context"
+	"encoding/json"
 	"io/ioutil"
 	"log"
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 	"sync"
 
+	"github.com/BurntSushi/toml"
 	"github.com/ghodss/yaml"
 	"github.com/spf13/pflag"
 )
@@ -19,10 +23,13 @@ var (
 	// AdditionalConfig to be loaded
 	AdditionalConfig []string
 	once             = sync.Once{}
+
+	// extensions supported by the loader, in the order siblings are loaded
+	extensions = []string{".yml", ".toml", ".json"}
 )
 
-// Load configuration in basedir
-func Load(root *Area, basedir string) error {
+// Load configuration in basedir, and from the given providers
+func Load(root *Area, basedir string, providers ...Provider) error {
 	once.Do(func() {
 		pflag.StringArrayVar(&AdditionalConfig, "flamingo-config", []string{}, "add multiple flamingo config additions")
 		pflag.BoolVar(&DebugLog, "flamingo-config-log", false, "enable flamingo config loader logging")
@@ -41,6 +48,15 @@ func Load(root *Area, basedir string) error {
 		}
 	}
 
+	if err := loadAreas(root, basedir); err != nil {
+		return err
+	}
+
+	root.providers = providers
+	if err := applyProviders(context.Background(), root); err != nil {
+		return err
+	}
+
 	for _, add := range AdditionalConfig {
 		if DebugLog {
 			log.Printf("Loading %q", add)
@@ -64,23 +80,83 @@ func LoadConfigFile(area *Area, file string) error {
 }
 
 func load(area *Area, basedir, curdir string) {
-	loadConfigFile(area, filepath.Join(basedir, curdir, "config.yml"))
-	loadRoutes(area, filepath.Join(basedir, curdir, "routes.yml"))
+	loadConfigFiles(area, filepath.Join(basedir, curdir, "config"))
+	loadRoutesFiles(area, filepath.Join(basedir, curdir, "routes"))
+	loadConfigDir(area, filepath.Join(basedir, curdir, "config.d"))
 	for _, context := range strings.Split(os.Getenv("CONTEXT"), ":") {
 		if context == "" {
 			continue
 		}
-		loadConfigFile(area, filepath.Join(basedir, curdir, "config_"+context+".yml"))
-		loadRoutes(area, filepath.Join(basedir, curdir, "routes_"+context+".yml"))
+		loadConfigFiles(area, filepath.Join(basedir, curdir, "config_"+context))
+		loadRoutesFiles(area, filepath.Join(basedir, curdir, "routes_"+context))
 	}
-	loadConfigFile(area, filepath.Join(basedir, curdir, "config_local.yml"))
-	loadRoutes(area, filepath.Join(basedir, curdir, "routes_local.yml"))
+	loadConfigFiles(area, filepath.Join(basedir, curdir, "config_local"))
+	loadRoutesFiles(area, filepath.Join(basedir, curdir, "routes_local"))
 
 	for _, child := range area.Childs {
 		load(child, basedir, filepath.Join(curdir, child.Name))
 	}
 }
 
+// loadConfigFiles loads all supported siblings of a config file, e.g. config.yml, config.toml and config.json
+func loadConfigFiles(area *Area, basename string) {
+	for _, ext := range extensions {
+		loadConfigFile(area, basename+ext)
+	}
+}
+
+// loadRoutesFiles loads all supported siblings of a routes file, together they replace the previously loaded routes
+func loadRoutesFiles(area *Area, basename string) {
+	var routes []Route
+	found := false
+	for _, ext := range extensions {
+		loaded, err := loadRoutes(area, basename+ext)
+		if err != nil {
+			continue
+		}
+		routes = append(routes, loaded...)
+		found = true
+	}
+
+	if found {
+		area.Routes = routes
+	}
+}
+
+// loadConfigDir loads all config fragments of a directory in lexical order
+func loadConfigDir(area *Area, dir string) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if DebugLog {
+			log.Println(err)
+		}
+		return
+	}
+
+	names := make([]string, 0, len(files))
+	for _, file := range files {
+		if file.IsDir() || !supported(file.Name()) {
+			continue
+		}
+		names = append(names, file.Name())
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		loadConfigFile(area, filepath.Join(dir, name))
+	}
+}
+
+func supported(filename string) bool {
+	ext := filepath.Ext(filename)
+	for _, e := range extensions {
+		if e == ext {
+			return true
+		}
+	}
+	return false
+}
+
 var regex = regexp.MustCompile(`%%ENV:([^%\n]+)%%(([^%\n]+)%%)?`)
 
 func loadConfigFile(area *Area, filename string) error {
@@ -94,10 +170,14 @@ func loadConfigFile(area *Area, filename string) error {
 	if DebugLog {
 		log.Println(area.Name, "loading", filename)
 	}
-	return loadConfig(area, config)
+	return loadConfigFormat(area, config, filepath.Ext(filename))
 }
 
 func loadConfig(area *Area, config []byte) error {
+	return loadConfigFormat(area, config, ".yml")
+}
+
+func loadConfigFormat(area *Area, config []byte, ext string) error {
 	config = []byte(regex.ReplaceAllFunc(
 		config,
 		func(a []byte) []byte {
@@ -110,7 +190,7 @@ func loadConfig(area *Area, config []byte) error {
 	))
 
 	cfg := make(Map)
-	err := yaml.Unmarshal(config, &cfg)
+	err := unmarshal(config, ext, &cfg)
 	if err != nil {
 		if DebugLog {
 			log.Println(err)
@@ -125,26 +205,58 @@ func loadConfig(area *Area, config []byte) error {
 	return area.LoadedConfig.Add(cfg)
 }
 
-func loadRoutes(area *Area, filename string) error {
+func loadRoutes(area *Area, filename string) ([]Route, error) {
 	routes, err := ioutil.ReadFile(filename)
 	if err != nil {
 		if DebugLog {
 			log.Println(err)
 		}
-		return err
+		return nil, err
 	}
 
-	err = yaml.Unmarshal(routes, &area.Routes)
+	var loaded []Route
+	if filepath.Ext(filename) == ".toml" {
+		// toml documents must be a table, so routes are declared as [[routes]]
+		var doc struct{ Routes []Route }
+		err = toml.Unmarshal(routes, &doc)
+		loaded = doc.Routes
+	} else {
+		err = unmarshal(routes, filepath.Ext(filename), &loaded)
+	}
 	if err != nil {
 		if DebugLog {
 			log.Println(err)
 		}
-		return err
+		return nil, err
 	}
 
 	if DebugLog {
 		log.Println(area.Name, "loading", filename)
 	}
 
-	return nil
+	return loaded, nil
+}
+
+// unmarshal decodes data depending on the file extension, falling back to yaml
+func unmarshal(data []byte, ext string, v interface{}) error {
+	switch ext {
+	case ".json":
+		return json.Unmarshal(data, v)
+
+	case ".toml":
+		// decode generically first and take the json roundtrip, so numbers and maps end up
+		// with the same types as for yaml and json files
+		var doc map[string]interface{}
+		if err := toml.Unmarshal(data, &doc); err != nil {
+			return err
+		}
+		data, err := json.Marshal(doc)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(data, v)
+
+	}
+
+	return yaml.Unmarshal(data, v)
 }
@@ -30,6 +30,7 @@ func TestLoad(t *testing.T) {
 	assert.Equal(t, Shim("injected", true), Shim(root.Configuration.Get("env.var.test4")))
 
 	assert.NoError(t, os.Setenv("CONTEXT", "dev"))
+	defer os.Unsetenv("CONTEXT")
 	err = Load(root, "testdata")
 	assert.NoError(t, err)
 	assert.Contains(t, root.Configuration.Flat(), "area")
@@ -39,3 +40,39 @@ func TestLoad(t *testing.T) {
 func Shim(a, b interface{}) []interface{} {
 	return []interface{}{a, b}
 }
+
+func TestLoadFormats(t *testing.T) {
+	root := new(Area)
+	load(root, "testdata/formats", "/")
+
+	_, err := root.GetFlatContexts()
+	assert.NoError(t, err)
+
+	flat := root.Configuration.Flat()
+	assert.Equal(t, true, flat["format.yml"])
+	assert.Equal(t, true, flat["format.toml"])
+	assert.Equal(t, true, flat["format.json"])
+	assert.Equal(t, float64(1), flat["number"])
+	assert.Equal(t, "json", flat["override"], "json siblings are loaded last")
+
+	t.Run("config.d", func(t *testing.T) {
+		assert.Equal(t, true, flat["fragment.first"])
+		assert.Equal(t, true, flat["fragment.second"])
+		assert.Equal(t, "second", flat["order"], "fragments are loaded in lexical order")
+	})
+
+	t.Run("routes", func(t *testing.T) {
+		assert.Equal(t, []Route{
+			{Path: "/yml", Controller: "yml", Name: "yml.route"},
+			{Path: "/toml", Controller: "toml", Methods: []string{"GET", "POST"}, Filters: []string{"auth"}, Args: map[string]interface{}{"page": int64(1)}},
+			{Path: "/json", Controller: "json", Args: map[string]interface{}{"category": "shoes"}},
+		}, root.Routes)
+	})
+}
+
+func TestLoadRoutesOverride(t *testing.T) {
+	root := new(Area)
+	load(root, "testdata/routes", "/")
+
+	assert.Equal(t, []Route{{Path: "/", Controller: "local.home"}}, root.Routes, "routes_local.yml replaces routes.yml")
+}
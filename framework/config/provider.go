@@ -0,0 +1,139 @@
+package config
+
+import (
+	"context"
+	"reflect"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+type (
+	// Provider provides configuration from an external source, such as a key-value service.
+	// Providers are consulted by Load after all config files have been loaded.
+	Provider interface {
+		// Load the configuration of an area, identified by its full name, e.g. `root/de`.
+		// Providers return an empty Map if they don't know the area.
+		Load(ctx context.Context, area string) (Map, error)
+	}
+
+	// ConfigChangedEvent is dispatched when a provider refresh changed the configuration of an area
+	ConfigChangedEvent struct {
+		// Area is the full name of the area, e.g. `root/de`
+		Area string
+		// Config is the provided configuration of the area after the refresh
+		Config Map
+		// Changed contains the flat keys which have been added, changed or removed
+		Changed []string
+	}
+)
+
+// HasProviders checks if the area tree has been loaded with providers
+func (area *Area) HasProviders() bool {
+	return len(area.root().providers) > 0
+}
+
+// RefreshProviders loads the provided configuration of all areas again and returns an event for every changed area.
+// The already loaded configuration, and therefore the injected values, are not modified.
+func (area *Area) RefreshProviders(ctx context.Context) ([]*ConfigChangedEvent, error) {
+	var events []*ConfigChangedEvent
+
+	err := area.walk(func(a *Area) error {
+		provided, err := a.loadProviders(ctx)
+		if err != nil {
+			return err
+		}
+
+		changed := changedKeys(a.provided, provided)
+		a.provided = provided
+		if len(changed) > 0 {
+			events = append(events, &ConfigChangedEvent{Area: a.fullName(), Config: provided, Changed: changed})
+		}
+		return nil
+	})
+
+	return events, err
+}
+
+// applyProviders merges the provided configuration into the loaded configuration of all areas
+func applyProviders(ctx context.Context, root *Area) error {
+	return root.walk(func(a *Area) error {
+		provided, err := a.loadProviders(ctx)
+		if err != nil {
+			return err
+		}
+		a.provided = provided
+
+		if a.LoadedConfig == nil {
+			a.LoadedConfig = make(Map)
+		}
+		return a.LoadedConfig.Add(provided)
+	})
+}
+
+func (area *Area) loadProviders(ctx context.Context) (Map, error) {
+	provided := make(Map)
+	for _, provider := range area.root().providers {
+		cfg, err := provider.Load(ctx, area.fullName())
+		if err != nil {
+			return nil, errors.Wrapf(err, "config provider %T for area %q", provider, area.fullName())
+		}
+		if err := provided.Add(cfg); err != nil {
+			return nil, errors.Wrapf(err, "config provider %T for area %q", provider, area.fullName())
+		}
+	}
+	return provided, nil
+}
+
+func (area *Area) walk(fnc func(*Area) error) error {
+	if err := fnc(area); err != nil {
+		return err
+	}
+	for _, child := range area.Childs {
+		if err := child.walk(fnc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (area *Area) root() *Area {
+	if area.Parent == nil {
+		return area
+	}
+	return area.Parent.root()
+}
+
+// fullName of the area, which is the same as used by Flat, e.g. `root/de`
+func (area *Area) fullName() string {
+	if area.Parent == nil {
+		return area.Name
+	}
+	return area.Parent.fullName() + "/" + area.Name
+}
+
+// changedKeys returns the sorted flat keys which differ between two maps
+func changedKeys(old, new Map) []string {
+	oldFlat, newFlat := old.Flat(), new.Flat()
+
+	var changed []string
+	for k, v := range newFlat {
+		if _, isMap := v.(Map); isMap {
+			continue
+		}
+		if ov, ok := oldFlat[k]; !ok || !reflect.DeepEqual(ov, v) {
+			changed = append(changed, k)
+		}
+	}
+	for k, v := range oldFlat {
+		if _, isMap := v.(Map); isMap {
+			continue
+		}
+		if _, ok := newFlat[k]; !ok {
+			changed = append(changed, k)
+		}
+	}
+
+	sort.Strings(changed)
+	return changed
+}
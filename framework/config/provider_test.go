@@ -0,0 +1,115 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDirProvider(t *testing.T) {
+	provider := &DirProvider{Dir: "testdata/provider"}
+
+	cfg, err := provider.Load(context.Background(), "root")
+	assert.NoError(t, err)
+	assert.Equal(t, Map{"provided": Map{"root": true}, "override": "provider"}, cfg)
+
+	cfg, err = provider.Load(context.Background(), "root/de")
+	assert.NoError(t, err)
+	assert.Equal(t, Map{"provided": Map{"de": true}}, cfg)
+
+	cfg, err = provider.Load(context.Background(), "root/unknown")
+	assert.NoError(t, err)
+	assert.Equal(t, Map{}, cfg)
+}
+
+// testConfigServer is a tiny stand-in for a remote config service
+type testConfigServer struct {
+	sync.Mutex
+	areas map[string]Map
+}
+
+func (s *testConfigServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+
+	cfg, ok := s.areas[r.URL.Path]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(cfg)
+}
+
+func (s *testConfigServer) set(area string, cfg Map) {
+	s.Lock()
+	defer s.Unlock()
+	s.areas[area] = cfg
+}
+
+func TestHTTPProvider(t *testing.T) {
+	configServer := &testConfigServer{areas: map[string]Map{
+		"/config/root": {"remote": "root"},
+	}}
+	server := httptest.NewServer(configServer)
+	defer server.Close()
+
+	provider := &HTTPProvider{URL: server.URL + "/config/"}
+
+	cfg, err := provider.Load(context.Background(), "root")
+	assert.NoError(t, err)
+	assert.Equal(t, Map{"remote": "root"}, cfg)
+
+	cfg, err = provider.Load(context.Background(), "root/de")
+	assert.NoError(t, err)
+	assert.Equal(t, Map{}, cfg)
+
+	_, err = (&HTTPProvider{URL: server.URL + "/invalid"}).Load(context.Background(), "root")
+	assert.NoError(t, err, "a 404 must be handled as empty config")
+}
+
+func TestLoadProviders(t *testing.T) {
+	configServer := &testConfigServer{areas: map[string]Map{
+		"/root":    {"remote": "root", "override": "remote"},
+		"/root/de": {"remote": "de"},
+	}}
+	server := httptest.NewServer(configServer)
+	defer server.Close()
+
+	root := NewArea("root", nil, NewArea("de", nil))
+	assert.NoError(t, Load(root, "not-existing", &DirProvider{Dir: "testdata/provider"}, &HTTPProvider{URL: server.URL}))
+	assert.True(t, root.HasProviders())
+
+	flat := root.Configuration.Flat()
+	assert.Equal(t, true, flat["provided.root"])
+	assert.Equal(t, "root", flat["remote"])
+	assert.Equal(t, "remote", flat["override"], "later providers override earlier ones")
+
+	de := root.Childs[0].Configuration.Flat()
+	assert.Equal(t, true, de["provided.de"])
+	assert.Equal(t, "de", de["remote"])
+
+	t.Run("refresh without changes", func(t *testing.T) {
+		events, err := root.RefreshProviders(context.Background())
+		assert.NoError(t, err)
+		assert.Empty(t, events)
+	})
+
+	t.Run("refresh with changes", func(t *testing.T) {
+		configServer.set("/root/de", Map{"remote": "de-changed", "added": true})
+
+		events, err := root.RefreshProviders(context.Background())
+		assert.NoError(t, err)
+		if assert.Len(t, events, 1) {
+			assert.Equal(t, "root/de", events[0].Area)
+			assert.Equal(t, []string{"added", "remote"}, events[0].Changed)
+			assert.Equal(t, "de-changed", events[0].Config.Flat()["remote"])
+		}
+
+		assert.Equal(t, "de", root.Childs[0].Configuration.Flat()["remote"], "loaded configuration must not change")
+	})
+}
@@ -0,0 +1,76 @@
+package framework
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"flamingo.me/flamingo/v3/framework/config"
+	"flamingo.me/flamingo/v3/framework/flamingo"
+)
+
+// configRefresher polls the config providers and dispatches a config.ConfigChangedEvent for changed areas
+type configRefresher struct {
+	area        *config.Area
+	eventRouter flamingo.EventRouter
+	logger      flamingo.Logger
+	interval    time.Duration
+
+	stop      chan struct{}
+	startOnce sync.Once
+	stopOnce  sync.Once
+}
+
+// Inject dependencies
+func (r *configRefresher) Inject(
+	area *config.Area,
+	eventRouter flamingo.EventRouter,
+	logger flamingo.Logger,
+	cfg *struct {
+		Interval float64 `inject:"config:flamingo.config.refresh"`
+	},
+) {
+	r.area = area
+	r.eventRouter = eventRouter
+	r.logger = logger.WithField(flamingo.LogKeyCategory, "config")
+	r.interval = time.Duration(cfg.Interval) * time.Millisecond
+	r.stop = make(chan struct{})
+}
+
+// Notify starts polling on startup and stops it on shutdown, repeated startup events do not start another poller
+func (r *configRefresher) Notify(ctx context.Context, event flamingo.Event) {
+	switch event.(type) {
+	case *flamingo.StartupEvent:
+		if r.interval > 0 && r.area.HasProviders() {
+			r.startOnce.Do(func() { go r.poll() })
+		}
+	case *flamingo.ShutdownEvent:
+		r.stopOnce.Do(func() { close(r.stop) })
+	}
+}
+
+func (r *configRefresher) poll() {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+			r.refresh(context.Background())
+		}
+	}
+}
+
+func (r *configRefresher) refresh(ctx context.Context) {
+	events, err := r.area.RefreshProviders(ctx)
+	if err != nil {
+		r.logger.WithContext(ctx).Error("config refresh failed: ", err)
+	}
+
+	for _, event := range events {
+		r.logger.WithContext(ctx).Info("config of area ", event.Area, " changed: ", event.Changed)
+		r.eventRouter.Dispatch(ctx, event)
+	}
+}
@@ -8,7 +8,14 @@ import (
 
 type (
 	// SessionFlashController takes care of supported flash messages
-	SessionFlashController struct{}
+	SessionFlashController struct {
+		translator FlashTranslator
+	}
+
+	// FlashTranslator resolves the translation keys of flash messages, e.g. bound by the locale module
+	FlashTranslator interface {
+		TranslateFlash(key string, args map[string]interface{}) string
+	}
 
 	// FlashMessage contains a type and a printable message
 	FlashMessage struct {
@@ -17,14 +24,39 @@ type (
 	}
 )
 
-func getMessages(r *web.Request) (messages []interface{}) {
-	for _, flash := range r.Session().Flashes() {
-		messages = append(messages, FlashMessage{Message: flash})
+// Inject dependencies
+func (sfc *SessionFlashController) Inject(cfg *struct {
+	Translator FlashTranslator `inject:",optional"`
+}) *SessionFlashController {
+	if cfg != nil {
+		sfc.translator = cfg.Translator
+	}
+	return sfc
+}
+
+func (sfc *SessionFlashController) getMessages(r *web.Request, level web.FlashLevel, queue string) (messages []interface{}) {
+	var queues []string
+	if queue != "" {
+		queues = append(queues, queue)
+	}
+
+	for _, flash := range r.Session().FlashMessages(level, queues...) {
+		// flashes which are not strings are returned as they are
+		if flash.Value != nil {
+			messages = append(messages, FlashMessage{Type: string(flash.Level), Message: flash.Value})
+			continue
+		}
+
+		message := flash.Message
+		if sfc.translator != nil {
+			message = sfc.translator.TranslateFlash(flash.Message, flash.Args)
+		}
+		messages = append(messages, FlashMessage{Type: string(flash.Level), Message: message})
 	}
 	return
 }
 
-// Data Controller for sessionflashcontroller
-func (sfc *SessionFlashController) Data(c context.Context, r *web.Request, _ web.RequestParams) interface{} {
-	return getMessages(r)
+// Data Controller for sessionflashcontroller, the messages can be filtered by the params `level` and `queue`
+func (sfc *SessionFlashController) Data(c context.Context, r *web.Request, params web.RequestParams) interface{} {
+	return sfc.getMessages(r, web.FlashLevel(params["level"]), params["queue"])
 }
@@ -0,0 +1,41 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"flamingo.me/flamingo/v3/framework/web"
+	"github.com/stretchr/testify/assert"
+)
+
+type testFlashTranslator struct{}
+
+func (testFlashTranslator) TranslateFlash(key string, args map[string]interface{}) string {
+	return fmt.Sprintf("translated %s %v", key, args["name"])
+}
+
+func TestSessionFlashController_Data(t *testing.T) {
+	session := web.EmptySession()
+	session.AddFlashMessage(web.FlashSuccess, "login.success", map[string]interface{}{"name": "flamingo"})
+	session.AddFlashMessage(web.FlashError, "checkout.failed", nil, "checkout")
+	request := web.CreateRequest(nil, session)
+
+	controller := new(SessionFlashController)
+	assert.Equal(t, []interface{}{FlashMessage{Type: "success", Message: "login.success"}}, controller.Data(context.Background(), request, nil))
+
+	session.AddFlashMessage(web.FlashSuccess, "login.success", map[string]interface{}{"name": "flamingo"})
+	controller.Inject(&struct {
+		Translator FlashTranslator `inject:",optional"`
+	}{Translator: testFlashTranslator{}})
+	assert.Nil(t, controller.Data(context.Background(), request, web.RequestParams{"level": "error"}))
+	assert.Equal(t, []interface{}{FlashMessage{Type: "success", Message: "translated login.success flamingo"}}, controller.Data(context.Background(), request, web.RequestParams{"level": "success"}))
+	assert.Equal(t, []interface{}{FlashMessage{Type: "error", Message: "translated checkout.failed <nil>"}}, controller.Data(context.Background(), request, web.RequestParams{"queue": "checkout"}))
+
+	session.AddFlash("login.plain")
+	session.AddFlash(map[string]interface{}{"custom": true})
+	assert.Equal(t, []interface{}{
+		FlashMessage{Type: "info", Message: "translated login.plain <nil>"},
+		FlashMessage{Type: "info", Message: map[string]interface{}{"custom": true}},
+	}, controller.Data(context.Background(), request, nil), "only strings are translated, other flashes are kept as they are")
+}
@@ -0,0 +1,60 @@
+package flamingo
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+
+	"flamingo.me/dingo"
+)
+
+type (
+	// Codec encodes single values, e.g. the session values or cached objects.
+	// Codecs are registered by name: injector.BindMap(new(flamingo.Codec), "msgpack").To(msgpackCodec{})
+	Codec interface {
+		Encode(value interface{}) ([]byte, error)
+		// Decode into the value, which is a pointer to the expected type
+		Decode(data []byte, value interface{}) error
+	}
+
+	// GobCodec encodes with encoding/gob, the types of values stored as interface have to be registered with gob.Register
+	GobCodec struct{}
+
+	// JSONCodec encodes with encoding/json, values decoded into an interface are the generic json types
+	JSONCodec struct{}
+)
+
+var (
+	_ Codec = new(GobCodec)
+	_ Codec = new(JSONCodec)
+)
+
+// BindCodecs registers the gob and json codecs, modules using codecs call it so they do not depend on each other
+func BindCodecs(injector *dingo.Injector) {
+	injector.BindMap(new(Codec), "gob").To(GobCodec{})
+	injector.BindMap(new(Codec), "json").To(JSONCodec{})
+}
+
+// Encode with gob
+func (GobCodec) Encode(value interface{}) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := gob.NewEncoder(buf).Encode(value); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Decode with gob
+func (GobCodec) Decode(data []byte, value interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(value)
+}
+
+// Encode with json
+func (JSONCodec) Encode(value interface{}) ([]byte, error) {
+	return json.Marshal(value)
+}
+
+// Decode with json
+func (JSONCodec) Decode(data []byte, value interface{}) error {
+	return json.Unmarshal(data, value)
+}
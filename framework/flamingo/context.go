@@ -0,0 +1,37 @@
+package flamingo
+
+import (
+	"context"
+	"time"
+)
+
+// detachedContext keeps the values of a context, but not its deadline and cancellation
+type detachedContext struct {
+	parent context.Context
+}
+
+// DetachedContext returns a context with the values of the given context, but without its deadline and cancellation,
+// e.g. for work which continues after the request which started it is done
+func DetachedContext(ctx context.Context) context.Context {
+	return detachedContext{parent: ctx}
+}
+
+// Deadline is never set
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+// Done is never closed
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+// Err is always nil
+func (detachedContext) Err() error {
+	return nil
+}
+
+// Value of the parent context
+func (d detachedContext) Value(key interface{}) interface{} {
+	return d.parent.Value(key)
+}
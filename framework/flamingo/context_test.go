@@ -0,0 +1,23 @@
+package flamingo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetachedContext(t *testing.T) {
+	type key struct{}
+
+	parent, cancel := context.WithTimeout(context.WithValue(context.Background(), key{}, "value"), time.Second)
+	cancel()
+
+	ctx := DetachedContext(parent)
+	assert.NoError(t, ctx.Err())
+	assert.Nil(t, ctx.Done())
+	_, ok := ctx.Deadline()
+	assert.False(t, ok)
+	assert.Equal(t, "value", ctx.Value(key{}))
+}
@@ -0,0 +1,294 @@
+package flamingo
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"flamingo.me/flamingo/v3/framework/config"
+	"github.com/gorilla/sessions"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/hkdf"
+)
+
+const (
+	// cookieChunkSize is the maximum length of a cookie value, browsers limit cookies to about 4096 bytes including the attributes
+	cookieChunkSize = 3800
+
+	// cookieKeyInfo binds the keys derived from the secrets to the cookie session encryption
+	cookieKeyInfo = "flamingo cookie session AES-256-GCM"
+)
+
+type (
+	cookieSessionBackend struct {
+		secrets   []string
+		maxChunks int
+	}
+
+	// cookieSessionStore stores the AES-GCM encrypted session in cookies, which are split into chunks if necessary
+	cookieSessionStore struct {
+		Options    *sessions.Options
+		aeads      []cipher.AEAD
+		maxChunks  int
+		now        func() time.Time
+		serializer *SessionSerializer
+	}
+
+	// cookieSession contains either the gob encoded Values, or the Data encoded by the session serializer
+	cookieSession struct {
+		ID     string
+		Saved  int64
+		Values map[interface{}]interface{}
+		Data   []byte
+	}
+)
+
+var (
+	_ SessionBackend = new(cookieSessionBackend)
+	_ sessions.Store = new(cookieSessionStore)
+)
+
+// Inject dependencies
+func (b *cookieSessionBackend) Inject(config *struct {
+	Secrets config.Slice `inject:"config:session.cookiestore.secrets,optional"`
+	// float64 is used due to the injection as config from json - int is not possible on this
+	MaxChunks float64 `inject:"config:session.cookiestore.maxChunks"`
+}) {
+	_ = config.Secrets.MapInto(&b.secrets)
+	b.maxChunks = int(config.MaxChunks)
+}
+
+// Store in encrypted cookies, the first secret is used for writing, the others for reading during a key rotation.
+// Without `session.cookiestore.secrets` the `session.secret` is used, it fails if no secret or the default secret is configured.
+func (b *cookieSessionBackend) Store(options SessionOptions) (sessions.Store, error) {
+	secrets := make([][]byte, 0, len(b.secrets)+1)
+	for _, secret := range b.secrets {
+		if secret != "" {
+			secrets = append(secrets, []byte(secret))
+		}
+	}
+	if len(secrets) == 0 {
+		secrets = append(secrets, options.Secret)
+	}
+
+	for _, secret := range secrets {
+		if len(secret) == 0 || string(secret) == defaultSessionSecret {
+			return nil, errors.New("the cookie session backend needs a secret, configure session.cookiestore.secrets or session.secret")
+		}
+	}
+
+	store, err := newCookieSessionStore(secrets, b.maxChunks)
+	if err != nil {
+		return nil, err
+	}
+
+	store.Options.MaxAge = options.MaxAge
+	store.Options.Secure = options.Secure
+	store.Options.Path = options.Path
+	store.serializer = options.Serializer
+
+	return store, nil
+}
+
+func newCookieSessionStore(secrets [][]byte, maxChunks int) (*cookieSessionStore, error) {
+	if maxChunks < 1 {
+		maxChunks = 1
+	}
+
+	store := &cookieSessionStore{
+		Options: &sessions.Options{
+			Path:     "/",
+			HttpOnly: true,
+		},
+		maxChunks: maxChunks,
+		now:       time.Now,
+	}
+
+	for _, secret := range secrets {
+		if len(secret) == 0 {
+			return nil, errors.New("cookie session secrets must not be empty")
+		}
+		key := make([]byte, 32)
+		if _, err := io.ReadFull(hkdf.New(sha256.New, secret, nil, []byte(cookieKeyInfo)), key); err != nil {
+			return nil, err
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		store.aeads = append(store.aeads, aead)
+	}
+
+	return store, nil
+}
+
+// Get a cached session of the request
+func (s *cookieSessionStore) Get(r *http.Request, name string) (*sessions.Session, error) {
+	return sessions.GetRegistry(r).Get(s, name)
+}
+
+// New session, decoded from the cookies of the request if they are valid
+func (s *cookieSessionStore) New(r *http.Request, name string) (*sessions.Session, error) {
+	session := sessions.NewSession(s, name)
+	options := *s.Options
+	session.Options = &options
+	session.IsNew = true
+	session.ID = newCookieSessionID()
+
+	value := s.read(r, name)
+	if value == "" {
+		return session, nil
+	}
+
+	data, err := s.decode(name, value)
+	if err != nil {
+		return session, err
+	}
+	if s.Options.MaxAge > 0 && s.now().Unix()-data.Saved > int64(s.Options.MaxAge) {
+		return session, nil
+	}
+
+	session.ID = data.ID
+	session.Values = data.Values
+	session.IsNew = false
+
+	return session, nil
+}
+
+// Save the session in the cookies, an error is returned if it exceeds the maximum number of chunks
+func (s *cookieSessionStore) Save(r *http.Request, w http.ResponseWriter, session *sessions.Session) error {
+	var chunks []string
+
+	if session.ID == "" {
+		session.ID = newCookieSessionID()
+	}
+
+	if session.Options.MaxAge >= 0 {
+		data := &cookieSession{ID: session.ID, Saved: s.now().Unix(), Values: session.Values}
+		if s.serializer != nil {
+			serialized, err := s.serializer.Serialize(session.Values)
+			if err != nil {
+				return err
+			}
+			data.Values, data.Data = nil, serialized
+		}
+
+		value, err := s.encode(session.Name(), data)
+		if err != nil {
+			return err
+		}
+		if len(value) > s.maxChunks*cookieChunkSize {
+			return errors.Errorf("session %q exceeds the cookie size limit: %d bytes encoded, %d allowed in %d cookies", session.Name(), len(value), s.maxChunks*cookieChunkSize, s.maxChunks)
+		}
+
+		for len(value) > cookieChunkSize {
+			chunks = append(chunks, value[:cookieChunkSize])
+			value = value[cookieChunkSize:]
+		}
+		chunks = append(chunks, value)
+	}
+
+	for i, chunk := range chunks {
+		http.SetCookie(w, sessions.NewCookie(cookieChunkName(session.Name(), i), chunk, session.Options))
+	}
+
+	// remove the chunks which are not needed anymore
+	expired := *session.Options
+	expired.MaxAge = -1
+	for i := len(chunks); i < s.maxChunks; i++ {
+		if _, err := r.Cookie(cookieChunkName(session.Name(), i)); err == nil {
+			http.SetCookie(w, sessions.NewCookie(cookieChunkName(session.Name(), i), "", &expired))
+		}
+	}
+
+	return nil
+}
+
+// read the value of all chunks
+func (s *cookieSessionStore) read(r *http.Request, name string) string {
+	var value strings.Builder
+	for i := 0; i < s.maxChunks; i++ {
+		cookie, err := r.Cookie(cookieChunkName(name, i))
+		if err != nil {
+			break
+		}
+		value.WriteString(cookie.Value)
+	}
+	return value.String()
+}
+
+// encode the session with the newest secret, the cookie name is authenticated as well
+func (s *cookieSessionStore) encode(name string, data *cookieSession) (string, error) {
+	buf := new(bytes.Buffer)
+	if err := gob.NewEncoder(buf).Encode(data); err != nil {
+		return "", errors.Wrapf(err, "session %q can not be encoded", name)
+	}
+
+	aead := s.aeads[0]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(aead.Seal(nonce, nonce, buf.Bytes(), []byte(name))), nil
+}
+
+// decode the session with any of the secrets
+func (s *cookieSessionStore) decode(name, value string) (*cookieSession, error) {
+	ciphertext, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, errors.Wrapf(err, "session %q cookie", name)
+	}
+
+	for _, aead := range s.aeads {
+		if len(ciphertext) < aead.NonceSize() {
+			break
+		}
+		plaintext, err := aead.Open(nil, ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():], []byte(name))
+		if err != nil {
+			continue
+		}
+
+		data := new(cookieSession)
+		if err := gob.NewDecoder(bytes.NewReader(plaintext)).Decode(data); err != nil {
+			return nil, errors.Wrapf(err, "session %q can not be decoded", name)
+		}
+		if data.Values == nil {
+			data.Values = make(map[interface{}]interface{})
+		}
+		if data.Data != nil && s.serializer != nil {
+			if err := s.serializer.Deserialize(data.Data, &data.Values); err != nil {
+				return nil, errors.Wrapf(err, "session %q can not be decoded", name)
+			}
+		}
+		return data, nil
+	}
+
+	return nil, errors.Errorf("session %q cookie can not be decrypted", name)
+}
+
+func cookieChunkName(name string, i int) string {
+	if i == 0 {
+		return name
+	}
+	return fmt.Sprintf("%s_%d", name, i)
+}
+
+func newCookieSessionID() string {
+	id := make([]byte, 32)
+	_, _ = rand.Read(id)
+	return base64.RawURLEncoding.EncodeToString(id)
+}
@@ -0,0 +1,156 @@
+package flamingo
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/gob"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/sessions"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+)
+
+func init() {
+	gob.Register(&oauth2.Token{})
+}
+
+func cookieSessionRoundtrip(t *testing.T, write, read *cookieSessionStore, values map[interface{}]interface{}) (*sessions.Session, error) {
+	t.Helper()
+
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	session, err := write.New(request, "flamingo")
+	assert.NoError(t, err)
+	for k, v := range values {
+		session.Values[k] = v
+	}
+
+	recorder := httptest.NewRecorder()
+	if err := write.Save(request, recorder, session); err != nil {
+		return nil, err
+	}
+
+	request = httptest.NewRequest(http.MethodGet, "/", nil)
+	for _, cookie := range recorder.Result().Cookies() {
+		request.AddCookie(cookie)
+	}
+
+	return read.New(request, "flamingo")
+}
+
+func TestCookieSessionStore(t *testing.T) {
+	store, err := newCookieSessionStore([][]byte{[]byte("secret")}, 4)
+	assert.NoError(t, err)
+
+	t.Run("roundtrip", func(t *testing.T) {
+		token := &oauth2.Token{AccessToken: "access", RefreshToken: "refresh", Expiry: time.Unix(1500000000, 0)}
+		session, err := cookieSessionRoundtrip(t, store, store, map[interface{}]interface{}{"key": "value", "token": token})
+		assert.NoError(t, err)
+		assert.False(t, session.IsNew)
+		assert.Equal(t, "value", session.Values["key"])
+		assert.Equal(t, token.AccessToken, session.Values["token"].(*oauth2.Token).AccessToken)
+		assert.NotEmpty(t, session.ID)
+	})
+
+	t.Run("chunks", func(t *testing.T) {
+		large := make([]byte, 2*cookieChunkSize)
+		_, _ = rand.Read(large)
+
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+		session, _ := store.New(request, "flamingo")
+		session.Values["large"] = base64.StdEncoding.EncodeToString(large)
+		recorder := httptest.NewRecorder()
+		assert.NoError(t, store.Save(request, recorder, session))
+
+		cookies := recorder.Result().Cookies()
+		assert.Len(t, cookies, 4)
+		assert.Equal(t, "flamingo_3", cookies[3].Name)
+
+		request = httptest.NewRequest(http.MethodGet, "/", nil)
+		for _, cookie := range cookies {
+			request.AddCookie(cookie)
+		}
+		loaded, err := store.New(request, "flamingo")
+		assert.NoError(t, err)
+		assert.Equal(t, session.Values["large"], loaded.Values["large"])
+
+		delete(loaded.Values, "large")
+		recorder = httptest.NewRecorder()
+		assert.NoError(t, store.Save(request, recorder, loaded))
+		cookies = recorder.Result().Cookies()
+		assert.Len(t, cookies, 4)
+		for _, cookie := range cookies[1:] {
+			assert.Equal(t, -1, cookie.MaxAge, "unused chunks are removed")
+		}
+	})
+
+	t.Run("size limit", func(t *testing.T) {
+		large := make([]byte, 4*cookieChunkSize)
+		_, err := cookieSessionRoundtrip(t, store, store, map[interface{}]interface{}{"large": large})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), `session "flamingo" exceeds the cookie size limit`)
+	})
+
+	t.Run("key rotation", func(t *testing.T) {
+		rotated, err := newCookieSessionStore([][]byte{[]byte("new secret"), []byte("secret")}, 4)
+		assert.NoError(t, err)
+
+		session, err := cookieSessionRoundtrip(t, store, rotated, map[interface{}]interface{}{"key": "value"})
+		assert.NoError(t, err)
+		assert.Equal(t, "value", session.Values["key"], "old secrets can be read")
+
+		_, err = cookieSessionRoundtrip(t, rotated, store, map[interface{}]interface{}{"key": "value"})
+		assert.EqualError(t, err, `session "flamingo" cookie can not be decrypted`, "the newest secret is used for writing")
+	})
+
+	t.Run("tampered", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+		request.AddCookie(&http.Cookie{Name: "flamingo", Value: "dGFtcGVyZWQgc2Vzc2lvbiBjb29raWU"})
+		session, err := store.New(request, "flamingo")
+		assert.Error(t, err)
+		assert.True(t, session.IsNew)
+		assert.Empty(t, session.Values)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		expiring, err := newCookieSessionStore([][]byte{[]byte("secret")}, 4)
+		assert.NoError(t, err)
+		expiring.Options.MaxAge = 60
+
+		writer, _ := newCookieSessionStore([][]byte{[]byte("secret")}, 4)
+		writer.now = func() time.Time { return time.Now().Add(-2 * time.Minute) }
+
+		session, err := cookieSessionRoundtrip(t, writer, expiring, map[interface{}]interface{}{"key": "value"})
+		assert.NoError(t, err)
+		assert.True(t, session.IsNew)
+		assert.Empty(t, session.Values)
+	})
+}
+
+func TestCookieSessionBackend(t *testing.T) {
+	backend := &cookieSessionBackend{maxChunks: 2}
+
+	_, err := backend.Store(SessionOptions{})
+	assert.Error(t, err, "no secret")
+	_, err = backend.Store(SessionOptions{Secret: []byte(defaultSessionSecret)})
+	assert.Error(t, err, "default secret")
+
+	store, err := backend.Store(SessionOptions{Secret: []byte("session secret"), MaxAge: 3600, Secure: true, Path: "/shop"})
+	assert.NoError(t, err)
+
+	cookieStore := store.(*cookieSessionStore)
+	assert.Len(t, cookieStore.aeads, 1, "the session secret is used without cookie secrets")
+	assert.Equal(t, &sessions.Options{Path: "/shop", MaxAge: 3600, Secure: true, HttpOnly: true}, cookieStore.Options)
+
+	backend.secrets = []string{"new", "old"}
+	store, err = backend.Store(SessionOptions{Secret: []byte(defaultSessionSecret)})
+	assert.NoError(t, err, "the session secret is not used with cookie secrets")
+	assert.Len(t, store.(*cookieSessionStore).aeads, 2)
+
+	backend.secrets = []string{"new", defaultSessionSecret}
+	_, err = backend.Store(SessionOptions{})
+	assert.Error(t, err, "default secret in the cookie secrets")
+}
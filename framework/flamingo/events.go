@@ -2,8 +2,19 @@ package flamingo
 
 import (
 	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"flamingo.me/dingo"
+	"flamingo.me/flamingo/v3/framework/opencensus"
+	"github.com/pkg/errors"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
 )
 
 type (
@@ -20,48 +31,355 @@ type (
 		Notify(ctx context.Context, event Event)
 	}
 
+	// errorEventSubscriber is notified of an event and can return an error
+	errorEventSubscriber interface {
+		Notify(ctx context.Context, event Event) error
+	}
+
+	// TypedEventSubscriber is only notified of the event types it declares
+	TypedEventSubscriber interface {
+		// EventTypes returns an instance of every handled event type, e.g. []flamingo.Event{new(flamingo.StartupEvent)}
+		EventTypes() []Event
+	}
+
+	// PrioritizedEventSubscriber is notified in the order of its priority, higher priorities first.
+	// Subscribers without a priority have priority 0, subscribers with the same priority are notified in bind order.
+	PrioritizedEventSubscriber interface {
+		EventPriority() int
+	}
+
+	// AsyncEventSubscriber is notified asynchronously by the event router's worker pool if Async returns true
+	AsyncEventSubscriber interface {
+		Async() bool
+	}
+
+	// EventErrors are the collected errors of all subscribers of an event
+	EventErrors []error
+
 	// Flamingo default lifecycle events
 	StartupEvent        struct{} // dispatched when the application starts
 	ServerStartEvent    struct{} // dispatched when a server is started (not for CLI commands)
 	ServerShutdownEvent struct{} // dispatched when a server is stopped (not for CLI commands)
 	ShutdownEvent       struct{} // dispatched when the application shuts down
 
-	eventSubscriberProvider func() []eventSubscriber
+	eventSubscriberProvider      func() []eventSubscriber
+	errorEventSubscriberProvider func() []errorEventSubscriber
 
 	// DefaultEventRouter is a default event routing implementation
 	DefaultEventRouter struct {
-		provider eventSubscriberProvider
-		logger   Logger
+		provider      eventSubscriberProvider
+		errorProvider errorEventSubscriberProvider
+		logger        Logger
+
+		workers   int
+		queueSize int
+		poolOnce  sync.Once
+		poolMutex sync.RWMutex
+		queue     chan asyncNotification
+		closed    bool
+		running   sync.WaitGroup
+	}
+
+	subscription struct {
+		name       string
+		subscriber interface{}
+		notify     func(ctx context.Context, event Event) error
+		types      map[reflect.Type]struct{}
+		priority   int
+		async      bool
+	}
+
+	asyncNotification struct {
+		ctx          context.Context
+		subscription *subscription
+		event        Event
 	}
 )
 
+const (
+	defaultEventWorkers   = 4
+	defaultEventQueueSize = 100
+)
+
+var (
+	eventDispatched   = stats.Int64("flamingo/events/dispatched", "dispatched events", stats.UnitDimensionless)
+	eventNotification = stats.Float64("flamingo/events/notification", "event subscriber notification times", stats.UnitMilliseconds)
+	eventErrors       = stats.Int64("flamingo/events/errors", "event subscriber errors", stats.UnitDimensionless)
+
+	keyEvent, _      = tag.NewKey("event")
+	keySubscriber, _ = tag.NewKey("subscriber")
+)
+
+func init() {
+	if err := opencensus.View("flamingo/events/dispatched", eventDispatched, view.Count(), keyEvent); err != nil {
+		panic(err)
+	}
+	if err := opencensus.View("flamingo/events/notification", eventNotification, view.Distribution(1, 5, 10, 50, 100, 500, 1000, 5000), keyEvent, keySubscriber); err != nil {
+		panic(err)
+	}
+	if err := opencensus.View("flamingo/events/errors", eventErrors, view.Count(), keyEvent, keySubscriber); err != nil {
+		panic(err)
+	}
+}
+
+// Error returns all error messages
+func (e EventErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
 // Inject eventSubscriberProvider dependency
-func (d *DefaultEventRouter) Inject(provider eventSubscriberProvider, logger Logger) {
+func (d *DefaultEventRouter) Inject(
+	provider eventSubscriberProvider,
+	errorProvider errorEventSubscriberProvider,
+	logger Logger,
+	cfg *struct {
+		Workers   float64 `inject:"config:flamingo.eventrouter.workers,optional"`
+		QueueSize float64 `inject:"config:flamingo.eventrouter.queueSize,optional"`
+	},
+) {
 	d.provider = provider
+	d.errorProvider = errorProvider
 	d.logger = logger
+	if cfg != nil {
+		d.workers = int(cfg.Workers)
+		d.queueSize = int(cfg.QueueSize)
+	}
 }
 
-func catched(ctx context.Context, logger Logger, s eventSubscriber, e Event) {
+// Dispatch notifies all subscribers of the event, errors are logged
+func (d *DefaultEventRouter) Dispatch(ctx context.Context, event Event) {
+	for _, err := range d.dispatch(ctx, event, false) {
+		d.log(ctx, err)
+	}
+}
+
+// TryDispatch notifies all subscribers of the event and returns the collected errors of the synchronous subscribers as EventErrors
+func (d *DefaultEventRouter) TryDispatch(ctx context.Context, event Event) error {
+	if errs := d.dispatch(ctx, event, false); len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// DispatchAsync notifies all subscribers of the event by the worker pool and returns immediately.
+// The pool is bounded, if its queue is full the subscribers are notified by the caller instead.
+func (d *DefaultEventRouter) DispatchAsync(ctx context.Context, event Event) {
+	d.dispatch(ctx, event, true)
+}
+
+func (d *DefaultEventRouter) dispatch(ctx context.Context, event Event, async bool) EventErrors {
+	if d.provider == nil && d.errorProvider == nil {
+		return nil
+	}
+
+	eventType := reflect.TypeOf(event)
+	ctx, _ = tag.New(ctx, tag.Upsert(keyEvent, fmt.Sprint(eventType)))
+	stats.Record(ctx, eventDispatched.M(1))
+
+	var errs EventErrors
+	for _, s := range d.subscriptions() {
+		if !s.handles(eventType) {
+			continue
+		}
+
+		if async || s.async {
+			d.enqueue(asyncNotification{ctx: DetachedContext(ctx), subscription: s, event: event})
+			continue
+		}
+
+		if err := s.call(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if _, ok := event.(*ShutdownEvent); ok {
+		d.shutdown()
+	}
+
+	return errs
+}
+
+// subscriptions returns all subscribers ordered by priority
+func (d *DefaultEventRouter) subscriptions() []*subscription {
+	var subscriptions []*subscription
+
+	if d.provider != nil {
+		for _, s := range d.provider() {
+			s := s
+			subscriptions = append(subscriptions, newSubscription(s, func(ctx context.Context, event Event) error {
+				s.Notify(ctx, event)
+				return nil
+			}))
+		}
+	}
+
+	if d.errorProvider != nil {
+		for _, s := range d.errorProvider() {
+			subscriptions = append(subscriptions, newSubscription(s, s.Notify))
+		}
+	}
+
+	sort.SliceStable(subscriptions, func(i, j int) bool {
+		return subscriptions[i].priority > subscriptions[j].priority
+	})
+
+	return subscriptions
+}
+
+// Subscribers returns all bound subscribers in the order they are notified
+func (d *DefaultEventRouter) Subscribers() []interface{} {
+	subscriptions := d.subscriptions()
+	subscribers := make([]interface{}, len(subscriptions))
+	for i, s := range subscriptions {
+		subscribers[i] = s.subscriber
+	}
+	return subscribers
+}
+
+func newSubscription(subscriber interface{}, notify func(ctx context.Context, event Event) error) *subscription {
+	s := &subscription{
+		name:       fmt.Sprintf("%T", subscriber),
+		subscriber: subscriber,
+		notify:     notify,
+	}
+
+	if typed, ok := subscriber.(TypedEventSubscriber); ok {
+		s.types = make(map[reflect.Type]struct{})
+		for _, e := range typed.EventTypes() {
+			s.types[reflect.TypeOf(e)] = struct{}{}
+		}
+	}
+
+	if prioritized, ok := subscriber.(PrioritizedEventSubscriber); ok {
+		s.priority = prioritized.EventPriority()
+	}
+
+	if async, ok := subscriber.(AsyncEventSubscriber); ok {
+		s.async = async.Async()
+	}
+
+	return s
+}
+
+func (s *subscription) handles(eventType reflect.Type) bool {
+	if s.types == nil {
+		return true
+	}
+	_, ok := s.types[eventType]
+	return ok
+}
+
+// call notifies the subscriber, records its metrics and converts panics into errors
+func (s *subscription) call(ctx context.Context, event Event) (err error) {
+	ctx, _ = tag.New(ctx, tag.Upsert(keySubscriber, s.name))
+	start := time.Now()
+
 	defer func() {
-		if err := recover(); err != nil {
-			logger.Error(err)
+		if p := recover(); p != nil {
+			err = errors.Errorf("subscriber %s panicked on %T: %v", s.name, event, p)
+		}
+
+		stats.Record(ctx, eventNotification.M(float64(time.Since(start).Nanoseconds())/1000000))
+		if err != nil {
+			stats.Record(ctx, eventErrors.M(1))
 		}
 	}()
-	s.Notify(ctx, e)
+
+	if err := s.notify(ctx, event); err != nil {
+		return errors.Wrapf(err, "subscriber %s failed on %T", s.name, event)
+	}
+
+	return nil
 }
 
-// Dispatch calls the event's Dispatch method on each subscriber
-func (d *DefaultEventRouter) Dispatch(ctx context.Context, event Event) {
-	if d.provider == nil {
+func (d *DefaultEventRouter) log(ctx context.Context, err error) {
+	if d.logger != nil {
+		d.logger.WithContext(ctx).Error(err)
+	}
+}
+
+// enqueue never blocks: after shutdown or if the queue is full the notification is done by the caller,
+// so a subscriber dispatching events from a worker can not deadlock the pool
+func (d *DefaultEventRouter) enqueue(n asyncNotification) {
+	d.poolOnce.Do(d.startPool)
+
+	if !d.tryEnqueue(n) {
+		if err := n.subscription.call(n.ctx, n.event); err != nil {
+			d.log(n.ctx, err)
+		}
+	}
+}
+
+func (d *DefaultEventRouter) tryEnqueue(n asyncNotification) bool {
+	d.poolMutex.RLock()
+	defer d.poolMutex.RUnlock()
+
+	// after shutdown there are no workers anymore
+	if d.closed {
+		return false
+	}
+
+	select {
+	case d.queue <- n:
+		return true
+	default:
+		return false
+	}
+}
+
+func (d *DefaultEventRouter) startPool() {
+	d.poolMutex.Lock()
+	defer d.poolMutex.Unlock()
+
+	if d.closed {
 		return
 	}
 
-	for _, s := range d.provider() {
-		catched(ctx, d.logger, s, event)
+	workers, queueSize := d.workers, d.queueSize
+	if workers <= 0 {
+		workers = defaultEventWorkers
+	}
+	if queueSize <= 0 {
+		queueSize = defaultEventQueueSize
+	}
+
+	d.queue = make(chan asyncNotification, queueSize)
+	for i := 0; i < workers; i++ {
+		d.running.Add(1)
+		go func() {
+			defer d.running.Done()
+			for n := range d.queue {
+				if err := n.subscription.call(n.ctx, n.event); err != nil {
+					d.log(n.ctx, err)
+				}
+			}
+		}()
 	}
 }
 
+// shutdown stops the worker pool after all queued notifications are done
+func (d *DefaultEventRouter) shutdown() {
+	d.poolMutex.Lock()
+	if !d.closed && d.queue != nil {
+		close(d.queue)
+	}
+	d.closed = true
+	d.poolMutex.Unlock()
+
+	d.running.Wait()
+}
+
 // BindEventSubscriber is a helper to bind a private event Subscriber via Dingo
 func BindEventSubscriber(injector *dingo.Injector) *dingo.Binding {
 	return injector.BindMulti(new(eventSubscriber))
 }
+
+// BindErrorEventSubscriber is a helper to bind a private event Subscriber via Dingo, which returns errors on Notify.
+// The errors are logged, or returned by DefaultEventRouter.TryDispatch.
+func BindErrorEventSubscriber(injector *dingo.Injector) *dingo.Binding {
+	return injector.BindMulti(new(errorEventSubscriber))
+}
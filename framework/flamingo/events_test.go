@@ -0,0 +1,268 @@
+package flamingo
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type (
+	testEvent  struct{}
+	otherEvent struct{}
+
+	recordingSubscriber struct {
+		name     string
+		calls    *[]string
+		mutex    *sync.Mutex
+		types    []Event
+		priority int
+		async    bool
+	}
+
+	typedRecordingSubscriber struct {
+		recordingSubscriber
+	}
+
+	prioritizedRecordingSubscriber struct {
+		recordingSubscriber
+	}
+
+	asyncRecordingSubscriber struct {
+		recordingSubscriber
+	}
+
+	failingSubscriber struct {
+		err error
+	}
+
+	panickingSubscriber struct{}
+)
+
+func (s *recordingSubscriber) Notify(_ context.Context, _ Event) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	*s.calls = append(*s.calls, s.name)
+}
+
+func (s *typedRecordingSubscriber) EventTypes() []Event {
+	return s.types
+}
+
+func (s *prioritizedRecordingSubscriber) EventPriority() int {
+	return s.priority
+}
+
+func (s *asyncRecordingSubscriber) Async() bool {
+	return s.async
+}
+
+func (s *failingSubscriber) Notify(context.Context, Event) error {
+	return s.err
+}
+
+func (*panickingSubscriber) Notify(context.Context, Event) {
+	panic("oops")
+}
+
+func TestDefaultEventRouter_Dispatch(t *testing.T) {
+	var calls []string
+	mutex := new(sync.Mutex)
+	recorder := func(name string) recordingSubscriber {
+		return recordingSubscriber{name: name, calls: &calls, mutex: mutex}
+	}
+
+	typed := &typedRecordingSubscriber{recorder("typed")}
+	typed.types = []Event{new(otherEvent)}
+	low := &prioritizedRecordingSubscriber{recorder("low")}
+	low.priority = -10
+	high := &prioritizedRecordingSubscriber{recorder("high")}
+	high.priority = 10
+	plain := recorder("plain")
+
+	router := new(DefaultEventRouter)
+	router.Inject(
+		func() []eventSubscriber { return []eventSubscriber{low, typed, &plain, high} },
+		nil,
+		NullLogger{},
+		nil,
+	)
+
+	router.Dispatch(context.Background(), new(testEvent))
+	assert.Equal(t, []string{"high", "plain", "low"}, calls, "typed subscriber must be skipped, others ordered by priority")
+
+	calls = nil
+	router.Dispatch(context.Background(), new(otherEvent))
+	assert.Equal(t, []string{"high", "typed", "plain", "low"}, calls)
+}
+
+func TestDefaultEventRouter_TryDispatch(t *testing.T) {
+	router := new(DefaultEventRouter)
+	router.Inject(
+		func() []eventSubscriber { return []eventSubscriber{new(panickingSubscriber)} },
+		func() []errorEventSubscriber {
+			return []errorEventSubscriber{&failingSubscriber{err: errors.New("failed")}, &failingSubscriber{}}
+		},
+		NullLogger{},
+		nil,
+	)
+
+	err := router.TryDispatch(context.Background(), new(testEvent))
+	if assert.Error(t, err) {
+		errs, ok := err.(EventErrors)
+		assert.True(t, ok)
+		assert.Len(t, errs, 2)
+		assert.Contains(t, errs.Error(), "oops")
+		assert.Contains(t, errs.Error(), "failed")
+	}
+
+	router = new(DefaultEventRouter)
+	router.Inject(nil, func() []errorEventSubscriber { return []errorEventSubscriber{&failingSubscriber{}} }, NullLogger{}, nil)
+	assert.NoError(t, router.TryDispatch(context.Background(), new(testEvent)))
+}
+
+func TestDefaultEventRouter_Async(t *testing.T) {
+	var calls []string
+	mutex := new(sync.Mutex)
+
+	async := &asyncRecordingSubscriber{recordingSubscriber{name: "async", calls: &calls, mutex: mutex, async: true}}
+	syncSubscriber := &recordingSubscriber{name: "sync", calls: &calls, mutex: mutex}
+
+	router := new(DefaultEventRouter)
+	router.Inject(
+		func() []eventSubscriber { return []eventSubscriber{async, syncSubscriber} },
+		nil,
+		NullLogger{},
+		&struct {
+			Workers   float64 `inject:"config:flamingo.eventrouter.workers,optional"`
+			QueueSize float64 `inject:"config:flamingo.eventrouter.queueSize,optional"`
+		}{Workers: 2, QueueSize: 2},
+	)
+
+	for i := 0; i < 10; i++ {
+		router.DispatchAsync(context.Background(), new(testEvent))
+	}
+	router.Dispatch(context.Background(), new(testEvent))
+
+	// shutdown waits until all queued notifications are done
+	done := make(chan struct{})
+	go func() {
+		router.Dispatch(context.Background(), new(ShutdownEvent))
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("shutdown did not finish")
+	}
+
+	count := map[string]int{}
+	for _, c := range calls {
+		count[c]++
+	}
+	assert.Equal(t, map[string]int{"async": 12, "sync": 12}, count)
+
+	calls = nil
+	router.Dispatch(context.Background(), new(testEvent))
+	assert.ElementsMatch(t, []string{"async", "sync"}, calls, "after shutdown async subscribers are notified synchronously")
+}
+
+type (
+	redispatchingSubscriber struct {
+		router *DefaultEventRouter
+		mutex  sync.Mutex
+		other  int
+	}
+
+	contextRecordingSubscriber struct {
+		started chan struct{}
+		release chan struct{}
+		err     chan error
+		value   chan interface{}
+	}
+
+	testContextKey struct{}
+)
+
+func (s *redispatchingSubscriber) Async() bool { return true }
+
+func (s *redispatchingSubscriber) Notify(ctx context.Context, event Event) {
+	switch event.(type) {
+	case *testEvent:
+		for i := 0; i < 5; i++ {
+			s.router.DispatchAsync(ctx, new(otherEvent))
+		}
+	case *otherEvent:
+		s.mutex.Lock()
+		s.other++
+		s.mutex.Unlock()
+	}
+}
+
+func (s *contextRecordingSubscriber) Async() bool { return true }
+
+func (s *contextRecordingSubscriber) EventTypes() []Event { return []Event{new(testEvent)} }
+
+func (s *contextRecordingSubscriber) Notify(ctx context.Context, _ Event) {
+	close(s.started)
+	<-s.release
+	s.err <- ctx.Err()
+	s.value <- ctx.Value(testContextKey{})
+}
+
+func TestDefaultEventRouter_AsyncFromWorker(t *testing.T) {
+	subscriber := new(redispatchingSubscriber)
+	router := new(DefaultEventRouter)
+	router.Inject(
+		func() []eventSubscriber { return []eventSubscriber{subscriber} },
+		nil,
+		NullLogger{},
+		&struct {
+			Workers   float64 `inject:"config:flamingo.eventrouter.workers,optional"`
+			QueueSize float64 `inject:"config:flamingo.eventrouter.queueSize,optional"`
+		}{Workers: 1, QueueSize: 1},
+	)
+	subscriber.router = router
+
+	done := make(chan struct{})
+	go func() {
+		router.DispatchAsync(context.Background(), new(testEvent))
+		router.Dispatch(context.Background(), new(ShutdownEvent))
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("dispatching from a worker with a full queue must not deadlock")
+	}
+
+	subscriber.mutex.Lock()
+	defer subscriber.mutex.Unlock()
+	assert.Equal(t, 5, subscriber.other)
+}
+
+func TestDefaultEventRouter_AsyncContext(t *testing.T) {
+	subscriber := &contextRecordingSubscriber{
+		started: make(chan struct{}),
+		release: make(chan struct{}),
+		err:     make(chan error, 1),
+		value:   make(chan interface{}, 1),
+	}
+	router := new(DefaultEventRouter)
+	router.Inject(func() []eventSubscriber { return []eventSubscriber{subscriber} }, nil, NullLogger{}, nil)
+
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), testContextKey{}, "value"))
+	router.DispatchAsync(ctx, new(testEvent))
+	<-subscriber.started
+	cancel()
+	close(subscriber.release)
+
+	assert.NoError(t, <-subscriber.err, "async subscribers must not be canceled with the dispatching context")
+	assert.Equal(t, "value", <-subscriber.value)
+	router.Dispatch(context.Background(), new(ShutdownEvent))
+}
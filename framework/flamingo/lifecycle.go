@@ -0,0 +1,246 @@
+package flamingo
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"flamingo.me/dingo"
+	"github.com/pkg/errors"
+)
+
+type (
+	// LifecycleHook is started before the application runs and stopped when it shuts down
+	LifecycleHook interface {
+		Start(ctx context.Context) error
+		Stop(ctx context.Context) error
+	}
+
+	// LifecycleDependencies can be implemented by a LifecycleHook to declare the names of hooks which must be started before it
+	LifecycleDependencies interface {
+		DependsOn() []string
+	}
+
+	// LifecycleTimeout can be implemented by a LifecycleHook to override the default timeout for Start and Stop
+	LifecycleTimeout interface {
+		Timeout() time.Duration
+	}
+
+	lifecycleHookProvider func() map[string]LifecycleHook
+
+	// Lifecycle starts all lifecycle hooks in the order of their dependencies, and stops them in reverse order
+	Lifecycle struct {
+		hookProvider lifecycleHookProvider
+		logger       Logger
+		timeout      time.Duration
+
+		mutex   sync.Mutex
+		started []string
+		hooks   map[string]LifecycleHook
+		ready   bool
+	}
+)
+
+const defaultLifecycleTimeout = 30 * time.Second
+
+// BindLifecycleHook is a helper to bind a named LifecycleHook via Dingo
+func BindLifecycleHook(injector *dingo.Injector, name string) *dingo.Binding {
+	return injector.BindMap(new(LifecycleHook), name)
+}
+
+// NewLifecycle creates a lifecycle for the given hooks, the logger is optional
+func NewLifecycle(hooks map[string]LifecycleHook, logger Logger) *Lifecycle {
+	return &Lifecycle{
+		hookProvider: func() map[string]LifecycleHook { return hooks },
+		logger:       logger,
+	}
+}
+
+// Inject dependencies
+func (l *Lifecycle) Inject(
+	hookProvider lifecycleHookProvider,
+	logger Logger,
+	cfg *struct {
+		Timeout float64 `inject:"config:flamingo.lifecycle.timeout,optional"`
+	},
+) *Lifecycle {
+	l.hookProvider = hookProvider
+	l.logger = logger
+	if cfg != nil {
+		l.timeout = time.Duration(cfg.Timeout) * time.Millisecond
+	}
+	return l
+}
+
+// Start all hooks in the order of their dependencies.
+// If a hook fails, all already started hooks are stopped again and the error is returned.
+func (l *Lifecycle) Start(ctx context.Context) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.hookProvider != nil {
+		l.hooks = l.hookProvider()
+	}
+
+	order, err := lifecycleOrder(l.hooks)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range order {
+		if l.logger != nil {
+			l.logger.Debug("lifecycle: starting ", name)
+		}
+
+		if err := l.call(ctx, l.hooks[name], l.hooks[name].Start); err != nil {
+			err = errors.Wrapf(err, "lifecycle hook %q failed to start", name)
+			if stopErr := l.stop(ctx); stopErr != nil && l.logger != nil {
+				l.logger.Error(stopErr)
+			}
+			return err
+		}
+
+		l.started = append(l.started, name)
+	}
+
+	l.ready = true
+	return nil
+}
+
+// Stop all started hooks in reverse order. All hooks are stopped, even if one of them fails.
+func (l *Lifecycle) Stop(ctx context.Context) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return l.stop(ctx)
+}
+
+// Ready returns true once all hooks have been started, and until they are stopped
+func (l *Lifecycle) Ready() bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return l.ready
+}
+
+// Notify stops all hooks on shutdown
+func (l *Lifecycle) Notify(ctx context.Context, event Event) {
+	if _, ok := event.(*ShutdownEvent); ok {
+		if err := l.Stop(ctx); err != nil && l.logger != nil {
+			l.logger.Error(err)
+		}
+	}
+}
+
+// EventTypes of the Lifecycle
+func (l *Lifecycle) EventTypes() []Event {
+	return []Event{new(ShutdownEvent)}
+}
+
+// EventPriority ensures hooks are stopped after all other shutdown subscribers are done
+func (l *Lifecycle) EventPriority() int {
+	return -1000
+}
+
+func (l *Lifecycle) stop(ctx context.Context) error {
+	l.ready = false
+
+	var errs EventErrors
+	for i := len(l.started) - 1; i >= 0; i-- {
+		name := l.started[i]
+		if l.logger != nil {
+			l.logger.Debug("lifecycle: stopping ", name)
+		}
+
+		if err := l.call(ctx, l.hooks[name], l.hooks[name].Stop); err != nil {
+			errs = append(errs, errors.Wrapf(err, "lifecycle hook %q failed to stop", name))
+		}
+	}
+	l.started = nil
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// call a hook function with the hook's timeout, a timeout results in an error
+func (l *Lifecycle) call(ctx context.Context, hook LifecycleHook, fnc func(ctx context.Context) error) error {
+	timeout := l.timeout
+	if t, ok := hook.(LifecycleTimeout); ok {
+		timeout = t.Timeout()
+	}
+	if timeout <= 0 {
+		timeout = defaultLifecycleTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	result := make(chan error, 1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				result <- errors.Errorf("panic: %v", p)
+			}
+		}()
+		result <- fnc(ctx)
+	}()
+
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "timeout")
+	}
+}
+
+// lifecycleOrder returns the hook names sorted by their dependencies, hooks without dependencies between them are sorted by name
+func lifecycleOrder(hooks map[string]LifecycleHook) ([]string, error) {
+	names := make([]string, 0, len(hooks))
+	for name := range hooks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make(map[string]int, len(hooks))
+	order := make([]string, 0, len(hooks))
+
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		switch state[name] {
+		case visited:
+			return nil
+		case visiting:
+			return errors.Errorf("lifecycle hooks have circular dependencies: %v", append(path, name))
+		}
+
+		state[name] = visiting
+		if deps, ok := hooks[name].(LifecycleDependencies); ok {
+			for _, dep := range deps.DependsOn() {
+				if _, ok := hooks[dep]; !ok {
+					return errors.Errorf("lifecycle hook %q depends on unknown hook %q", name, dep)
+				}
+				if err := visit(dep, append(path, name)); err != nil {
+					return err
+				}
+			}
+		}
+		state[name] = visited
+		order = append(order, name)
+		return nil
+	}
+
+	for _, name := range names {
+		if err := visit(name, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return order, nil
+}
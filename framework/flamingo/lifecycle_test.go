@@ -0,0 +1,151 @@
+package flamingo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type (
+	testHook struct {
+		name     string
+		calls    *[]string
+		startErr error
+		stopErr  error
+		deps     []string
+		delay    time.Duration
+	}
+
+	dependingTestHook struct {
+		testHook
+	}
+
+	slowTestHook struct {
+		testHook
+	}
+)
+
+func (h *testHook) Start(ctx context.Context) error {
+	if h.delay > 0 {
+		select {
+		case <-time.After(h.delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	*h.calls = append(*h.calls, "start "+h.name)
+	return h.startErr
+}
+
+func (h *testHook) Stop(context.Context) error {
+	*h.calls = append(*h.calls, "stop "+h.name)
+	return h.stopErr
+}
+
+func (h *dependingTestHook) DependsOn() []string {
+	return h.deps
+}
+
+func (h *slowTestHook) Timeout() time.Duration {
+	return 10 * time.Millisecond
+}
+
+func TestLifecycle_StartStop(t *testing.T) {
+	var calls []string
+
+	cache := &dependingTestHook{testHook{name: "cache", calls: &calls}}
+	cache.deps = []string{"database"}
+	worker := &dependingTestHook{testHook{name: "worker", calls: &calls}}
+	worker.deps = []string{"cache", "database"}
+
+	lifecycle := NewLifecycle(map[string]LifecycleHook{
+		"worker":   worker,
+		"cache":    cache,
+		"database": &testHook{name: "database", calls: &calls},
+		"audit":    &testHook{name: "audit", calls: &calls},
+	}, NullLogger{})
+
+	assert.False(t, lifecycle.Ready())
+	assert.NoError(t, lifecycle.Start(context.Background()))
+	assert.True(t, lifecycle.Ready())
+	assert.Equal(t, []string{"start audit", "start database", "start cache", "start worker"}, calls)
+
+	calls = nil
+	lifecycle.Notify(context.Background(), new(ShutdownEvent))
+	assert.False(t, lifecycle.Ready())
+	assert.Equal(t, []string{"stop worker", "stop cache", "stop database", "stop audit"}, calls)
+
+	calls = nil
+	assert.NoError(t, lifecycle.Stop(context.Background()))
+	assert.Empty(t, calls, "hooks are only stopped once")
+}
+
+func TestLifecycle_StartFailure(t *testing.T) {
+	var calls []string
+
+	second := &dependingTestHook{testHook{name: "second", calls: &calls, startErr: errors.New("boom")}}
+	second.deps = []string{"first"}
+	third := &dependingTestHook{testHook{name: "third", calls: &calls}}
+	third.deps = []string{"second"}
+
+	lifecycle := NewLifecycle(map[string]LifecycleHook{
+		"first":  &testHook{name: "first", calls: &calls},
+		"second": second,
+		"third":  third,
+	}, NullLogger{})
+
+	err := lifecycle.Start(context.Background())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `lifecycle hook "second" failed to start: boom`)
+	assert.False(t, lifecycle.Ready())
+	assert.Equal(t, []string{"start first", "start second", "stop first"}, calls)
+}
+
+func TestLifecycle_Timeout(t *testing.T) {
+	var calls []string
+
+	lifecycle := NewLifecycle(map[string]LifecycleHook{
+		"slow": &slowTestHook{testHook{name: "slow", calls: &calls, delay: time.Second}},
+	}, NullLogger{})
+
+	err := lifecycle.Start(context.Background())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "timeout")
+	assert.False(t, lifecycle.Ready())
+}
+
+func TestLifecycle_StopErrors(t *testing.T) {
+	var calls []string
+
+	lifecycle := NewLifecycle(map[string]LifecycleHook{
+		"a": &testHook{name: "a", calls: &calls, stopErr: errors.New("a failed")},
+		"b": &testHook{name: "b", calls: &calls, stopErr: errors.New("b failed")},
+	}, NullLogger{})
+
+	assert.NoError(t, lifecycle.Start(context.Background()))
+	err := lifecycle.Stop(context.Background())
+	assert.Error(t, err)
+	assert.Len(t, err.(EventErrors), 2)
+	assert.Equal(t, []string{"start a", "start b", "stop b", "stop a"}, calls)
+}
+
+func TestLifecycle_InvalidDependencies(t *testing.T) {
+	var calls []string
+
+	unknown := &dependingTestHook{testHook{name: "unknown", calls: &calls}}
+	unknown.deps = []string{"missing"}
+	assert.Error(t, NewLifecycle(map[string]LifecycleHook{"unknown": unknown}, NullLogger{}).Start(context.Background()))
+
+	a := &dependingTestHook{testHook{name: "a", calls: &calls}}
+	a.deps = []string{"b"}
+	b := &dependingTestHook{testHook{name: "b", calls: &calls}}
+	b.deps = []string{"a"}
+	err := NewLifecycle(map[string]LifecycleHook{"a": a, "b": b}, NullLogger{}).Start(context.Background())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "circular")
+
+	assert.Empty(t, calls)
+}
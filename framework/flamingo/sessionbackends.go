@@ -0,0 +1,137 @@
+package flamingo
+
+import (
+	"os"
+	"sync"
+
+	"github.com/boj/redistore"
+	"github.com/gomodule/redigo/redis"
+	"github.com/gorilla/securecookie"
+	"github.com/gorilla/sessions"
+	"github.com/zemirco/memorystore"
+)
+
+type (
+	memorySessionBackend struct{}
+
+	fileSessionBackend struct {
+		fileName string
+	}
+
+	redisSessionBackend struct {
+		host            string
+		password        string
+		idleConnections int
+		maxAge          int
+		mutex           sync.Mutex
+		pool            *redis.Pool
+	}
+)
+
+// Store in memory
+func (*memorySessionBackend) Store(options SessionOptions) (sessions.Store, error) {
+	sessionStore := memorystore.NewMemoryStore(options.Secret)
+
+	sessionStore.MaxLength(options.StoreLength)
+	sessionStore.MaxAge(options.MaxAge)
+	sessionStore.Options.Secure = options.Secure
+	sessionStore.Options.HttpOnly = true
+	sessionStore.Options.Path = options.Path
+
+	return sessionStore, nil
+}
+
+// Inject dependencies
+func (b *fileSessionBackend) Inject(config *struct {
+	FileName string `inject:"config:session.file"`
+}) {
+	b.fileName = config.FileName
+}
+
+// Store in the session directory
+func (b *fileSessionBackend) Store(options SessionOptions) (sessions.Store, error) {
+	if err := os.MkdirAll(b.fileName, os.ModePerm); err != nil {
+		return nil, err
+	}
+	sessionStore := sessions.NewFilesystemStore(b.fileName, options.Secret)
+	if options.Serializer != nil {
+		for _, codec := range sessionStore.Codecs {
+			if secureCookie, ok := codec.(*securecookie.SecureCookie); ok {
+				secureCookie.SetSerializer(options.Serializer)
+			}
+		}
+	}
+
+	sessionStore.MaxLength(options.StoreLength)
+	sessionStore.MaxAge(options.MaxAge)
+	sessionStore.Options.Secure = options.Secure
+	sessionStore.Options.HttpOnly = true
+	sessionStore.Options.Path = options.Path
+
+	return sessionStore, nil
+}
+
+// Status checks if the session directory is available
+func (b *fileSessionBackend) Status() (bool, string) {
+	if _, err := os.Stat(b.fileName); err != nil {
+		return false, err.Error()
+	}
+	return true, "success"
+}
+
+// Inject dependencies
+func (b *redisSessionBackend) Inject(config *struct {
+	Host     string `inject:"config:session.redis.host"`
+	Password string `inject:"config:session.redis.password"`
+	// float64 is used due to the injection as config from json - int is not possible on this
+	IdleConnections float64 `inject:"config:session.redis.idle.connections"`
+	MaxAge          float64 `inject:"config:session.redis.maxAge"`
+}) {
+	b.host = config.Host
+	b.password = config.Password
+	b.idleConnections = int(config.IdleConnections)
+	b.maxAge = int(config.MaxAge)
+}
+
+// Store in redis
+func (b *redisSessionBackend) Store(options SessionOptions) (sessions.Store, error) {
+	sessionStore, err := redistore.NewRediStore(b.idleConnections, "tcp", b.host, b.password, options.Secret)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionStore.SetMaxAge(options.MaxAge)
+	sessionStore.SetMaxLength(options.StoreLength)
+	sessionStore.Options.Secure = options.Secure
+	sessionStore.Options.HttpOnly = true
+	sessionStore.Options.Path = options.Path
+	sessionStore.DefaultMaxAge = b.maxAge
+	if options.Serializer != nil {
+		sessionStore.SetSerializer(redisSessionSerializer{serializer: options.Serializer})
+	}
+
+	b.mutex.Lock()
+	b.pool = sessionStore.Pool
+	b.mutex.Unlock()
+
+	return sessionStore, nil
+}
+
+// Status checks if the redis server is available
+func (b *redisSessionBackend) Status() (bool, string) {
+	b.mutex.Lock()
+	pool := b.pool
+	b.mutex.Unlock()
+
+	if pool == nil {
+		return false, "redis session store not initialized"
+	}
+
+	conn := pool.Get()
+	defer conn.Close()
+
+	if _, err := conn.Do("PING"); err != nil {
+		return false, err.Error()
+	}
+	return true, "success"
+}
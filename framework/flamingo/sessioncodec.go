@@ -0,0 +1,188 @@
+package flamingo
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"flamingo.me/dingo"
+	"github.com/gorilla/sessions"
+	"github.com/pkg/errors"
+)
+
+type (
+	// SessionKey defines the type of a session value, so it can be decoded without guessing, e.g. by the json codec.
+	// The version has to be increased if the type changes in an incompatible way, stored values with another version are dropped.
+	SessionKey struct {
+		Key string
+		// Type is an instance of the stored type, e.g. new(oauth2.Token) if a pointer is stored
+		Type    interface{}
+		Version int
+	}
+
+	// SessionSerializer encodes the session values one by one with the configured codec,
+	// so values which can not be decoded anymore are dropped with a warning instead of losing the whole session.
+	// Values with other keys than strings are encoded together with gob, their types have to be registered with gob.Register.
+	// It implements the securecookie.Serializer interface.
+	SessionSerializer struct {
+		codec  string
+		codecs map[string]Codec
+		keys   map[string]SessionKey
+		logger Logger
+	}
+
+	sessionEnvelope struct {
+		Codec  string                  `json:"codec"`
+		Values map[string]sessionValue `json:"values"`
+		// Other contains the values with other keys than strings, encoded with gob
+		Other []byte `json:"other,omitempty"`
+	}
+
+	sessionValue struct {
+		Version int    `json:"version,omitempty"`
+		Data    []byte `json:"data"`
+	}
+
+	redisSessionSerializer struct {
+		serializer *SessionSerializer
+	}
+)
+
+// BindSessionKeys registers the types of session values
+func BindSessionKeys(injector *dingo.Injector, keys ...SessionKey) {
+	for _, key := range keys {
+		injector.BindMulti(new(SessionKey)).ToInstance(key)
+	}
+}
+
+// NewSessionSerializer creates a serializer which encodes with the codec, values stored by any of the codecs can be read
+func NewSessionSerializer(codec string, codecs map[string]Codec, keys []SessionKey, logger Logger) (*SessionSerializer, error) {
+	if _, ok := codecs[codec]; !ok {
+		return nil, errors.Errorf("session codec %q is not registered", codec)
+	}
+
+	serializer := &SessionSerializer{
+		codec:  codec,
+		codecs: codecs,
+		keys:   make(map[string]SessionKey, len(keys)),
+		logger: logger,
+	}
+	for _, key := range keys {
+		serializer.keys[key.Key] = key
+	}
+
+	return serializer, nil
+}
+
+// Serialize session values, other values such as the session id are encoded directly
+func (s *SessionSerializer) Serialize(src interface{}) ([]byte, error) {
+	values, ok := src.(map[interface{}]interface{})
+	if !ok {
+		return s.codecs[s.codec].Encode(src)
+	}
+
+	envelope := sessionEnvelope{Codec: s.codec, Values: make(map[string]sessionValue, len(values))}
+	other := make(map[interface{}]interface{})
+	for k, v := range values {
+		name, ok := k.(string)
+		if !ok {
+			other[k] = v
+			continue
+		}
+
+		key, typed := s.keys[name]
+		var value interface{} = v
+		if !typed {
+			// encode the interface, so the type is known when decoding
+			value = &v
+		}
+
+		data, err := s.codecs[s.codec].Encode(value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "session value %q can not be encoded", name)
+		}
+		envelope.Values[name] = sessionValue{Version: key.Version, Data: data}
+	}
+
+	if len(other) > 0 {
+		data, err := GobCodec{}.Encode(other)
+		if err != nil {
+			return nil, errors.Wrap(err, "session values with non string keys can not be encoded")
+		}
+		envelope.Other = data
+	}
+
+	return json.Marshal(envelope)
+}
+
+// Deserialize session values, values which can not be decoded or have been stored with another version are dropped
+func (s *SessionSerializer) Deserialize(src []byte, dst interface{}) error {
+	values, ok := dst.(*map[interface{}]interface{})
+	if !ok {
+		return s.codecs[s.codec].Decode(src, dst)
+	}
+
+	var envelope sessionEnvelope
+	if err := json.Unmarshal(src, &envelope); err != nil {
+		// sessions stored before the serializer was used are gob encoded as a whole
+		if gobErr := gob.NewDecoder(bytes.NewReader(src)).Decode(values); gobErr != nil {
+			return errors.Wrap(err, "session can not be decoded")
+		}
+		return nil
+	}
+
+	codec, ok := s.codecs[envelope.Codec]
+	if !ok {
+		return errors.Errorf("session codec %q is not registered", envelope.Codec)
+	}
+
+	if *values == nil {
+		*values = make(map[interface{}]interface{}, len(envelope.Values))
+	}
+	if len(envelope.Other) > 0 {
+		if err := (GobCodec{}).Decode(envelope.Other, values); err != nil {
+			s.logger.Warn(fmt.Sprintf("session values with non string keys are dropped: %v", err))
+		}
+	}
+	for name, value := range envelope.Values {
+		decoded, err := s.decode(codec, name, value)
+		if err != nil {
+			s.logger.Warn(fmt.Sprintf("session value %q is dropped: %v", name, err))
+			continue
+		}
+		(*values)[name] = decoded
+	}
+
+	return nil
+}
+
+func (s *SessionSerializer) decode(codec Codec, name string, value sessionValue) (interface{}, error) {
+	key, typed := s.keys[name]
+	if !typed {
+		var decoded interface{}
+		err := codec.Decode(value.Data, &decoded)
+		return decoded, err
+	}
+
+	if key.Version != value.Version {
+		return nil, errors.Errorf("stored with version %d, expected %d", value.Version, key.Version)
+	}
+
+	decoded := reflect.New(reflect.TypeOf(key.Type))
+	if err := codec.Decode(value.Data, decoded.Interface()); err != nil {
+		return nil, err
+	}
+	return decoded.Elem().Interface(), nil
+}
+
+// Serialize the session values for the redis store
+func (s redisSessionSerializer) Serialize(session *sessions.Session) ([]byte, error) {
+	return s.serializer.Serialize(session.Values)
+}
+
+// Deserialize the session values for the redis store
+func (s redisSessionSerializer) Deserialize(data []byte, session *sessions.Session) error {
+	return s.serializer.Deserialize(data, &session.Values)
+}
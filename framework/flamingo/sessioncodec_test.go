@@ -0,0 +1,112 @@
+package flamingo
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/securecookie"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+)
+
+var (
+	_ securecookie.Serializer = new(SessionSerializer)
+
+	testSessionCodecs = map[string]Codec{"gob": GobCodec{}, "json": JSONCodec{}}
+	testSessionKeys   = []SessionKey{
+		{Key: "token", Type: new(oauth2.Token), Version: 1},
+		{Key: "created", Type: int64(0), Version: 1},
+	}
+)
+
+func TestSessionSerializer(t *testing.T) {
+	token := &oauth2.Token{AccessToken: "access", Expiry: time.Unix(1500000000, 0).UTC()}
+
+	for _, codec := range []string{"gob", "json"} {
+		t.Run(codec, func(t *testing.T) {
+			serializer, err := NewSessionSerializer(codec, testSessionCodecs, testSessionKeys, new(NullLogger))
+			assert.NoError(t, err)
+
+			data, err := serializer.Serialize(map[interface{}]interface{}{"token": token, "created": int64(1500000000), "redirect": "/home", 1: "non string key"})
+			assert.NoError(t, err)
+
+			values := make(map[interface{}]interface{})
+			assert.NoError(t, serializer.Deserialize(data, &values))
+			assert.Equal(t, map[interface{}]interface{}{"token": token, "created": int64(1500000000), "redirect": "/home", 1: "non string key"}, values, "values with non string keys are kept")
+
+			data, err = serializer.Serialize("session id")
+			assert.NoError(t, err)
+			var id string
+			assert.NoError(t, serializer.Deserialize(data, &id))
+			assert.Equal(t, "session id", id)
+		})
+	}
+
+	t.Run("values are dropped one by one", func(t *testing.T) {
+		gobSerializer, _ := NewSessionSerializer("gob", testSessionCodecs, testSessionKeys, new(NullLogger))
+		data, err := gobSerializer.Serialize(map[interface{}]interface{}{"token": token, "created": int64(1500000000), "redirect": "/home"})
+		assert.NoError(t, err)
+
+		var envelope sessionEnvelope
+		assert.NoError(t, json.Unmarshal(data, &envelope))
+		envelope.Values["redirect"] = sessionValue{Data: []byte("broken")}
+		data, _ = json.Marshal(envelope)
+
+		changed, _ := NewSessionSerializer("json", testSessionCodecs, []SessionKey{
+			{Key: "token", Type: new(oauth2.Token), Version: 2},
+			{Key: "created", Type: int64(0), Version: 1},
+		}, new(NullLogger))
+
+		values := make(map[interface{}]interface{})
+		assert.NoError(t, changed.Deserialize(data, &values))
+		assert.Equal(t, map[interface{}]interface{}{"created": int64(1500000000)}, values, "values with another version or broken data are dropped, values of other codecs can be read")
+	})
+
+	t.Run("non string keys of unregistered types", func(t *testing.T) {
+		type unregistered struct{}
+
+		serializer, _ := NewSessionSerializer("json", testSessionCodecs, nil, new(NullLogger))
+		_, err := serializer.Serialize(map[interface{}]interface{}{unregistered{}: "value"})
+		assert.Error(t, err, "values are not dropped silently")
+	})
+
+	t.Run("sessions stored with gob", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		assert.NoError(t, gob.NewEncoder(buf).Encode(map[interface{}]interface{}{"redirect": "/home"}))
+
+		serializer, _ := NewSessionSerializer("json", testSessionCodecs, nil, new(NullLogger))
+		values := make(map[interface{}]interface{})
+		assert.NoError(t, serializer.Deserialize(buf.Bytes(), &values))
+		assert.Equal(t, "/home", values["redirect"])
+	})
+
+	t.Run("unknown codec", func(t *testing.T) {
+		_, err := NewSessionSerializer("xml", testSessionCodecs, nil, new(NullLogger))
+		assert.EqualError(t, err, `session codec "xml" is not registered`)
+	})
+}
+
+func TestCookieSessionStore_Serializer(t *testing.T) {
+	store, err := newCookieSessionStore([][]byte{[]byte("secret")}, 4)
+	assert.NoError(t, err)
+	store.serializer, _ = NewSessionSerializer("json", testSessionCodecs, testSessionKeys, new(NullLogger))
+
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	session, _ := store.New(request, "flamingo")
+	session.Values["created"] = int64(1500000000)
+	recorder := httptest.NewRecorder()
+	assert.NoError(t, store.Save(request, recorder, session))
+
+	request = httptest.NewRequest(http.MethodGet, "/", nil)
+	for _, cookie := range recorder.Result().Cookies() {
+		request.AddCookie(cookie)
+	}
+	loaded, err := store.New(request, "flamingo")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1500000000), loaded.Values["created"])
+}
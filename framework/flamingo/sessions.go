@@ -1,107 +1,129 @@
 package flamingo
 
 import (
-	"os"
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
 
 	"flamingo.me/dingo"
 	"flamingo.me/flamingo/v3/framework/config"
 	"github.com/boj/redistore"
 	"github.com/gomodule/redigo/redis"
 	"github.com/gorilla/sessions"
-	"github.com/zemirco/memorystore"
+	"github.com/pkg/errors"
 )
 
-// SessionModule for session management
-type SessionModule struct {
-	backend              string
-	secret               string
-	fileName             string
-	secure               bool
-	storeLength          int
-	maxAge               int
-	path                 string
-	redisHost            string
-	redisPassword        string
-	redisIdleConnections int
-	redisMaxAge          int
-}
+// defaultSessionSecret is the default of `session.secret`, it must be changed for backends which rely on the secret
+const defaultSessionSecret = "flamingosecret"
+
+type (
+	// SessionModule for session management
+	SessionModule struct {
+		backend  string
+		fallback string
+	}
+
+	// SessionBackend creates the session store.
+	// Backends are registered by their name, and configure themselves from their own config sub-tree:
+	// injector.BindMap(new(flamingo.SessionBackend), "sql").To(sqlSessionBackend{})
+	SessionBackend interface {
+		Store(options SessionOptions) (sessions.Store, error)
+	}
+
+	// SessionOptions are shared by all session backends
+	SessionOptions struct {
+		Secret      []byte
+		StoreLength int
+		MaxAge      int
+		Secure      bool
+		Path        string
+		// Serializer encodes the session values, backends which store them should use it
+		Serializer *SessionSerializer
+	}
+
+	// SessionStatus reports the health of the session backend, and if the fallback backend is used
+	SessionStatus struct {
+		mutex   sync.RWMutex
+		active  string
+		backend SessionBackend
+		err     error
+	}
+
+	// unavailableSessionStore is used if the session backend failed without a fallback, every session access fails with the error
+	unavailableSessionStore struct {
+		err error
+	}
+
+	sessionStoreProvider func() sessions.Store
+
+	// sessionLifecycleHook creates the session store on startup, and aborts the startup if the session backend failed without a fallback
+	sessionLifecycleHook struct {
+		storeProvider sessionStoreProvider
+	}
+
+	sessionStoreFactory struct {
+		status   *SessionStatus
+		backends map[string]SessionBackend
+		codecs   map[string]Codec
+		keys     []SessionKey
+		logger   Logger
+		backend  string
+		fallback string
+		codec    string
+		options  SessionOptions
+	}
+)
 
 // Inject dependencies
 func (m *SessionModule) Inject(config *struct {
 	// session config is optional to allow usage of the DefaultConfig
 	Backend  string `inject:"config:session.backend"`
-	Secret   string `inject:"config:session.secret"`
-	FileName string `inject:"config:session.file"`
-	Secure   bool   `inject:"config:session.cookie.secure"`
-	// float64 is used due to the injection as config from json - int is not possible on this
-	StoreLength          float64 `inject:"config:session.store.length"`
-	MaxAge               float64 `inject:"config:session.max.age"`
-	Path                 string  `inject:"config:session.cookie.path"`
-	RedisHost            string  `inject:"config:session.redis.host"`
-	RedisPassword        string  `inject:"config:session.redis.password"`
-	RedisIdleConnections float64 `inject:"config:session.redis.idle.connections"`
-	RedisMaxAge          float64 `inject:"config:session.redis.maxAge"`
+	Fallback string `inject:"config:session.fallback,optional"`
 }) {
 	m.backend = config.Backend
-	m.secret = config.Secret
-	m.fileName = config.FileName
-	m.secure = config.Secure
-	m.storeLength = int(config.StoreLength)
-	m.maxAge = int(config.MaxAge)
-	m.path = config.Path
-	m.redisHost = config.RedisHost
-	m.redisPassword = config.RedisPassword
-	m.redisIdleConnections = int(config.RedisIdleConnections)
-	m.maxAge = int(config.MaxAge)
+	m.fallback = config.Fallback
 }
 
 // Configure DI
 func (m *SessionModule) Configure(injector *dingo.Injector) {
-	switch m.backend {
-	case "redis":
-		sessionStore, err := redistore.NewRediStore(int(m.redisIdleConnections), "tcp", m.redisHost, m.redisPassword, []byte(m.secret))
+	injector.BindMap(new(SessionBackend), "memory").To(memorySessionBackend{})
+	injector.BindMap(new(SessionBackend), "file").To(fileSessionBackend{})
+	injector.BindMap(new(SessionBackend), "redis").To(redisSessionBackend{})
+	injector.BindMap(new(SessionBackend), "cookie").To(cookieSessionBackend{})
+
+	BindCodecs(injector)
+
+	injector.Bind(SessionStatus{}).In(dingo.ChildSingleton)
+	injector.Bind(new(sessions.Store)).In(dingo.ChildSingleton).ToProvider(func(factory *sessionStoreFactory) sessions.Store {
+		store, err := factory.store()
 		if err != nil {
-			panic(err) // todo: don't panic? fallback?
+			return &unavailableSessionStore{err: err}
 		}
+		return store
+	})
+
+	BindLifecycleHook(injector, "session").To(sessionLifecycleHook{})
 
-		sessionStore.SetMaxAge(m.maxAge)
-		sessionStore.SetMaxLength(m.storeLength)
-		sessionStore.Options.Secure = m.secure
-		sessionStore.Options.HttpOnly = true
-		sessionStore.Options.Path = m.path
-		sessionStore.DefaultMaxAge = m.redisMaxAge
-
-		injector.Bind(new(sessions.Store)).ToInstance(sessionStore)
-		injector.Bind(new(redis.Pool)).ToInstance(sessionStore.Pool)
-	case "file":
-		os.Mkdir(m.fileName, os.ModePerm)
-		sessionStore := sessions.NewFilesystemStore(m.fileName, []byte(m.secret))
-
-		sessionStore.MaxLength(m.storeLength)
-		sessionStore.MaxAge(m.maxAge)
-		sessionStore.Options.Secure = m.secure
-		sessionStore.Options.HttpOnly = true
-		sessionStore.Options.Path = m.path
-
-		injector.Bind(new(sessions.Store)).ToInstance(sessionStore)
-	default: //memory
-		sessionStore := memorystore.NewMemoryStore([]byte(m.secret))
-
-		sessionStore.MaxLength(m.storeLength)
-		sessionStore.MaxAge(m.maxAge)
-		sessionStore.Options.Secure = m.secure
-		sessionStore.Options.HttpOnly = true
-		sessionStore.Options.Path = m.path
-
-		injector.Bind(new(sessions.Store)).ToInstance(sessionStore)
+	// with a fallback the store might not use redis, so the pool is only bound without a fallback
+	if m.backend == "redis" && (m.fallback == "" || m.fallback == m.backend) {
+		injector.Bind(new(redis.Pool)).ToProvider(func(store sessions.Store) *redis.Pool {
+			if unavailable, ok := store.(*unavailableSessionStore); ok {
+				return &redis.Pool{Dial: func() (redis.Conn, error) { return nil, unavailable.err }}
+			}
+			return store.(*redistore.RediStore).Pool
+		})
 	}
 }
 
 // DefaultConfig for this module
 func (m *SessionModule) DefaultConfig() config.Map {
 	return config.Map{
+		"session.name":                   "flamingo",
 		"session.backend":                "memory",
-		"session.secret":                 "flamingosecret",
+		"session.fallback":               "",
+		"session.codec":                  "gob",
+		"session.secret":                 defaultSessionSecret,
 		"session.file":                   "/sessions",
 		"session.store.length":           1024 * 1024,
 		"session.max.age":                60 * 60 * 24 * 30,
@@ -111,5 +133,169 @@ func (m *SessionModule) DefaultConfig() config.Map {
 		"session.redis.password":         "",
 		"session.redis.idle.connections": 10,
 		"session.redis.maxAge":           60 * 60 * 24 * 30,
+		"session.cookiestore.secrets":    config.Slice{},
+		"session.cookiestore.maxChunks":  4,
+	}
+}
+
+// Inject dependencies
+func (f *sessionStoreFactory) Inject(
+	status *SessionStatus,
+	backends map[string]SessionBackend,
+	codecs map[string]Codec,
+	logger Logger,
+	config *struct {
+		Backend  string `inject:"config:session.backend"`
+		Fallback string `inject:"config:session.fallback,optional"`
+		Codec    string `inject:"config:session.codec,optional"`
+		Secret   string `inject:"config:session.secret"`
+		Secure   bool   `inject:"config:session.cookie.secure"`
+		// float64 is used due to the injection as config from json - int is not possible on this
+		StoreLength float64      `inject:"config:session.store.length"`
+		MaxAge      float64      `inject:"config:session.max.age"`
+		Path        string       `inject:"config:session.cookie.path"`
+		Keys        []SessionKey `inject:",optional"`
+	},
+) {
+	f.status = status
+	f.backends = backends
+	f.codecs = codecs
+	f.keys = config.Keys
+	f.logger = logger.WithField(LogKeyModule, "session")
+	f.backend = config.Backend
+	if f.backend == "" {
+		f.backend = "memory"
+	}
+	f.fallback = config.Fallback
+	f.codec = config.Codec
+	if f.codec == "" {
+		f.codec = "gob"
+	}
+	f.options = SessionOptions{
+		Secret:      []byte(config.Secret),
+		StoreLength: int(config.StoreLength),
+		MaxAge:      int(config.MaxAge),
+		Secure:      config.Secure,
+		Path:        config.Path,
+	}
+}
+
+// store creates the session store of the configured backend, and uses the fallback backend if it fails
+func (f *sessionStoreFactory) store() (sessions.Store, error) {
+	serializer, err := NewSessionSerializer(f.codec, f.codecs, f.keys, f.logger)
+	if err != nil {
+		f.status.set("", nil, err)
+		return nil, err
+	}
+	f.options.Serializer = serializer
+
+	store, err := f.create(f.backend)
+	if err == nil {
+		f.status.set(f.backend, f.backends[f.backend], nil)
+		return store, nil
+	}
+
+	if f.fallback == "" || f.fallback == f.backend {
+		f.status.set("", nil, err)
+		return nil, err
+	}
+
+	f.logger.Warn(fmt.Sprintf("%v, falling back to session backend %q", err, f.fallback))
+
+	store, fallbackErr := f.create(f.fallback)
+	if fallbackErr != nil {
+		f.status.set("", nil, err)
+		return nil, errors.Wrapf(fallbackErr, "fallback after %v", err)
 	}
+
+	f.status.set(f.fallback, f.backends[f.fallback], err)
+	return store, nil
+}
+
+func (f *sessionStoreFactory) create(name string) (sessions.Store, error) {
+	backend, ok := f.backends[name]
+	if !ok {
+		return nil, errors.Errorf("session backend %q is not registered", name)
+	}
+
+	store, err := backend.Store(f.options)
+	if err != nil {
+		return nil, errors.Wrapf(err, "session backend %q", name)
+	}
+	return store, nil
+}
+
+// Inject dependencies
+func (h *sessionLifecycleHook) Inject(storeProvider sessionStoreProvider) *sessionLifecycleHook {
+	h.storeProvider = storeProvider
+	return h
+}
+
+// Start fails if the session backend failed without a fallback
+func (h *sessionLifecycleHook) Start(context.Context) error {
+	if unavailable, ok := h.storeProvider().(*unavailableSessionStore); ok {
+		return errors.Wrap(unavailable.err, "sessions are not available, check session.backend and session.fallback")
+	}
+	return nil
+}
+
+// Stop does nothing, the session store has nothing to release
+func (h *sessionLifecycleHook) Stop(context.Context) error {
+	return nil
+}
+
+// Get returns a new session and the error of the session backend
+func (s *unavailableSessionStore) Get(_ *http.Request, name string) (*sessions.Session, error) {
+	return sessions.NewSession(s, name), s.err
+}
+
+// New returns a new session and the error of the session backend
+func (s *unavailableSessionStore) New(_ *http.Request, name string) (*sessions.Session, error) {
+	return sessions.NewSession(s, name), s.err
+}
+
+// Save fails with the error of the session backend
+func (s *unavailableSessionStore) Save(*http.Request, http.ResponseWriter, *sessions.Session) error {
+	return s.err
+}
+
+func (s *SessionStatus) set(active string, backend SessionBackend, err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.active = active
+	s.backend = backend
+	s.err = err
+}
+
+// Backend returns the name of the active session backend, which is the fallback if the configured backend failed
+func (s *SessionStatus) Backend() string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.active
+}
+
+// Status of the session backend, a fallback is reported as not alive.
+// Backends can provide their own status with a `Status() (alive bool, details string)` method.
+func (s *SessionStatus) Status() (bool, string) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if s.err != nil {
+		if s.active == "" {
+			return false, s.err.Error()
+		}
+		return false, fmt.Sprintf("using fallback session backend %q: %v", s.active, s.err)
+	}
+
+	if s.backend == nil {
+		return false, "session store not initialized"
+	}
+
+	if status, ok := s.backend.(interface{ Status() (bool, string) }); ok {
+		return status.Status()
+	}
+
+	return true, "success"
 }
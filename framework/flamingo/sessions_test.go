@@ -0,0 +1,168 @@
+package flamingo
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"flamingo.me/dingo"
+	"flamingo.me/flamingo/v3/framework/config"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gomodule/redigo/redis"
+	"github.com/gorilla/sessions"
+	"github.com/stretchr/testify/assert"
+	"github.com/zemirco/memorystore"
+)
+
+type failingSessionBackend struct{}
+
+func (failingSessionBackend) Store(SessionOptions) (sessions.Store, error) {
+	return nil, errors.New("connection refused")
+}
+
+type testSessionModule struct{}
+
+func (*testSessionModule) Configure(injector *dingo.Injector) {
+	injector.Bind(new(Logger)).To(NullLogger{})
+	injector.BindMap(new(SessionBackend), "failing").To(failingSessionBackend{})
+}
+
+func newTestSessionInjector(backend, fallback string, configs ...config.Map) *dingo.Injector {
+	cfg := make(config.Map)
+	configs = append([]config.Map{
+		new(SessionModule).DefaultConfig(),
+		{"session.backend": backend, "session.fallback": fallback},
+	}, configs...)
+	for _, c := range configs {
+		if err := cfg.Add(c); err != nil {
+			panic(err)
+		}
+	}
+
+	return dingo.NewInjector(&config.Module{Map: cfg}, new(SessionModule), new(testSessionModule))
+}
+
+func newTestSessionStoreFactory(backend, fallback string) *sessionStoreFactory {
+	return newTestSessionInjector(backend, fallback).GetInstance(sessionStoreFactory{}).(*sessionStoreFactory)
+}
+
+func TestSessionStoreFactory(t *testing.T) {
+	t.Run("configured backend", func(t *testing.T) {
+		factory := newTestSessionStoreFactory("memory", "")
+		alive, _ := factory.status.Status()
+		assert.False(t, alive, "not initialized")
+
+		store, err := factory.store()
+		assert.NoError(t, err)
+		assert.NotNil(t, store)
+		assert.Equal(t, "memory", factory.status.Backend())
+
+		alive, details := factory.status.Status()
+		assert.True(t, alive)
+		assert.Equal(t, "success", details)
+	})
+
+	t.Run("empty backend is memory", func(t *testing.T) {
+		_, err := newTestSessionStoreFactory("", "").store()
+		assert.NoError(t, err)
+	})
+
+	t.Run("fallback", func(t *testing.T) {
+		factory := newTestSessionStoreFactory("failing", "memory")
+
+		store, err := factory.store()
+		assert.NoError(t, err)
+		assert.NotNil(t, store)
+		assert.Equal(t, "memory", factory.status.Backend())
+
+		alive, details := factory.status.Status()
+		assert.False(t, alive)
+		assert.Equal(t, `using fallback session backend "memory": session backend "failing": connection refused`, details)
+	})
+
+	t.Run("no fallback", func(t *testing.T) {
+		factory := newTestSessionStoreFactory("failing", "")
+
+		_, err := factory.store()
+		assert.EqualError(t, err, `session backend "failing": connection refused`)
+
+		alive, details := factory.status.Status()
+		assert.False(t, alive)
+		assert.Equal(t, err.Error(), details)
+	})
+
+	t.Run("unknown backend and fallback", func(t *testing.T) {
+		_, err := newTestSessionStoreFactory("sql", "memcached").store()
+		assert.EqualError(t, err, `fallback after session backend "sql" is not registered: session backend "memcached" is not registered`)
+	})
+}
+
+func TestSessionModule_RedisPool(t *testing.T) {
+	server, err := miniredis.Run()
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer server.Close()
+
+	injector := newTestSessionInjector("redis", "", config.Map{"session.redis.host": server.Addr()})
+	pool := injector.GetInstance(new(redis.Pool)).(*redis.Pool)
+	conn := pool.Get()
+	defer conn.Close()
+	_, err = conn.Do("PING")
+	assert.NoError(t, err, "the pool of the redis store is bound")
+
+	// with a fallback the store might not use redis, so there is no pool to bind
+	injector = newTestSessionInjector("redis", "memory", config.Map{"session.redis.host": "127.0.0.1:1"})
+	assert.IsType(t, new(memorystore.MemoryStore), injector.GetInstance(new(sessions.Store)))
+
+	// without redis the pool fails with the error of the session backend
+	injector = newTestSessionInjector("redis", "", config.Map{"session.redis.host": "127.0.0.1:1"})
+	conn = injector.GetInstance(new(redis.Pool)).(*redis.Pool).Get()
+	defer conn.Close()
+	assert.Error(t, conn.Err())
+}
+
+func TestSessionModule_UnavailableStore(t *testing.T) {
+	injector := newTestSessionInjector("failing", "")
+
+	var store sessions.Store
+	assert.NotPanics(t, func() { store = injector.GetInstance(new(sessions.Store)).(sessions.Store) })
+
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	session, err := store.Get(request, "flamingo")
+	assert.EqualError(t, err, `session backend "failing": connection refused`)
+	assert.NotNil(t, session, "requests get an empty session")
+	assert.EqualError(t, store.Save(request, httptest.NewRecorder(), session), `session backend "failing": connection refused`)
+
+	alive, details := injector.GetInstance(SessionStatus{}).(*SessionStatus).Status()
+	assert.False(t, alive)
+	assert.Equal(t, `session backend "failing": connection refused`, details)
+
+	lifecycle := injector.GetInstance(Lifecycle{}).(*Lifecycle)
+	assert.EqualError(t, lifecycle.Start(context.Background()), `lifecycle hook "session" failed to start: sessions are not available, check session.backend and session.fallback: session backend "failing": connection refused`)
+
+	lifecycle = newTestSessionInjector("memory", "").GetInstance(Lifecycle{}).(*Lifecycle)
+	assert.NoError(t, lifecycle.Start(context.Background()))
+}
+
+func TestFileSessionBackend(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sessions")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	backend := &fileSessionBackend{fileName: dir + "/store"}
+	alive, _ := backend.Status()
+	assert.False(t, alive)
+
+	store, err := backend.Store(SessionOptions{Secret: []byte("secret"), MaxAge: 3600, Path: "/"})
+	assert.NoError(t, err)
+	assert.IsType(t, new(sessions.FilesystemStore), store)
+
+	alive, details := backend.Status()
+	assert.True(t, alive)
+	assert.Equal(t, "success", details)
+}
@@ -39,10 +39,20 @@ func (initmodule *InitModule) Configure(injector *dingo.Injector) {
 	injector.BindMulti(new(cobra.Command)).ToProvider(web.RoutesCmd)
 	injector.BindMulti(new(cobra.Command)).ToProvider(web.HandlerCmd)
 	injector.BindMulti(new(cobra.Command)).ToProvider(config.Cmd)
+	injector.BindMulti(new(cobra.Command)).ToProvider(modulesCmd)
 
 	web.BindRoutes(injector, new(routes))
 
 	injector.Bind(new(flamingo.EventRouter)).To(flamingo.DefaultEventRouter{})
+	injector.Bind(flamingo.DefaultEventRouter{}).In(dingo.ChildSingleton)
+
+	injector.Bind(new(configRefresher)).In(dingo.Singleton)
+	flamingo.BindEventSubscriber(injector).To(new(configRefresher))
+
+	flamingo.BindSessionKeys(injector, web.SessionKeys...)
+
+	injector.Bind(flamingo.Lifecycle{}).In(dingo.Singleton)
+	flamingo.BindEventSubscriber(injector).To(flamingo.Lifecycle{})
 
 	injector.Bind(web.Router{}).In(dingo.ChildSingleton)
 	injector.Bind(new(web.ReverseRouter)).To(web.Router{})
@@ -85,14 +95,20 @@ func (r *routes) Routes(registry *web.RouterRegistry) {
 // DefaultConfig for this module
 func (initmodule *InitModule) DefaultConfig() config.Map {
 	return config.Map{
-		"debug.mode":                    true,
-		"flamingo.router.notfound":      web.FlamingoNotfound,
-		"flamingo.router.error":         web.FlamingoError,
-		"flamingo.router.timeout":       float64(60000),
-		"flamingo.template.err403":      "error/403",
-		"flamingo.template.err404":      "error/404",
-		"flamingo.template.errWithCode": "error/withCode",
-		"flamingo.template.err503":      "error/503",
-		"session.name":                  "flamingo",
+		"debug.mode":                     true,
+		"flamingo.router.notfound":       web.FlamingoNotfound,
+		"flamingo.router.error":          web.FlamingoError,
+		"flamingo.router.timeout":        float64(60000),
+		"flamingo.config.refresh":        float64(60000),
+		"flamingo.eventrouter.workers":   float64(4),
+		"flamingo.eventrouter.queueSize": float64(100),
+		"flamingo.lifecycle.timeout":     float64(30000),
+		"flamingo.template.err403":       "error/403",
+		"flamingo.template.err404":       "error/404",
+		"flamingo.template.errWithCode":  "error/withCode",
+		"flamingo.template.err503":       "error/503",
+		"session.name":                   "flamingo",
+		"session.timeout.idle":           float64(0),
+		"session.timeout.absolute":       float64(0),
 	}
 }
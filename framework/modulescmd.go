@@ -0,0 +1,417 @@
+package framework
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+
+	"flamingo.me/dingo"
+	"flamingo.me/flamingo/v3/framework/config"
+	"flamingo.me/flamingo/v3/framework/flamingo"
+	"flamingo.me/flamingo/v3/framework/web"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type (
+	// moduleInfo describes a module of an area, its dependencies and what it contributed
+	moduleInfo struct {
+		Name          string              `json:"name"`
+		Disabled      bool                `json:"disabled,omitempty"`
+		Depends       []*moduleInfo       `json:"depends,omitempty"`
+		Contributions map[string][]string `json:"contributions,omitempty"`
+
+		pkg string
+	}
+
+	// areaInfo describes the modules of an area
+	areaInfo struct {
+		Name         string              `json:"name"`
+		Path         string              `json:"path"`
+		Modules      []*moduleInfo       `json:"modules"`
+		Unattributed map[string][]string `json:"unattributed,omitempty"`
+	}
+
+	// contribution is something a module added to an area, e.g. a filter or a template function
+	contribution struct {
+		kind string
+		name string
+		pkg  string
+	}
+
+	// contributionSource lists the contributions of an initialized area
+	contributionSource func(area *config.Area) []contribution
+
+	filterProvider        func() []web.Filter
+	routeFilterProvider   func() map[string]web.Filter
+	routesProvider        func() []web.RoutesModule
+	templateFuncProvider  func() map[string]flamingo.TemplateFunc
+	commandProvider       func() []*cobra.Command
+	lifecycleHookProvider func() map[string]flamingo.LifecycleHook
+	dependingModule       interface{ Depends() []dingo.Module }
+)
+
+// modulesCmd prints the modules of all areas, their dependencies, and what they contributed
+func modulesCmd(area *config.Area) *cobra.Command {
+	return newModulesCmd(area, injectorContributions)
+}
+
+func newModulesCmd(area *config.Area, source contributionSource) *cobra.Command {
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "modules [area]",
+		Short: "Dump the modules of the areas, their dependencies and contributions",
+		Long: `Dump the modules of the areas, the modules they depend on, the modules disabled by flamingo.modules.disabled,
+and the filters, routes, handlers, template functions, commands, lifecycle hooks and event subscribers each module contributed.
+Contributions are attributed to the module with the longest matching package path.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			filter := ""
+			if len(args) > 0 {
+				filter = args[0]
+			}
+
+			areas := collectAreas(area, filter, source)
+			if filter != "" && len(areas) == 0 {
+				return errors.Errorf("area %q not found", filter)
+			}
+
+			switch format {
+			case "text":
+				return printModules(cmd.OutOrStdout(), areas)
+			case "json":
+				encoder := json.NewEncoder(cmd.OutOrStdout())
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(areas)
+			case "dot":
+				return printModulesDot(cmd.OutOrStdout(), areas)
+			}
+			return errors.Errorf("unknown format %q, use text, json or dot", format)
+		},
+	}
+
+	cmd.Flags().StringVarP(&format, "format", "f", "text", "output format: text, json or dot")
+
+	return cmd
+}
+
+// collectAreas walks the area tree and describes every area, or only the area matching the filter by name or path
+func collectAreas(root *config.Area, filter string, source contributionSource) []*areaInfo {
+	var areas []*areaInfo
+
+	var walk func(area *config.Area, path string, inherited map[string]*moduleInfo, unattributed map[contribution]bool)
+	walk = func(area *config.Area, path string, inherited map[string]*moduleInfo, unattributed map[contribution]bool) {
+		info, packages := describeArea(area, path)
+
+		// modules of the parent contribute to the child areas as well, so their contributions are not repeated
+		childInherited := make(map[string]*moduleInfo, len(inherited)+len(packages))
+		for pkg, module := range inherited {
+			childInherited[pkg] = module
+		}
+		for pkg, module := range packages {
+			childInherited[pkg] = module
+		}
+		childUnattributed := make(map[contribution]bool, len(unattributed))
+		for c := range unattributed {
+			childUnattributed[c] = true
+		}
+
+		if area.Injector != nil {
+			for _, c := range attribute(info, packages, inherited, unattributed, source(area)) {
+				childUnattributed[c] = true
+			}
+		}
+
+		if filter == "" || filter == area.Name || filter == path {
+			areas = append(areas, info)
+		}
+
+		for _, child := range area.Childs {
+			walk(child, path+"/"+child.Name, childInherited, childUnattributed)
+		}
+	}
+	walk(root, root.Name, nil, nil)
+
+	return areas
+}
+
+// describeArea returns the module tree of the area, and the enabled modules by their package path
+func describeArea(area *config.Area, path string) (*areaInfo, map[string]*moduleInfo) {
+	info := &areaInfo{Name: area.Name, Path: path}
+	packages := make(map[string]*moduleInfo)
+
+	disabled := make(map[string]bool)
+	for _, module := range area.DisabledModules() {
+		disabled[config.ModuleName(module)] = true
+	}
+
+	var describe func(module dingo.Module, parents map[string]bool) *moduleInfo
+	describe = func(module dingo.Module, parents map[string]bool) *moduleInfo {
+		name := config.ModuleName(module)
+		tm := reflect.TypeOf(module)
+		if tm.Kind() == reflect.Ptr {
+			tm = tm.Elem()
+		}
+
+		m := &moduleInfo{Name: name, Disabled: disabled[name], pkg: tm.PkgPath()}
+		if m.Disabled || parents[name] {
+			return m
+		}
+		if _, ok := packages[m.pkg]; !ok {
+			packages[m.pkg] = m
+		}
+
+		if depending, ok := module.(dependingModule); ok {
+			parents[name] = true
+			for _, dependency := range depending.Depends() {
+				m.Depends = append(m.Depends, describe(dependency, parents))
+			}
+			delete(parents, name)
+		}
+
+		return m
+	}
+
+	for _, module := range area.Modules {
+		info.Modules = append(info.Modules, describe(module, make(map[string]bool)))
+	}
+
+	return info, packages
+}
+
+// attribute the contributions to the module with the longest matching package path and returns the unattributed ones,
+// contributions of modules of parent areas are skipped
+func attribute(info *areaInfo, packages, inherited map[string]*moduleInfo, inheritedUnattributed map[contribution]bool, contributions []contribution) []contribution {
+	var unattributed []contribution
+	for _, c := range contributions {
+		module := findModule(c.pkg, packages)
+		if parent := findModule(c.pkg, inherited); parent != nil && (module == nil || len(parent.pkg) > len(module.pkg)) {
+			continue
+		}
+
+		if module != nil {
+			if module.Contributions == nil {
+				module.Contributions = make(map[string][]string)
+			}
+			module.Contributions[c.kind] = append(module.Contributions[c.kind], c.name)
+			continue
+		}
+
+		unattributed = append(unattributed, c)
+		if inheritedUnattributed[c] {
+			continue
+		}
+		if info.Unattributed == nil {
+			info.Unattributed = make(map[string][]string)
+		}
+		info.Unattributed[c.kind] = append(info.Unattributed[c.kind], c.name)
+	}
+	return unattributed
+}
+
+// findModule returns the module with the longest package path which contains the package
+func findModule(pkg string, packages map[string]*moduleInfo) *moduleInfo {
+	var found *moduleInfo
+	for modulePkg, module := range packages {
+		if pkg != modulePkg && !strings.HasPrefix(pkg, modulePkg+"/") {
+			continue
+		}
+		if found == nil || len(modulePkg) > len(found.pkg) {
+			found = module
+		}
+	}
+	return found
+}
+
+// injectorContributions lists the contributions of the area's injector
+func injectorContributions(area *config.Area) []contribution {
+	injector := area.Injector
+	var contributions []contribution
+
+	if provider, ok := injector.GetInstance(new(filterProvider)).(filterProvider); ok {
+		for _, filter := range provider() {
+			contributions = append(contributions, typeContribution("filters", fmt.Sprintf("%T", filter), filter))
+		}
+	}
+
+	if provider, ok := injector.GetInstance(new(routeFilterProvider)).(routeFilterProvider); ok {
+		for name, filter := range provider() {
+			contributions = append(contributions, typeContribution("routeFilters", name, filter))
+		}
+	}
+
+	if provider, ok := injector.GetInstance(new(routesProvider)).(routesProvider); ok {
+		for _, routes := range provider() {
+			registry := web.NewRegistry()
+			routes.Routes(registry)
+
+			for _, handler := range registry.GetHandlerNames() {
+				contributions = append(contributions, typeContribution("handlers", handler, routes))
+			}
+			for _, route := range registry.GetRoutes() {
+				contributions = append(contributions, typeContribution("routes", route.GetPath()+" -> "+route.GetHandlerName(), routes))
+			}
+		}
+	}
+
+	if provider, ok := injector.GetInstance(new(templateFuncProvider)).(templateFuncProvider); ok {
+		for name, fnc := range provider() {
+			contributions = append(contributions, typeContribution("templateFuncs", name, fnc))
+		}
+	}
+
+	if provider, ok := injector.GetInstance(new(commandProvider)).(commandProvider); ok {
+		for _, command := range provider() {
+			contributions = append(contributions, contribution{kind: "commands", name: command.Name(), pkg: commandPackage(command)})
+		}
+	}
+
+	if provider, ok := injector.GetInstance(new(lifecycleHookProvider)).(lifecycleHookProvider); ok {
+		for name, hook := range provider() {
+			contributions = append(contributions, typeContribution("lifecycleHooks", name, hook))
+		}
+	}
+
+	if router, ok := injector.GetInstance(flamingo.DefaultEventRouter{}).(*flamingo.DefaultEventRouter); ok {
+		for _, subscriber := range router.Subscribers() {
+			contributions = append(contributions, typeContribution("eventSubscribers", fmt.Sprintf("%T", subscriber), subscriber))
+		}
+	}
+
+	sort.SliceStable(contributions, func(i, j int) bool {
+		if contributions[i].kind != contributions[j].kind {
+			return contributions[i].kind < contributions[j].kind
+		}
+		return contributions[i].name < contributions[j].name
+	})
+
+	return contributions
+}
+
+// typeContribution attributes the contribution by the package of the value's type
+func typeContribution(kind, name string, value interface{}) contribution {
+	t := reflect.TypeOf(value)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	c := contribution{kind: kind, name: name}
+	if t != nil {
+		c.pkg = t.PkgPath()
+		if c.pkg == "" && t.Kind() == reflect.Func {
+			c.pkg = funcPackage(reflect.ValueOf(value).Pointer())
+		}
+	}
+	return c
+}
+
+// commandPackage is the package of the function run by the command
+func commandPackage(command *cobra.Command) string {
+	for _, run := range []interface{}{command.RunE, command.Run} {
+		if v := reflect.ValueOf(run); !v.IsNil() {
+			return funcPackage(v.Pointer())
+		}
+	}
+	return ""
+}
+
+// funcPackage returns the package of a function, e.g. `flamingo.me/flamingo/v3/framework/web` for `flamingo.me/flamingo/v3/framework/web.RoutesCmd.func1`
+func funcPackage(pc uintptr) string {
+	fnc := runtime.FuncForPC(pc)
+	if fnc == nil {
+		return ""
+	}
+
+	name := fnc.Name()
+	slash := strings.LastIndex(name, "/") + 1
+	if dot := strings.Index(name[slash:], "."); dot >= 0 {
+		return name[:slash+dot]
+	}
+	return name
+}
+
+func printModules(w io.Writer, areas []*areaInfo) error {
+	var printModule func(module *moduleInfo, indent string)
+	printModule = func(module *moduleInfo, indent string) {
+		if module.Disabled {
+			fmt.Fprintf(w, "%s%s (disabled)\n", indent, module.Name)
+		} else {
+			fmt.Fprintf(w, "%s%s\n", indent, module.Name)
+		}
+		printContributions(w, module.Contributions, indent+"    ")
+		for _, dependency := range module.Depends {
+			printModule(dependency, indent+"  ")
+		}
+	}
+
+	for _, area := range areas {
+		fmt.Fprintf(w, "%s\n", area.Path)
+		for _, module := range area.Modules {
+			printModule(module, "  ")
+		}
+		if len(area.Unattributed) > 0 {
+			fmt.Fprintln(w, "  unattributed")
+			printContributions(w, area.Unattributed, "      ")
+		}
+	}
+	return nil
+}
+
+func printContributions(w io.Writer, contributions map[string][]string, indent string) {
+	kinds := make([]string, 0, len(contributions))
+	for kind := range contributions {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+
+	for _, kind := range kinds {
+		fmt.Fprintf(w, "%s%s: %s\n", indent, kind, strings.Join(contributions[kind], ", "))
+	}
+}
+
+func printModulesDot(w io.Writer, areas []*areaInfo) error {
+	fmt.Fprintln(w, "digraph modules {")
+	fmt.Fprintln(w, "  rankdir=LR;")
+	fmt.Fprintln(w, "  node [shape=box];")
+
+	for i, area := range areas {
+		nodes := make(map[string]bool)
+		var edges []string
+
+		var walk func(module *moduleInfo)
+		walk = func(module *moduleInfo) {
+			id := area.Path + "|" + module.Name
+			if !nodes[id] {
+				nodes[id] = true
+				style := ""
+				if module.Disabled {
+					style = ", style=dashed"
+				}
+				fmt.Fprintf(w, "    %q [label=%q%s];\n", id, module.Name, style)
+			}
+			for _, dependency := range module.Depends {
+				edges = append(edges, fmt.Sprintf("  %q -> %q;", id, area.Path+"|"+dependency.Name))
+				walk(dependency)
+			}
+		}
+
+		fmt.Fprintf(w, "  subgraph %q {\n", fmt.Sprintf("cluster_%d", i))
+		fmt.Fprintf(w, "    label=%q;\n", area.Path)
+		for _, module := range area.Modules {
+			walk(module)
+		}
+		fmt.Fprintln(w, "  }")
+
+		for _, edge := range edges {
+			fmt.Fprintln(w, edge)
+		}
+	}
+
+	fmt.Fprintln(w, "}")
+	return nil
+}
@@ -0,0 +1,125 @@
+package framework
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"flamingo.me/dingo"
+	"flamingo.me/flamingo/v3/framework/cmd"
+	"flamingo.me/flamingo/v3/framework/config"
+	"github.com/stretchr/testify/assert"
+)
+
+type (
+	dependingTestModule struct{}
+	cyclicTestModule    struct{}
+)
+
+func (*dependingTestModule) Configure(*dingo.Injector) {}
+
+func (*dependingTestModule) Depends() []dingo.Module {
+	return []dingo.Module{new(cmd.Module), new(cyclicTestModule)}
+}
+
+func (*cyclicTestModule) Configure(*dingo.Injector) {}
+
+func (*cyclicTestModule) Depends() []dingo.Module {
+	return []dingo.Module{new(dependingTestModule)}
+}
+
+func testAreas() *config.Area {
+	child := config.NewArea("de", []dingo.Module{new(config.Module), new(cmd.Module)})
+	child.Injector = new(dingo.Injector)
+	child.Configuration = config.Map{
+		"flamingo": config.Map{
+			"modules": config.Map{
+				"disabled": config.Slice{"flamingo.me/flamingo/v3/framework/cmd.Module"},
+			},
+		},
+	}
+
+	root := config.NewArea("root", []dingo.Module{new(dependingTestModule)}, child)
+	root.Injector = new(dingo.Injector)
+
+	return root
+}
+
+func testContributions(area *config.Area) []contribution {
+	return []contribution{
+		{kind: "filters", name: "web.filter", pkg: "flamingo.me/flamingo/v3/framework/web"},
+		{kind: "commands", name: "serve", pkg: "flamingo.me/flamingo/v3/framework/cmd"},
+		{kind: "templateFuncs", name: "config", pkg: "flamingo.me/flamingo/v3/framework/config"},
+		{kind: "filters", name: "other", pkg: "example.com/other"},
+	}
+}
+
+func TestCollectAreas(t *testing.T) {
+	areas := collectAreas(testAreas(), "", testContributions)
+	assert.Len(t, areas, 2)
+
+	root := areas[0]
+	assert.Equal(t, "root", root.Path)
+	assert.Len(t, root.Modules, 1)
+
+	depending := root.Modules[0]
+	assert.Equal(t, "flamingo.me/flamingo/v3/framework.dependingTestModule", depending.Name)
+	assert.Equal(t, "flamingo.me/flamingo/v3/framework/cmd.Module", depending.Depends[0].Name)
+	assert.Equal(t, "flamingo.me/flamingo/v3/framework.cyclicTestModule", depending.Depends[1].Name)
+	assert.Equal(t, depending.Name, depending.Depends[1].Depends[0].Name)
+	assert.Empty(t, depending.Depends[1].Depends[0].Depends, "cycles are not followed")
+
+	assert.Equal(t, map[string][]string{"filters": {"web.filter"}, "templateFuncs": {"config"}}, depending.Contributions, "longest package match")
+	assert.Equal(t, map[string][]string{"commands": {"serve"}}, depending.Depends[0].Contributions)
+	assert.Equal(t, map[string][]string{"filters": {"other"}}, root.Unattributed)
+
+	de := areas[1]
+	assert.Equal(t, "root/de", de.Path)
+	assert.False(t, de.Modules[0].Disabled)
+	assert.Equal(t, map[string][]string{"templateFuncs": {"config"}}, de.Modules[0].Contributions)
+	assert.True(t, de.Modules[1].Disabled)
+	assert.Empty(t, de.Modules[1].Contributions)
+	assert.Empty(t, de.Unattributed, "contributions of the parent area are skipped")
+
+	for _, filter := range []string{"de", "root/de"} {
+		areas = collectAreas(testAreas(), filter, testContributions)
+		assert.Len(t, areas, 1)
+		assert.Equal(t, "root/de", areas[0].Path)
+	}
+}
+
+func TestModulesCmd(t *testing.T) {
+	run := func(args ...string) (string, error) {
+		command := newModulesCmd(testAreas(), testContributions)
+		out := new(bytes.Buffer)
+		command.SetOutput(out)
+		command.SetArgs(args)
+		err := command.Execute()
+		return out.String(), err
+	}
+
+	out, err := run()
+	assert.NoError(t, err)
+	assert.Contains(t, out, "root/de\n")
+	assert.Contains(t, out, "  flamingo.me/flamingo/v3/framework/cmd.Module (disabled)\n")
+	assert.Contains(t, out, "      commands: serve\n")
+	assert.Contains(t, out, "  unattributed\n      filters: other\n")
+
+	out, err = run("--format", "dot")
+	assert.NoError(t, err)
+	assert.Contains(t, out, "digraph modules {")
+	assert.Contains(t, out, `"root|flamingo.me/flamingo/v3/framework.dependingTestModule" -> "root|flamingo.me/flamingo/v3/framework/cmd.Module";`)
+	assert.Contains(t, out, `"root/de|flamingo.me/flamingo/v3/framework/cmd.Module" [label="flamingo.me/flamingo/v3/framework/cmd.Module", style=dashed];`)
+
+	out, err = run("-f", "json")
+	assert.NoError(t, err)
+	var areas []areaInfo
+	assert.NoError(t, json.Unmarshal([]byte(out), &areas))
+	assert.Len(t, areas, 2)
+
+	_, err = run("fr")
+	assert.EqualError(t, err, `area "fr" not found`)
+
+	_, err = run("-f", "yaml")
+	assert.Error(t, err)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"flamingo.me/dingo"
@@ -20,6 +21,9 @@ type Module struct {
 	server                    *http.Server
 	logger                    flamingo.Logger
 	enableRootRedirectHandler bool
+
+	mutex    sync.Mutex
+	shutdown bool
 }
 
 // Configure DI
@@ -40,11 +44,11 @@ func (m *Module) Inject(l flamingo.Logger, config *struct {
 	m.enableRootRedirectHandler = config.EnableRootRedirectHandler
 }
 
-func serveCmd(m *Module) func(area *config.Area, defaultmux *http.ServeMux, configuredURLPrefixSampler *opencensus.ConfiguredURLPrefixSampler, config *struct {
+func serveCmd(m *Module) func(area *config.Area, defaultmux *http.ServeMux, eventRouter flamingo.EventRouter, configuredURLPrefixSampler *opencensus.ConfiguredURLPrefixSampler, config *struct {
 	PrimaryHandlers  []OptionalHandler `inject:"primaryHandlers,optional"` // Optional Register a PrimaryHandlersHandlers which is passed to the FrontendRouter
 	FallbackHandlers []OptionalHandler `inject:"fallback,optional"`        // Optional Register a FallbackHandlers which is passed to the FrontendRouter
 }) *cobra.Command {
-	return func(area *config.Area, defaultmux *http.ServeMux, configuredURLPrefixSampler *opencensus.ConfiguredURLPrefixSampler, config *struct {
+	return func(area *config.Area, defaultmux *http.ServeMux, eventRouter flamingo.EventRouter, configuredURLPrefixSampler *opencensus.ConfiguredURLPrefixSampler, config *struct {
 		PrimaryHandlers  []OptionalHandler `inject:"primaryHandlers,optional"` // Optional Register a PrimaryHandlersHandlers which is passed to the FrontendRouter
 		FallbackHandlers []OptionalHandler `inject:"fallback,optional"`        // Optional Register a FallbackHandlers which is passed to the FrontendRouter
 	}) *cobra.Command {
@@ -54,7 +58,7 @@ func serveCmd(m *Module) func(area *config.Area, defaultmux *http.ServeMux, conf
 			Use:     "serve",
 			Short:   "run the prefix router",
 			Aliases: []string{"server"},
-			Run:     m.serve(area, defaultmux, &addr, configuredURLPrefixSampler, config.PrimaryHandlers, config.FallbackHandlers),
+			Run:     m.serve(area, defaultmux, eventRouter, &addr, configuredURLPrefixSampler, config.PrimaryHandlers, config.FallbackHandlers),
 		}
 
 		cmd.Flags().StringVarP(&addr, "addr", "a", ":3210", "addr on which flamingo runs")
@@ -67,6 +71,7 @@ func serveCmd(m *Module) func(area *config.Area, defaultmux *http.ServeMux, conf
 func (m *Module) serve(
 	root *config.Area,
 	defaultRouter *http.ServeMux,
+	eventRouter flamingo.EventRouter,
 	addr *string,
 	configuredURLPrefixSampler *opencensus.ConfiguredURLPrefixSampler,
 	primaryHandlers []OptionalHandler,
@@ -130,7 +135,7 @@ func (m *Module) serve(
 		}
 
 		m.logger.WithField("category", "prefixrouter").Info("Starting HTTP Server (Prefixrouter) at ", *addr, ".....")
-		m.server = &http.Server{
+		server := &http.Server{
 			Addr: *addr,
 			Handler: &ochttp.Handler{
 				IsPublicEndpoint: true,
@@ -139,21 +144,42 @@ func (m *Module) serve(
 			},
 		}
 
-		e := m.server.ListenAndServe()
+		// the application might have been shut down already, e.g. by a signal during the setup
+		m.mutex.Lock()
+		if m.shutdown {
+			m.mutex.Unlock()
+			return
+		}
+		m.server = server
+		m.mutex.Unlock()
+
+		eventRouter.Dispatch(context.Background(), &flamingo.ServerStartEvent{})
+		defer eventRouter.Dispatch(context.Background(), &flamingo.ServerShutdownEvent{})
+
+		e := server.ListenAndServe()
 		if e != nil && e != http.ErrServerClosed {
 			m.logger.WithField("category", "prefixrouter").Error("Unexpected Error ", e)
 		}
 	}
 }
 
-// Notify handles the app shutdown event
+// Notify handles the app shutdown event, which is dispatched after the command or on SIGINT and SIGTERM
 func (m *Module) Notify(ctx context.Context, event flamingo.Event) {
-	if _, ok := event.(*flamingo.ServerShutdownEvent); ok {
+	if _, ok := event.(*flamingo.ShutdownEvent); ok {
+		m.mutex.Lock()
+		m.shutdown = true
+		server := m.server
+		m.mutex.Unlock()
+
+		if server == nil {
+			return
+		}
+
 		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 		defer cancel()
-		m.logger.WithField("category", "prefixrouter").Info("Shutdown server on ", m.server.Addr)
+		m.logger.WithField("category", "prefixrouter").Info("Shutdown server on ", server.Addr)
 
-		err := m.server.Shutdown(ctx)
+		err := server.Shutdown(ctx)
 		if err != nil {
 			m.logger.WithField("category", "prefixrouter").Error("unexpected error on server shutdown: ", err)
 		}
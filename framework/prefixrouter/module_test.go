@@ -1,20 +1,116 @@
 package prefixrouter_test
 
 import (
+	"context"
+	"net"
+	"os"
+	"reflect"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
 	"flamingo.me/dingo"
+	"flamingo.me/flamingo/v3/framework"
+	"flamingo.me/flamingo/v3/framework/cmd"
+	"flamingo.me/flamingo/v3/framework/config"
 	"flamingo.me/flamingo/v3/framework/flamingo"
 	"flamingo.me/flamingo/v3/framework/prefixrouter"
-	"testing"
+	"github.com/stretchr/testify/assert"
 )
 
-type testingNullLogger struct{}
+type (
+	testingNullLogger struct{}
+
+	eventRecorder struct {
+		mutex  sync.Mutex
+		events []flamingo.Event
+	}
+)
 
 func (m *testingNullLogger) Configure(injector *dingo.Injector) {
 	injector.Bind(new(flamingo.Logger)).To(flamingo.NullLogger{})
 }
 
+func (r *eventRecorder) Configure(injector *dingo.Injector) {
+	flamingo.BindEventSubscriber(injector).ToInstance(r)
+}
+
+func (r *eventRecorder) Notify(_ context.Context, event flamingo.Event) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *eventRecorder) has(event flamingo.Event) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	for _, e := range r.events {
+		if reflect.TypeOf(e) == reflect.TypeOf(event) {
+			return true
+		}
+	}
+	return false
+}
+
 func TestModule_Configure(t *testing.T) {
 	if err := dingo.TryModule(new(testingNullLogger), new(prefixrouter.Module)); err != nil {
 		t.Error(err)
 	}
 }
+
+func TestModule_ServeShutdownOnSignal(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		return
+	}
+	addr := listener.Addr().String()
+	assert.NoError(t, listener.Close())
+
+	events := new(eventRecorder)
+	root := config.NewArea("root", []dingo.Module{
+		new(framework.InitModule),
+		new(flamingo.SessionModule),
+		new(cmd.Module),
+		new(testingNullLogger),
+		events,
+		new(prefixrouter.Module),
+	})
+	root.LoadedConfig = make(config.Map)
+	assert.NoError(t, root.LoadedConfig.Add(config.Map{
+		"opencensus.tracing.sampler.whitelist":        config.Slice{},
+		"opencensus.tracing.sampler.blacklist":        config.Slice{},
+		"opencensus.tracing.sampler.allowParentTrace": true,
+	}))
+
+	injector, err := root.GetInitializedInjector()
+	if !assert.NoError(t, err) {
+		return
+	}
+	root.Injector = injector
+
+	done := make(chan error)
+	go func() {
+		done <- cmd.Run(injector, cmd.Args("serve", "--addr", addr))
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if conn, err := net.Dial("tcp", addr); err == nil {
+			conn.Close()
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	assert.True(t, events.has(new(flamingo.ServerStartEvent)), "server start event")
+
+	assert.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGTERM))
+
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("serve is not shut down on SIGTERM")
+	}
+	assert.True(t, events.has(new(flamingo.ServerShutdownEvent)), "server shutdown event")
+}
@@ -2,6 +2,7 @@ package application
 
 import (
 	"context"
+	"net"
 	"net/http"
 
 	"flamingo.me/flamingo/v3/framework/flamingo"
@@ -50,11 +51,18 @@ func (s *SystemServer) startup() {
 			serveMux.Handle(route, handler)
 		}
 	}
+
+	// listen synchronously, so a blocked address is reported right away instead of crashing the serving goroutine
+	listener, err := net.Listen("tcp", s.serviceAddress)
+	if err != nil {
+		s.logger.Error("systemendpoint: unable to listen at ", s.serviceAddress, ": ", err)
+		return
+	}
+
 	s.server = &http.Server{Addr: s.serviceAddress, Handler: serveMux}
 	go func() {
-		err := s.server.ListenAndServe()
-		if err != nil {
-			panic(err)
+		if err := s.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			s.logger.Error("systemendpoint: ", err)
 		}
 	}()
 }
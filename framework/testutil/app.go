@@ -0,0 +1,488 @@
+package testutil
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"flamingo.me/dingo"
+	"flamingo.me/flamingo/v3/framework"
+	"flamingo.me/flamingo/v3/framework/config"
+	"flamingo.me/flamingo/v3/framework/flamingo"
+	"flamingo.me/flamingo/v3/framework/web"
+	"github.com/gorilla/sessions"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type (
+	// App is a flamingo application running in-process, without a server
+	App struct {
+		t            testing.TB
+		injector     *dingo.Injector
+		area         *config.Area
+		handler      http.Handler
+		sessionStore sessions.Store
+		sessionName  string
+		eventRouter  flamingo.EventRouter
+		lifecycle    *flamingo.Lifecycle
+		events       *eventRecorder
+		jar          http.CookieJar
+		closeOnce    sync.Once
+	}
+
+	// AppOption configures the App
+	AppOption func(*appConfig)
+
+	appConfig struct {
+		overrides []dingo.Module
+	}
+
+	// overrideModule configures the injector after all other modules
+	overrideModule func(injector *dingo.Injector)
+
+	// testModule provides the defaults of the test application
+	testModule struct {
+		events *eventRecorder
+	}
+
+	eventRecorder struct {
+		mutex  sync.Mutex
+		events []flamingo.Event
+	}
+
+	// Request to the App, built fluently and executed by Expect
+	Request struct {
+		app     *App
+		request *http.Request
+		session map[interface{}]interface{}
+	}
+
+	// Response of the App, with fluent assertions
+	Response struct {
+		t    testing.TB
+		raw  *http.Response
+		body []byte
+	}
+)
+
+// BaseURL of the requests to the App. It uses https, so secure cookies are kept by the cookie jar.
+const BaseURL = "https://flamingo.test"
+
+// Override configures the injector after all modules, e.g. to swap bindings for fakes:
+//
+//	testutil.Override(func(injector *dingo.Injector) {
+//		injector.Override(new(domain.Service), "").To(fakeService{})
+//	})
+func Override(configure func(injector *dingo.Injector)) AppOption {
+	return func(cfg *appConfig) {
+		cfg.overrides = append(cfg.overrides, overrideModule(configure))
+	}
+}
+
+// NewApp builds the root area of the modules with the config, and creates the router's handler in-process.
+// The framework's InitModule and, if missing, the SessionModule are added to the modules, and a flamingo.NullLogger is bound.
+// The lifecycle hooks are started and the flamingo.StartupEvent is dispatched. Close shuts the App down,
+// it is called by t.Cleanup when the test is done.
+func NewApp(t testing.TB, modules []dingo.Module, cfg config.Map, options ...AppOption) *App {
+	appCfg := new(appConfig)
+	for _, option := range options {
+		option(appCfg)
+	}
+
+	events := new(eventRecorder)
+
+	all := []dingo.Module{new(framework.InitModule), &testModule{events: events}}
+	if !containsModule(modules, new(flamingo.SessionModule)) {
+		all = append(all, new(flamingo.SessionModule))
+	}
+	all = append(all, modules...)
+	all = append(all, appCfg.overrides...)
+
+	area := config.NewArea("root", all)
+	area.LoadedConfig = make(config.Map)
+	if err := area.LoadedConfig.Add(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	injector, err := area.GetInitializedInjector()
+	if err != nil {
+		t.Fatal(err)
+	}
+	area.Injector = injector
+
+	sessionName, _ := area.Configuration.Get("session.name")
+	sessionNameString, _ := sessionName.(string)
+
+	app := newApp(
+		t,
+		injector.GetInstance(web.Router{}).(*web.Router).Handler(),
+		injector.GetInstance(new(sessions.Store)).(sessions.Store),
+		sessionNameString,
+		events,
+	)
+	app.injector = injector
+	app.area = area
+	app.eventRouter = injector.GetInstance(new(flamingo.EventRouter)).(flamingo.EventRouter)
+	app.lifecycle = injector.GetInstance(flamingo.Lifecycle{}).(*flamingo.Lifecycle)
+
+	if err := app.lifecycle.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(app.Close)
+	app.eventRouter.Dispatch(context.Background(), &flamingo.StartupEvent{})
+
+	return app
+}
+
+func containsModule(modules []dingo.Module, module dingo.Module) bool {
+	for _, m := range modules {
+		if config.ModuleName(m) == config.ModuleName(module) {
+			return true
+		}
+	}
+	return false
+}
+
+func newApp(t testing.TB, handler http.Handler, sessionStore sessions.Store, sessionName string, events *eventRecorder) *App {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &App{
+		t:            t,
+		handler:      handler,
+		sessionStore: sessionStore,
+		sessionName:  sessionName,
+		events:       events,
+		jar:          jar,
+	}
+}
+
+// Configure the override
+func (o overrideModule) Configure(injector *dingo.Injector) {
+	o(injector)
+}
+
+// Configure the test defaults
+func (m *testModule) Configure(injector *dingo.Injector) {
+	injector.Bind(new(flamingo.Logger)).To(flamingo.NullLogger{})
+	flamingo.BindEventSubscriber(injector).ToInstance(m.events)
+}
+
+// Notify records the event
+func (r *eventRecorder) Notify(_ context.Context, event flamingo.Event) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.events = append(r.events, event)
+}
+
+// Injector of the root area
+func (a *App) Injector() *dingo.Injector {
+	return a.injector
+}
+
+// Area is the root area
+func (a *App) Area() *config.Area {
+	return a.area
+}
+
+// Handler of the router
+func (a *App) Handler() http.Handler {
+	return a.handler
+}
+
+// Cookies for the path, which are sent with the next request
+func (a *App) Cookies(path string) []*http.Cookie {
+	u, _ := url.Parse(BaseURL + path)
+	return a.jar.Cookies(u)
+}
+
+// Events returns the dispatched events, in the order of their dispatch
+func (a *App) Events() []flamingo.Event {
+	a.events.mutex.Lock()
+	defer a.events.mutex.Unlock()
+	return append([]flamingo.Event(nil), a.events.events...)
+}
+
+// EventsOf returns the dispatched events of the same type as the event
+func (a *App) EventsOf(event flamingo.Event) []flamingo.Event {
+	var events []flamingo.Event
+	for _, e := range a.Events() {
+		if reflect.TypeOf(e) == reflect.TypeOf(event) {
+			events = append(events, e)
+		}
+	}
+	return events
+}
+
+// AssertEvent asserts that an event of the same type as the event has been dispatched, and returns the last one
+func (a *App) AssertEvent(event flamingo.Event) flamingo.Event {
+	events := a.EventsOf(event)
+	if len(events) == 0 {
+		assert.Fail(a.t, fmt.Sprintf("no event of type %T dispatched", event))
+		return nil
+	}
+	return events[len(events)-1]
+}
+
+// ResetEvents forgets the dispatched events
+func (a *App) ResetEvents() {
+	a.events.mutex.Lock()
+	defer a.events.mutex.Unlock()
+	a.events.events = nil
+}
+
+// Close dispatches the flamingo.ShutdownEvent, which stops the lifecycle hooks
+func (a *App) Close() {
+	a.closeOnce.Do(func() {
+		if a.eventRouter != nil {
+			a.eventRouter.Dispatch(context.Background(), &flamingo.ShutdownEvent{})
+		}
+	})
+}
+
+// GET request
+func (a *App) GET(path string) *Request {
+	return a.Request(http.MethodGet, path)
+}
+
+// POST request
+func (a *App) POST(path string) *Request {
+	return a.Request(http.MethodPost, path)
+}
+
+// PUT request
+func (a *App) PUT(path string) *Request {
+	return a.Request(http.MethodPut, path)
+}
+
+// PATCH request
+func (a *App) PATCH(path string) *Request {
+	return a.Request(http.MethodPatch, path)
+}
+
+// DELETE request
+func (a *App) DELETE(path string) *Request {
+	return a.Request(http.MethodDelete, path)
+}
+
+// Request with the method to the path, relative to the BaseURL
+func (a *App) Request(method, path string) *Request {
+	return &Request{
+		app:     a,
+		request: httptest.NewRequest(method, BaseURL+path, nil),
+	}
+}
+
+// WithHeader sets a request header
+func (r *Request) WithHeader(key, value string) *Request {
+	r.request.Header.Set(key, value)
+	return r
+}
+
+// WithQuery adds a query parameter
+func (r *Request) WithQuery(key, value string) *Request {
+	query := r.request.URL.Query()
+	query.Add(key, value)
+	r.request.URL.RawQuery = query.Encode()
+	return r
+}
+
+// WithForm sends the values as url encoded form
+func (r *Request) WithForm(values url.Values) *Request {
+	r.withBody(strings.NewReader(values.Encode()), "application/x-www-form-urlencoded")
+	return r
+}
+
+// WithJSON sends the value as JSON
+func (r *Request) WithJSON(value interface{}) *Request {
+	body, err := json.Marshal(value)
+	if err != nil {
+		r.app.t.Fatal(err)
+	}
+	r.withBody(bytes.NewReader(body), "application/json")
+	return r
+}
+
+// WithBody sends the body
+func (r *Request) WithBody(body io.Reader, contentType string) *Request {
+	r.withBody(body, contentType)
+	return r
+}
+
+func (r *Request) withBody(body io.Reader, contentType string) {
+	r.request.Body = ioutil.NopCloser(body)
+	r.request.ContentLength = -1
+	r.request.Header.Set("Content-Type", contentType)
+}
+
+// WithSession stores the value in the session before the request, the session is kept by the cookie jar
+func (r *Request) WithSession(key, value interface{}) *Request {
+	if r.session == nil {
+		r.session = make(map[interface{}]interface{})
+	}
+	r.session[key] = value
+	return r
+}
+
+// Expect executes the request
+func (r *Request) Expect() *Response {
+	a := r.app
+
+	if len(r.session) > 0 {
+		if err := a.storeSession(r.session); err != nil {
+			a.t.Fatal(err)
+		}
+	}
+
+	for _, cookie := range a.jar.Cookies(r.request.URL) {
+		r.request.AddCookie(cookie)
+	}
+
+	recorder := httptest.NewRecorder()
+	a.handler.ServeHTTP(recorder, r.request)
+
+	raw := recorder.Result()
+	a.jar.SetCookies(r.request.URL, raw.Cookies())
+
+	body, err := ioutil.ReadAll(raw.Body)
+	if err != nil {
+		a.t.Fatal(err)
+	}
+
+	return &Response{t: a.t, raw: raw, body: body}
+}
+
+// storeSession stores the values in the session of the cookie jar
+func (a *App) storeSession(values map[interface{}]interface{}) error {
+	if a.sessionStore == nil {
+		return errors.New("no session store")
+	}
+
+	request := httptest.NewRequest(http.MethodGet, BaseURL+"/", nil)
+	for _, cookie := range a.jar.Cookies(request.URL) {
+		request.AddCookie(cookie)
+	}
+
+	session, err := a.sessionStore.Get(request, a.sessionName)
+	if err != nil {
+		if session, err = a.sessionStore.New(request, a.sessionName); err != nil {
+			return err
+		}
+	}
+
+	for k, v := range values {
+		session.Values[k] = v
+	}
+
+	recorder := httptest.NewRecorder()
+	if err := a.sessionStore.Save(request, recorder, session); err != nil {
+		return err
+	}
+	a.jar.SetCookies(request.URL, recorder.Result().Cookies())
+
+	return nil
+}
+
+// Raw returns the http.Response
+func (r *Response) Raw() *http.Response {
+	return r.raw
+}
+
+// Body returns the body
+func (r *Response) Body() string {
+	return string(r.body)
+}
+
+// Status asserts the status code
+func (r *Response) Status(status int) *Response {
+	assert.Equal(r.t, status, r.raw.StatusCode, "status")
+	return r
+}
+
+// Header asserts the value of a header
+func (r *Response) Header(key, value string) *Response {
+	assert.Equal(r.t, value, r.raw.Header.Get(key), "header %s", key)
+	return r
+}
+
+// Location asserts the location of a redirect
+func (r *Response) Location(location string) *Response {
+	return r.Header("Location", location)
+}
+
+// BodyContains asserts that the body contains the string
+func (r *Response) BodyContains(s string) *Response {
+	assert.Contains(r.t, string(r.body), s, "body")
+	return r
+}
+
+// JSON decodes the body into the value
+func (r *Response) JSON(value interface{}) *Response {
+	assert.NoError(r.t, json.Unmarshal(r.body, value), "JSON body")
+	return r
+}
+
+// JSONPath asserts the value at the path of the JSON body, e.g. `data.items[0].name` or `$.data.items.0.name`.
+// Numbers are compared by value, so `JSONPath("count", 1)` matches `{"count": 1}`.
+func (r *Response) JSONPath(path string, expected interface{}) *Response {
+	var data interface{}
+	if err := json.Unmarshal(r.body, &data); err != nil {
+		assert.Fail(r.t, "body is no JSON", err.Error())
+		return r
+	}
+
+	actual, err := jsonPath(data, path)
+	if err != nil {
+		assert.Fail(r.t, err.Error())
+		return r
+	}
+
+	assert.EqualValues(r.t, expected, actual, "JSON path %s", path)
+	return r
+}
+
+// jsonPath resolves a simple path of object keys and array indexes
+func jsonPath(data interface{}, path string) (interface{}, error) {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	path = strings.NewReplacer("[", ".", "]", "").Replace(path)
+	if path == "" {
+		return data, nil
+	}
+
+	current := data
+	for _, part := range strings.Split(path, ".") {
+		switch value := current.(type) {
+		case map[string]interface{}:
+			v, ok := value[part]
+			if !ok {
+				return nil, errors.Errorf("JSON path %s: key %q not found", path, part)
+			}
+			current = v
+		case []interface{}:
+			i, err := strconv.Atoi(part)
+			if err != nil || i < 0 || i >= len(value) {
+				return nil, errors.Errorf("JSON path %s: index %q not found", path, part)
+			}
+			current = value[i]
+		default:
+			return nil, errors.Errorf("JSON path %s: %q is no object or array", path, part)
+		}
+	}
+
+	return current, nil
+}
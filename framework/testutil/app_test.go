@@ -0,0 +1,137 @@
+package testutil
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"flamingo.me/dingo"
+	"flamingo.me/flamingo/v3/framework/config"
+	"flamingo.me/flamingo/v3/framework/flamingo"
+	"flamingo.me/flamingo/v3/framework/web"
+	"github.com/stretchr/testify/assert"
+	"github.com/zemirco/memorystore"
+)
+
+type sessionRoutes struct{}
+
+func (*sessionRoutes) Configure(injector *dingo.Injector) {
+	web.BindRoutes(injector, new(sessionRoutes))
+}
+
+func (*sessionRoutes) Routes(registry *web.RouterRegistry) {
+	registry.HandleGet("me", func(ctx context.Context, r *web.Request) web.Result {
+		user, _ := r.Session().Load("user")
+		return &web.DataResponse{Response: web.Response{Status: http.StatusOK, Header: make(http.Header)}, Data: map[string]interface{}{"user": user}}
+	})
+	_, _ = registry.Route("/me", "me")
+}
+
+func testApp(t *testing.T) *App {
+	store := memorystore.NewMemoryStore([]byte("secret"))
+	store.Options.Secure = true
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		session, _ := store.Get(r, "flamingo")
+		session.Values["user"] = r.FormValue("user")
+		_ = session.Save(r, w)
+		http.Redirect(w, r, "/me", http.StatusSeeOther)
+	})
+	mux.HandleFunc("/me", func(w http.ResponseWriter, r *http.Request) {
+		session, _ := store.Get(r, "flamingo")
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"user":   session.Values["user"],
+			"query":  r.URL.Query().Get("q"),
+			"header": r.Header.Get("X-Test"),
+			"items":  []interface{}{map[string]interface{}{"count": 1}},
+		})
+	})
+
+	return newApp(t, mux, store, "flamingo", new(eventRecorder))
+}
+
+func TestApp_Requests(t *testing.T) {
+	app := testApp(t)
+
+	app.GET("/me").WithQuery("q", "search").WithHeader("X-Test", "yes").Expect().
+		Status(http.StatusOK).
+		Header("Content-Type", "application/json").
+		JSONPath("user", nil).
+		JSONPath("query", "search").
+		JSONPath("$.header", "yes").
+		JSONPath("items[0].count", 1)
+
+	app.POST("/login").WithForm(url.Values{"user": {"flamingo"}}).Expect().
+		Status(http.StatusSeeOther).
+		Location("/me")
+
+	assert.Len(t, app.Cookies("/"), 1, "the session cookie is kept")
+
+	var me struct{ User string }
+	app.GET("/me").Expect().JSON(&me)
+	assert.Equal(t, "flamingo", me.User, "the session is sent with the next request")
+
+	app.GET("/me").WithSession("user", "stored").Expect().JSONPath("user", "stored")
+}
+
+func TestNewApp(t *testing.T) {
+	var app *App
+	t.Run("session name", func(t *testing.T) {
+		app = NewApp(t, []dingo.Module{new(sessionRoutes)}, config.Map{"session.name": "custom"})
+
+		app.GET("/me").WithSession("user", "stored").Expect().
+			Status(http.StatusOK).
+			JSONPath("user", "stored")
+
+		cookies := app.Cookies("/")
+		if assert.Len(t, cookies, 1) {
+			assert.Equal(t, "custom", cookies[0].Name)
+		}
+		assert.Empty(t, app.EventsOf(new(flamingo.ShutdownEvent)))
+	})
+
+	assert.Len(t, app.EventsOf(new(flamingo.ShutdownEvent)), 1, "the app is closed when the test is done")
+}
+
+func TestApp_Events(t *testing.T) {
+	app := testApp(t)
+
+	app.events.Notify(context.Background(), &flamingo.StartupEvent{})
+	app.events.Notify(context.Background(), &flamingo.ShutdownEvent{})
+	app.events.Notify(context.Background(), &flamingo.StartupEvent{})
+
+	assert.Len(t, app.Events(), 3)
+	assert.Len(t, app.EventsOf(new(flamingo.StartupEvent)), 2)
+	assert.IsType(t, new(flamingo.ShutdownEvent), app.AssertEvent(new(flamingo.ShutdownEvent)))
+
+	app.ResetEvents()
+	assert.Empty(t, app.Events())
+}
+
+func TestJSONPath(t *testing.T) {
+	var data interface{}
+	assert.NoError(t, json.Unmarshal([]byte(`{"a": {"b": [{"c": "d"}]}}`), &data))
+
+	for path, expected := range map[string]interface{}{
+		"a.b[0].c":   "d",
+		"$.a.b.0.c":  "d",
+		"a.b[0]":     map[string]interface{}{"c": "d"},
+		"$":          data,
+		"a.b[1]":     nil,
+		"a.x":        nil,
+		"a.b[0].c.d": nil,
+	} {
+		actual, err := jsonPath(data, path)
+		if expected == nil {
+			assert.Error(t, err, path)
+			continue
+		}
+		assert.NoError(t, err, path)
+		assert.Equal(t, fmt.Sprint(expected), fmt.Sprint(actual), path)
+	}
+}
@@ -0,0 +1,420 @@
+package testutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+type (
+	// PactMock is a consumer contract mock server, which does not need the pact daemon.
+	// It serves the registered interactions, and writes them to a pact file when it is closed.
+	PactMock struct {
+		// PactDir is the directory of the pact files, defaults to $PACT_DIR or `pacts`
+		PactDir string
+
+		t            testing.TB
+		consumer     string
+		provider     string
+		server       *httptest.Server
+		mutex        sync.Mutex
+		interactions []*PactInteraction
+		errors       []string
+	}
+
+	// PactInteraction is an expected request and its response
+	PactInteraction struct {
+		Description   string
+		ProviderState string
+		Request       PactRequest
+		Response      PactResponse
+
+		called int
+	}
+
+	// PactRequest expected by the mock. Headers are required, other headers of the request are ignored.
+	PactRequest struct {
+		Method  string
+		Path    string
+		Query   url.Values
+		Headers map[string]string
+		Body    interface{}
+	}
+
+	// PactResponse of the mock. The body can contain matchers, see Like, EachLike and Term.
+	PactResponse struct {
+		Status  int
+		Headers map[string]string
+		Body    interface{}
+	}
+
+	// pactMatcher is a flexible expectation of a response body value
+	pactMatcher struct {
+		match string
+		value interface{}
+		regex string
+		min   int
+	}
+
+	pactFile struct {
+		Consumer     pactParticipant        `json:"consumer"`
+		Provider     pactParticipant        `json:"provider"`
+		Interactions []pactInteraction      `json:"interactions"`
+		Metadata     map[string]interface{} `json:"metadata"`
+	}
+
+	pactParticipant struct {
+		Name string `json:"name"`
+	}
+
+	pactInteraction struct {
+		Description   string       `json:"description"`
+		ProviderState string       `json:"providerState,omitempty"`
+		Request       pactRequest  `json:"request"`
+		Response      pactResponse `json:"response"`
+	}
+
+	pactRequest struct {
+		Method  string            `json:"method"`
+		Path    string            `json:"path"`
+		Query   string            `json:"query,omitempty"`
+		Headers map[string]string `json:"headers,omitempty"`
+		Body    interface{}       `json:"body,omitempty"`
+	}
+
+	pactResponse struct {
+		Status        int                               `json:"status"`
+		Headers       map[string]string                 `json:"headers,omitempty"`
+		Body          interface{}                       `json:"body,omitempty"`
+		MatchingRules map[string]map[string]interface{} `json:"matchingRules,omitempty"`
+	}
+)
+
+// Like matches values of the same type as the example value
+func Like(example interface{}) interface{} {
+	return pactMatcher{match: "type", value: example}
+}
+
+// EachLike matches arrays with at least min elements, which are like the example
+func EachLike(example interface{}, min int) interface{} {
+	if min < 1 {
+		min = 1
+	}
+	return pactMatcher{match: "type", value: example, min: min}
+}
+
+// Term matches strings with the regular expression, the mock responds with the generated example
+func Term(generate, regex string) interface{} {
+	return pactMatcher{match: "regex", value: generate, regex: regex}
+}
+
+// NewPactMock starts a mock server for the contract between the consumer and the provider
+func NewPactMock(t testing.TB, consumer, provider string) *PactMock {
+	mock := &PactMock{
+		PactDir:  os.Getenv("PACT_DIR"),
+		t:        t,
+		consumer: consumer,
+		provider: provider,
+	}
+	if mock.PactDir == "" {
+		mock.PactDir = "pacts"
+	}
+	mock.server = httptest.NewServer(http.HandlerFunc(mock.serve))
+
+	return mock
+}
+
+// URL of the mock server
+func (m *PactMock) URL() string {
+	return m.server.URL
+}
+
+// AddInteraction registers an expected request and its response
+func (m *PactMock) AddInteraction(interaction *PactInteraction) *PactMock {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if interaction.Request.Method == "" {
+		interaction.Request.Method = http.MethodGet
+	}
+	if interaction.Response.Status == 0 {
+		interaction.Response.Status = http.StatusOK
+	}
+	m.interactions = append(m.interactions, interaction)
+
+	return m
+}
+
+// Close stops the mock server and fails the test for unexpected requests and interactions which have not been called.
+// If everything matched, the interactions are written to the pact file `<consumer>-<provider>.json` in the PactDir,
+// and published to the pact broker if $PACT_BROKER_HOST is set.
+func (m *PactMock) Close() {
+	m.server.Close()
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	failed := len(m.errors) > 0
+	for _, err := range m.errors {
+		m.t.Error(err)
+	}
+	for _, interaction := range m.interactions {
+		if interaction.called == 0 {
+			m.t.Errorf("pact interaction %q has not been called", interaction.Description)
+			failed = true
+		}
+	}
+	if failed {
+		return
+	}
+
+	file, err := m.writePact()
+	if err != nil {
+		m.t.Error(err)
+		return
+	}
+
+	if broker := os.Getenv("PACT_BROKER_HOST"); broker != "" {
+		if err := PublishPact(broker, file); err != nil {
+			m.t.Error(err)
+		}
+	}
+}
+
+// serve the response of the first matching interaction
+func (m *PactMock) serve(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, interaction := range m.interactions {
+		if !interaction.Request.matches(r, body) {
+			continue
+		}
+		interaction.called++
+
+		for k, v := range interaction.Response.Headers {
+			w.Header().Set(k, v)
+		}
+		response, _ := reifyPact(interaction.Response.Body, "$.body", nil)
+		if response != nil && w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", "application/json")
+		}
+		w.WriteHeader(interaction.Response.Status)
+		if response != nil {
+			_ = json.NewEncoder(w).Encode(response)
+		}
+		return
+	}
+
+	m.errors = append(m.errors, fmt.Sprintf("unexpected pact request %s %s", r.Method, r.URL.RequestURI()))
+	http.Error(w, "no matching pact interaction", http.StatusInternalServerError)
+}
+
+// matches the request: method, path, query, the headers of the interaction, and the JSON body
+func (p PactRequest) matches(r *http.Request, body []byte) bool {
+	if r.Method != p.Method || r.URL.Path != p.Path {
+		return false
+	}
+	if len(p.Query) > 0 || len(r.URL.Query()) > 0 {
+		if !reflect.DeepEqual(url.Values(r.URL.Query()), p.Query) {
+			return false
+		}
+	}
+	for k, v := range p.Headers {
+		if r.Header.Get(k) != v {
+			return false
+		}
+	}
+
+	if p.Body == nil {
+		return true
+	}
+
+	var expected, actual interface{}
+	encoded, _ := json.Marshal(p.Body)
+	if err := json.Unmarshal(encoded, &expected); err != nil {
+		return false
+	}
+	if err := json.Unmarshal(body, &actual); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(expected, actual)
+}
+
+// reifyPact replaces the matchers by their examples, and collects the matching rules by their path
+func reifyPact(value interface{}, path string, rules map[string]map[string]interface{}) (interface{}, map[string]map[string]interface{}) {
+	if rules == nil {
+		rules = make(map[string]map[string]interface{})
+	}
+
+	switch v := value.(type) {
+	case pactMatcher:
+		rule := map[string]interface{}{"match": v.match}
+		if v.regex != "" {
+			rule["regex"] = v.regex
+		}
+		if v.min > 0 {
+			rule["min"] = v.min
+			rules[path] = rule
+
+			example, _ := reifyPact(v.value, path+"[*]", rules)
+			examples := make([]interface{}, v.min)
+			for i := range examples {
+				examples[i] = example
+			}
+			return examples, rules
+		}
+		rules[path] = rule
+		example, _ := reifyPact(v.value, path, rules)
+		return example, rules
+
+	case map[string]interface{}:
+		reified := make(map[string]interface{}, len(v))
+		for k, value := range v {
+			reified[k], _ = reifyPact(value, path+"."+k, rules)
+		}
+		return reified, rules
+
+	case []interface{}:
+		reified := make([]interface{}, len(v))
+		for i, value := range v {
+			reified[i], _ = reifyPact(value, fmt.Sprintf("%s[%d]", path, i), rules)
+		}
+		return reified, rules
+	}
+
+	return value, rules
+}
+
+// writePact merges the interactions into the pact file, interactions with the same description are replaced
+func (m *PactMock) writePact() (string, error) {
+	file := filepath.Join(m.PactDir, fmt.Sprintf("%s-%s.json", strings.ToLower(m.consumer), strings.ToLower(m.provider)))
+
+	pact := pactFile{
+		Consumer: pactParticipant{Name: m.consumer},
+		Provider: pactParticipant{Name: m.provider},
+		Metadata: map[string]interface{}{"pactSpecification": map[string]string{"version": "2.0.0"}},
+	}
+	if existing, err := ioutil.ReadFile(file); err == nil {
+		if err := json.Unmarshal(existing, &pact); err != nil {
+			return "", errors.Wrapf(err, "pact file %s", file)
+		}
+	}
+
+	for _, interaction := range m.interactions {
+		body, rules := reifyPact(interaction.Response.Body, "$.body", nil)
+		if len(rules) == 0 {
+			rules = nil
+		}
+
+		written := pactInteraction{
+			Description:   interaction.Description,
+			ProviderState: interaction.ProviderState,
+			Request: pactRequest{
+				Method:  interaction.Request.Method,
+				Path:    interaction.Request.Path,
+				Query:   interaction.Request.Query.Encode(),
+				Headers: interaction.Request.Headers,
+				Body:    interaction.Request.Body,
+			},
+			Response: pactResponse{
+				Status:        interaction.Response.Status,
+				Headers:       interaction.Response.Headers,
+				Body:          body,
+				MatchingRules: rules,
+			},
+		}
+
+		replaced := false
+		for i, existing := range pact.Interactions {
+			if existing.Description == written.Description && existing.ProviderState == written.ProviderState {
+				pact.Interactions[i] = written
+				replaced = true
+			}
+		}
+		if !replaced {
+			pact.Interactions = append(pact.Interactions, written)
+		}
+	}
+
+	content, err := json.MarshalIndent(pact, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(m.PactDir, os.ModePerm); err != nil {
+		return "", err
+	}
+
+	return file, ioutil.WriteFile(file, content, 0644)
+}
+
+// PublishPact publishes a pact file to the pact broker, with the version of $PACT_VERSION and the tags of $PACT_TAGS.
+// $PACT_BROKER_USERNAME and $PACT_BROKER_PASSWORD are used for basic auth.
+func PublishPact(broker, file string) error {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	var pact pactFile
+	if err := json.Unmarshal(content, &pact); err != nil {
+		return errors.Wrapf(err, "pact file %s", file)
+	}
+
+	version := os.Getenv("PACT_VERSION")
+	broker = strings.TrimRight(broker, "/")
+
+	if err := brokerPut(fmt.Sprintf("%s/pacts/provider/%s/consumer/%s/version/%s", broker, url.PathEscape(pact.Provider.Name), url.PathEscape(pact.Consumer.Name), url.PathEscape(version)), content); err != nil {
+		return err
+	}
+
+	tags := []string{strings.ToLower(pact.Consumer.Name), strings.ToLower(pact.Provider.Name)}
+	for _, tag := range strings.Split(os.Getenv("PACT_TAGS"), ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	for _, tag := range tags {
+		if err := brokerPut(fmt.Sprintf("%s/pacticipants/%s/versions/%s/tags/%s", broker, url.PathEscape(pact.Consumer.Name), url.PathEscape(version), url.PathEscape(tag)), nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func brokerPut(u string, body []byte) error {
+	request, err := http.NewRequest(http.MethodPut, u, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	if username := os.Getenv("PACT_BROKER_USERNAME"); username != "" {
+		request.SetBasicAuth(username, os.Getenv("PACT_BROKER_PASSWORD"))
+	}
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return errors.Wrap(err, "pact broker")
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= 300 {
+		return errors.Errorf("pact broker: %s responded with %d", u, response.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package testutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingTB struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recordingTB) Error(args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprint(args...))
+}
+
+func (r *recordingTB) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestPactMock(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pacts")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	mock := NewPactMock(t, "Shop", "Catalog")
+	mock.PactDir = dir
+	mock.AddInteraction(&PactInteraction{
+		Description:   "a product",
+		ProviderState: "product 1 exists",
+		Request: PactRequest{
+			Path:    "/products/1",
+			Query:   url.Values{"locale": {"de"}},
+			Headers: map[string]string{"Accept": "application/json"},
+		},
+		Response: PactResponse{
+			Body: map[string]interface{}{
+				"id":    Term("1", `^\d+$`),
+				"name":  Like("Shirt"),
+				"tags":  EachLike(map[string]interface{}{"code": Like("sale")}, 2),
+				"fixed": "value",
+			},
+		},
+	})
+
+	request, _ := http.NewRequest(http.MethodGet, mock.URL()+"/products/1?locale=de", nil)
+	request.Header.Set("Accept", "application/json")
+	response, err := http.DefaultClient.Do(request)
+	assert.NoError(t, err)
+	var body map[string]interface{}
+	assert.NoError(t, json.NewDecoder(response.Body).Decode(&body))
+	response.Body.Close()
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "Shirt", body["name"])
+	assert.Len(t, body["tags"], 2)
+
+	mock.Close()
+
+	content, err := ioutil.ReadFile(filepath.Join(dir, "shop-catalog.json"))
+	assert.NoError(t, err)
+	var pact pactFile
+	assert.NoError(t, json.Unmarshal(content, &pact))
+	assert.Equal(t, "Shop", pact.Consumer.Name)
+	assert.Equal(t, "Catalog", pact.Provider.Name)
+	assert.Len(t, pact.Interactions, 1)
+	assert.Equal(t, "locale=de", pact.Interactions[0].Request.Query)
+	assert.Equal(t, map[string]interface{}{"match": "type", "min": float64(2)}, pact.Interactions[0].Response.MatchingRules["$.body.tags"])
+	assert.Equal(t, map[string]interface{}{"match": "type"}, pact.Interactions[0].Response.MatchingRules["$.body.tags[*].code"])
+	assert.Equal(t, map[string]interface{}{"match": "regex", "regex": `^\d+$`}, pact.Interactions[0].Response.MatchingRules["$.body.id"])
+
+	t.Run("merge", func(t *testing.T) {
+		mock := NewPactMock(t, "Shop", "Catalog")
+		mock.PactDir = dir
+		mock.AddInteraction(&PactInteraction{Description: "health", Request: PactRequest{Path: "/health"}})
+		response, err := http.Get(mock.URL() + "/health")
+		assert.NoError(t, err)
+		response.Body.Close()
+		mock.Close()
+
+		content, _ := ioutil.ReadFile(filepath.Join(dir, "shop-catalog.json"))
+		var pact pactFile
+		assert.NoError(t, json.Unmarshal(content, &pact))
+		assert.Len(t, pact.Interactions, 2)
+	})
+
+	t.Run("verify", func(t *testing.T) {
+		var state bool
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/health" {
+				return
+			}
+			assert.True(t, state)
+			assert.Equal(t, "de", r.URL.Query().Get("locale"))
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"id":"42","name":"Pants","tags":[{"code":"new"},{"code":"sale"},{"code":"top"}],"fixed":"value","extra":true}`))
+		})
+
+		VerifyPact(t, handler, filepath.Join(dir, "shop-catalog.json"), PactStates{
+			"product 1 exists": func() error {
+				state = true
+				return nil
+			},
+		})
+	})
+}
+
+func TestPactMockUnexpected(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pacts")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	recorder := &recordingTB{TB: t}
+	mock := NewPactMock(recorder, "Shop", "Catalog")
+	mock.PactDir = dir
+	mock.AddInteraction(&PactInteraction{Description: "a product", Request: PactRequest{Path: "/products/1"}})
+
+	response, err := http.Post(mock.URL()+"/products/1", "application/json", nil)
+	assert.NoError(t, err)
+	response.Body.Close()
+	assert.Equal(t, http.StatusInternalServerError, response.StatusCode)
+
+	mock.Close()
+	assert.Len(t, recorder.errors, 2)
+
+	_, err = os.Stat(filepath.Join(dir, "shop-catalog.json"))
+	assert.True(t, os.IsNotExist(err), "no pact file is written for failed tests")
+}
+
+func TestComparePact(t *testing.T) {
+	rules := map[string]map[string]interface{}{
+		"$.body.items":       {"match": "type", "min": float64(1)},
+		"$.body.id":          {"match": "regex", "regex": "^[a-z]+$"},
+		"$.body.price":       {"match": "type"},
+		"$.body.items[*].id": {"match": "type"},
+	}
+	expected := map[string]interface{}{
+		"id":    "abc",
+		"price": float64(1),
+		"name":  "fixed",
+		"items": []interface{}{map[string]interface{}{"id": float64(1)}},
+	}
+
+	assert.Empty(t, comparePact(expected, map[string]interface{}{
+		"id":    "xyz",
+		"price": float64(2),
+		"name":  "fixed",
+		"items": []interface{}{map[string]interface{}{"id": float64(5)}, map[string]interface{}{"id": float64(6)}},
+	}, "$.body", rules, false))
+
+	assert.Len(t, comparePact(expected, map[string]interface{}{
+		"id":    "123",
+		"price": "2",
+		"name":  "other",
+		"items": []interface{}{},
+	}, "$.body", rules, false), 4)
+
+	assert.Equal(t, []string{"$.body.name: missing"}, comparePact(map[string]interface{}{"name": "x"}, map[string]interface{}{}, "$.body", nil, false))
+}
@@ -0,0 +1,181 @@
+package testutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+// PactStates are called with the provider state of an interaction before it is verified
+type PactStates map[string]func() error
+
+// VerifyPact replays the interactions of the pact file against the handler in-process,
+// e.g. the Handler of an App, and verifies the responses
+func VerifyPact(t *testing.T, handler http.Handler, file string, states PactStates) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var pact pactFile
+	if err := json.Unmarshal(content, &pact); err != nil {
+		t.Fatalf("pact file %s: %v", file, err)
+	}
+
+	for _, interaction := range pact.Interactions {
+		interaction := interaction
+		t.Run(interaction.Description, func(t *testing.T) {
+			if interaction.ProviderState != "" {
+				state, ok := states[interaction.ProviderState]
+				if !ok {
+					t.Fatalf("provider state %q is not defined", interaction.ProviderState)
+				}
+				if err := state(); err != nil {
+					t.Fatalf("provider state %q: %v", interaction.ProviderState, err)
+				}
+			}
+
+			for _, err := range verifyInteraction(handler, interaction) {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// verifyInteraction returns all mismatches of the handlers response
+func verifyInteraction(handler http.Handler, interaction pactInteraction) []string {
+	var body []byte
+	if interaction.Request.Body != nil {
+		body, _ = json.Marshal(interaction.Request.Body)
+	}
+
+	target := interaction.Request.Path
+	if interaction.Request.Query != "" {
+		target += "?" + interaction.Request.Query
+	}
+	request := httptest.NewRequest(interaction.Request.Method, BaseURL+target, bytes.NewReader(body))
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	for k, v := range interaction.Request.Headers {
+		request.Header.Set(k, v)
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	var mismatches []string
+	if recorder.Code != interaction.Response.Status {
+		mismatches = append(mismatches, fmt.Sprintf("expected status %d, got %d", interaction.Response.Status, recorder.Code))
+	}
+	for k, v := range interaction.Response.Headers {
+		if actual := recorder.Header().Get(k); actual != v {
+			mismatches = append(mismatches, fmt.Sprintf("expected header %s %q, got %q", k, v, actual))
+		}
+	}
+
+	if interaction.Response.Body == nil {
+		return mismatches
+	}
+
+	var actual interface{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &actual); err != nil {
+		return append(mismatches, fmt.Sprintf("response body is not JSON: %v", err))
+	}
+
+	return append(mismatches, comparePact(interaction.Response.Body, actual, "$.body", interaction.Response.MatchingRules, false)...)
+}
+
+// comparePact compares the actual value with the expected, extra keys of objects are allowed.
+// The matching rules of the path switch to type or regex comparison, type matching applies to all children.
+func comparePact(expected, actual interface{}, path string, rules map[string]map[string]interface{}, byType bool) []string {
+	rule := pactRule(rules, path)
+	if rule != nil {
+		switch rule["match"] {
+		case "type":
+			byType = true
+		case "regex":
+			pattern, _ := rule["regex"].(string)
+			s, ok := actual.(string)
+			if !ok {
+				return []string{fmt.Sprintf("%s: expected a string matching %q, got %v", path, pattern, actual)}
+			}
+			if matched, err := regexp.MatchString(pattern, s); err != nil || !matched {
+				return []string{fmt.Sprintf("%s: %q does not match %q", path, s, pattern)}
+			}
+			return nil
+		}
+	}
+
+	switch e := expected.(type) {
+	case map[string]interface{}:
+		a, ok := actual.(map[string]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected an object, got %v", path, actual)}
+		}
+		var mismatches []string
+		for k, v := range e {
+			value, ok := a[k]
+			if !ok {
+				mismatches = append(mismatches, fmt.Sprintf("%s.%s: missing", path, k))
+				continue
+			}
+			mismatches = append(mismatches, comparePact(v, value, path+"."+k, rules, byType)...)
+		}
+		return mismatches
+
+	case []interface{}:
+		a, ok := actual.([]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected an array, got %v", path, actual)}
+		}
+		if min, ok := rule["min"].(float64); ok {
+			if len(a) < int(min) {
+				return []string{fmt.Sprintf("%s: expected at least %d elements, got %d", path, int(min), len(a))}
+			}
+			if len(e) == 0 {
+				return nil
+			}
+			var mismatches []string
+			for i, value := range a {
+				mismatches = append(mismatches, comparePact(e[0], value, fmt.Sprintf("%s[%d]", path, i), rules, byType)...)
+			}
+			return mismatches
+		}
+		if len(a) != len(e) {
+			return []string{fmt.Sprintf("%s: expected %d elements, got %d", path, len(e), len(a))}
+		}
+		var mismatches []string
+		for i := range e {
+			mismatches = append(mismatches, comparePact(e[i], a[i], fmt.Sprintf("%s[%d]", path, i), rules, byType)...)
+		}
+		return mismatches
+	}
+
+	if byType {
+		if reflect.TypeOf(expected) != reflect.TypeOf(actual) {
+			return []string{fmt.Sprintf("%s: expected a value like %v, got %v", path, expected, actual)}
+		}
+		return nil
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		return []string{fmt.Sprintf("%s: expected %v, got %v", path, expected, actual)}
+	}
+	return nil
+}
+
+// pactRule finds the rule of the path, array indexes also match `[*]`
+func pactRule(rules map[string]map[string]interface{}, path string) map[string]interface{} {
+	if rule, ok := rules[path]; ok {
+		return rule
+	}
+	return rules[arrayIndex.ReplaceAllString(path, "[*]")]
+}
+
+var arrayIndex = regexp.MustCompile(`\[\d+\]`)
@@ -22,4 +22,21 @@ type (
 		OnRequestEvent
 		Error error
 	}
+
+	// OnSessionExpiredEvent is dispatched if the idle or absolute lifetime of the session is exceeded.
+	// The session still contains the expired values, it is cleared and regenerated afterwards.
+	OnSessionExpiredEvent struct {
+		OnRequestEvent
+		Session *Session
+		Reason  SessionExpiry
+	}
+
+	// SessionExpiry is the reason of a session expiration
+	SessionExpiry string
+)
+
+// Session expiration reasons
+const (
+	SessionExpiredIdle     SessionExpiry = "idle"
+	SessionExpiredAbsolute SessionExpiry = "absolute"
 )
@@ -2,7 +2,11 @@ package web
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"sort"
+
+	"go.opencensus.io/trace"
 )
 
 type (
@@ -11,6 +15,13 @@ type (
 		Filter(ctx context.Context, req *Request, w http.ResponseWriter, fc *FilterChain) Result
 	}
 
+	// PrioritizedFilter is called in the order of its priority, higher priorities first.
+	// Filters without priority have the priority 0 and keep the order of their bindings.
+	PrioritizedFilter interface {
+		Filter
+		FilterPriority() int
+	}
+
 	// FilterChain defines the chain which contains all filters which will be worked off
 	FilterChain struct {
 		filters   []Filter
@@ -42,6 +53,15 @@ func (fc *FilterChain) Next(ctx context.Context, req *Request, w http.ResponseWr
 
 	next := fc.filters[0]
 	fc.filters = fc.filters[1:]
+
+	// the span per filter is only worth its cost if the trace is sampled anyway
+	if parent := trace.FromContext(ctx); parent != nil && parent.SpanContext().IsSampled() {
+		var span *trace.Span
+		ctx, span = trace.StartSpan(ctx, "router/filter")
+		defer span.End()
+		span.AddAttributes(trace.StringAttribute("filter", fmt.Sprintf("%T", next)))
+	}
+
 	return next.Filter(ctx, req, w, fc)
 }
 
@@ -49,3 +69,22 @@ func (fc *FilterChain) Next(ctx context.Context, req *Request, w http.ResponseWr
 func (fc *FilterChain) AddPostApply(callback func(err error, result Result)) {
 	fc.postApply = append(fc.postApply, callback)
 }
+
+// sortFilters by their priority, see PrioritizedFilter
+func sortFilters(filters []Filter) []Filter {
+	sorted := make([]Filter, len(filters))
+	copy(sorted, filters)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return filterPriority(sorted[i]) > filterPriority(sorted[j])
+	})
+
+	return sorted
+}
+
+func filterPriority(filter Filter) int {
+	if prioritized, ok := filter.(PrioritizedFilter); ok {
+		return prioritized.FilterPriority()
+	}
+	return 0
+}
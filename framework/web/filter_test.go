@@ -0,0 +1,60 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opencensus.io/trace"
+)
+
+type (
+	namedFilter       string
+	prioritizedFilter struct {
+		namedFilter
+		priority int
+	}
+)
+
+func (f namedFilter) Filter(ctx context.Context, req *Request, w http.ResponseWriter, fc *FilterChain) Result {
+	return fc.Next(ctx, req, w)
+}
+
+func (f prioritizedFilter) FilterPriority() int {
+	return f.priority
+}
+
+func TestFilterChain_Span(t *testing.T) {
+	var spans []*trace.Span
+	final := func(ctx context.Context, req *Request, w http.ResponseWriter) Result {
+		spans = append(spans, trace.FromContext(ctx))
+		return nil
+	}
+
+	ctx, sampled := trace.StartSpan(context.Background(), "test", trace.WithSampler(trace.AlwaysSample()))
+	NewFilterChain(final, namedFilter("a")).Next(ctx, nil, nil)
+
+	ctx, unsampled := trace.StartSpan(context.Background(), "test", trace.WithSampler(trace.NeverSample()))
+	NewFilterChain(final, namedFilter("a")).Next(ctx, nil, nil)
+
+	NewFilterChain(final, namedFilter("a")).Next(context.Background(), nil, nil)
+
+	assert.NotEqual(t, sampled, spans[0], "a span per filter for sampled traces")
+	assert.Equal(t, sampled.SpanContext().TraceID, spans[0].SpanContext().TraceID)
+	assert.Equal(t, unsampled, spans[1], "no span per filter for traces which are not sampled")
+	assert.Nil(t, spans[2], "no span per filter without a trace")
+}
+
+func TestSortFilters(t *testing.T) {
+	filters := []Filter{
+		namedFilter("a"),
+		prioritizedFilter{namedFilter: "low", priority: -10},
+		namedFilter("b"),
+		prioritizedFilter{namedFilter: "high", priority: 10},
+	}
+
+	sorted := sortFilters(filters)
+	assert.Equal(t, []Filter{filters[3], filters[0], filters[2], filters[1]}, sorted)
+	assert.Equal(t, namedFilter("a"), filters[0], "the bound filters are not modified")
+}
@@ -0,0 +1,83 @@
+package web
+
+import (
+	"encoding/gob"
+	"fmt"
+)
+
+type (
+	// FlashLevel defines the severity of a flash message
+	FlashLevel string
+
+	// FlashMessage is a typed flash message, the message can be a translation key which is resolved with the args.
+	// Value keeps the original flash if it has been added with AddFlash and is not a string.
+	FlashMessage struct {
+		Level   FlashLevel
+		Message string
+		Args    map[string]interface{}
+		Value   interface{}
+	}
+)
+
+// Flash message levels
+const (
+	FlashSuccess FlashLevel = "success"
+	FlashInfo    FlashLevel = "info"
+	FlashWarning FlashLevel = "warning"
+	FlashError   FlashLevel = "error"
+)
+
+func init() {
+	gob.Register(FlashMessage{})
+}
+
+// AddFlashMessage adds a typed flash message to the session, an optional queue name can be given like for AddFlash
+func (s *Session) AddFlashMessage(level FlashLevel, message string, args map[string]interface{}, queue ...string) {
+	s.AddFlash(FlashMessage{Level: level, Message: message, Args: args}, queue...)
+}
+
+// FlashMessages returns and removes the flash messages of a queue, if a level is given only the messages with this level.
+// Flashes which have been added with AddFlash are returned as FlashInfo messages, other values than strings are kept in Value.
+func (s *Session) FlashMessages(level FlashLevel, queue ...string) []FlashMessage {
+	s.load()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	flashes := s.s.Flashes(queue...)
+	if len(flashes) == 0 {
+		return nil
+	}
+	s.dirty = true
+
+	var messages []FlashMessage
+	for _, flash := range flashes {
+		message := flashMessage(flash)
+
+		if level != "" && message.Level != level {
+			// keep the flash for a later request
+			s.s.AddFlash(flash, queue...)
+			continue
+		}
+		messages = append(messages, message)
+	}
+
+	return messages
+}
+
+// flashMessage converts a flash, flash messages decoded by the json session codec are generic maps
+func flashMessage(flash interface{}) FlashMessage {
+	switch flash := flash.(type) {
+	case FlashMessage:
+		return flash
+	case map[string]interface{}:
+		if level, ok := flash["Level"].(string); ok {
+			message, _ := flash["Message"].(string)
+			args, _ := flash["Args"].(map[string]interface{})
+			return FlashMessage{Level: FlashLevel(level), Message: message, Args: args, Value: flash["Value"]}
+		}
+	case string:
+		return FlashMessage{Level: FlashInfo, Message: flash}
+	}
+	return FlashMessage{Level: FlashInfo, Message: fmt.Sprint(flash), Value: flash}
+}
@@ -0,0 +1,28 @@
+package web
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSession_FlashMessages(t *testing.T) {
+	session := EmptySession()
+	session.AddFlashMessage(FlashSuccess, "login.success", map[string]interface{}{"name": "flamingo"})
+	session.AddFlashMessage(FlashError, "checkout.failed", nil)
+	session.AddFlash("plain")
+	session.AddFlash(map[string]interface{}{"custom": true})
+	session.AddFlashMessage(FlashWarning, "cart.changed", nil, "cart")
+
+	assert.Equal(t, []FlashMessage{{Level: FlashError, Message: "checkout.failed"}}, session.FlashMessages(FlashError))
+
+	assert.Equal(t, []FlashMessage{
+		{Level: FlashSuccess, Message: "login.success", Args: map[string]interface{}{"name": "flamingo"}},
+		{Level: FlashInfo, Message: "plain"},
+		{Level: FlashInfo, Message: "map[custom:true]", Value: map[string]interface{}{"custom": true}},
+	}, session.FlashMessages(""), "messages of other levels are kept, plain flashes are info messages which keep their value")
+	assert.Empty(t, session.FlashMessages(""))
+
+	assert.Equal(t, []FlashMessage{{Level: FlashWarning, Message: "cart.changed"}}, session.FlashMessages("", "cart"), "named queues")
+	assert.Empty(t, session.FlashMessages("", "cart"))
+}
@@ -26,9 +26,11 @@ type (
 		eventRouter flamingo.EventRouter
 		logger      flamingo.Logger
 
-		sessionStore sessions.Store
-		sessionName  string
-		prefix       string
+		sessionStore           sessions.Store
+		sessionName            string
+		sessionIdleTimeout     time.Duration
+		sessionAbsoluteTimeout time.Duration
+		prefix                 string
 	}
 
 	emptyResponseWriter struct{}
@@ -42,6 +44,22 @@ var (
 	RouterError contextKeyType = "error"
 )
 
+const (
+	sessionCreatedKey   = "flamingo.session.created"
+	sessionLastSeenKey  = "flamingo.session.lastSeen"
+	sessionRefreshedKey = "flamingo.session.refreshed"
+
+	// maxLastSeenResolution limits how often the last seen timestamp is written, so not every request changes the session
+	maxLastSeenResolution = time.Minute
+)
+
+// SessionKeys are the typed session values of the handler
+var SessionKeys = []flamingo.SessionKey{
+	{Key: sessionCreatedKey, Type: int64(0), Version: 1},
+	{Key: sessionLastSeenKey, Type: int64(0), Version: 1},
+	{Key: sessionRefreshedKey, Type: int64(0), Version: 1},
+}
+
 func init() {
 	if err := opencensus.View("flamingo/router/controller", rt, view.Distribution(100, 500, 1000, 2500, 5000, 10000), ControllerKey); err != nil {
 		panic(err)
@@ -71,6 +89,100 @@ func (h *handler) getSession(ctx context.Context, httpRequest *http.Request) (gs
 	return
 }
 
+// enforceSessionTimeouts expires the session if it has been idle or exists for too long, and tracks the timestamps
+func (h *handler) enforceSessionTimeouts(ctx context.Context, req *Request, rw http.ResponseWriter) {
+	if h.sessionIdleTimeout <= 0 && h.sessionAbsoluteTimeout <= 0 {
+		return
+	}
+
+	session := req.Session()
+	now := time.Now()
+	created, _ := session.Try(sessionCreatedKey).(int64)
+	lastSeen, _ := session.Try(sessionLastSeenKey).(int64)
+
+	var reason SessionExpiry
+	if h.sessionAbsoluteTimeout > 0 && created > 0 && now.Sub(time.Unix(created, 0)) > h.sessionAbsoluteTimeout {
+		reason = SessionExpiredAbsolute
+	} else if h.sessionIdleTimeout > 0 && lastSeen > 0 && now.Sub(time.Unix(lastSeen, 0)) > h.sessionIdleTimeout {
+		reason = SessionExpiredIdle
+	}
+
+	if reason != "" {
+		h.eventRouter.Dispatch(ctx, &OnSessionExpiredEvent{OnRequestEvent: OnRequestEvent{req, rw}, Session: session, Reason: reason})
+		session.ClearAll()
+		session.Regenerate()
+		created, lastSeen = 0, 0
+	}
+
+	if h.sessionAbsoluteTimeout > 0 && created == 0 {
+		session.Store(sessionCreatedKey, now.Unix())
+	}
+	if h.sessionIdleTimeout > 0 && now.Sub(time.Unix(lastSeen, 0)) >= lastSeenResolution(h.sessionIdleTimeout) {
+		session.Store(sessionLastSeenKey, now.Unix())
+	}
+}
+
+// lastSeenResolution is a tenth of the idle timeout, at most maxLastSeenResolution
+func lastSeenResolution(idleTimeout time.Duration) time.Duration {
+	if resolution := idleTimeout / 10; resolution < maxLastSeenResolution {
+		return resolution
+	}
+	return maxLastSeenResolution
+}
+
+// refreshSession marks an existing session to be saved if less than half of its max age remains,
+// so the expiry of the cookie and the backend is extended for sessions which are still used
+func (h *handler) refreshSession(req *Request) {
+	gs := req.Session().loaded()
+	if gs == nil || gs.IsNew || gs.Options == nil || gs.Options.MaxAge <= 0 {
+		return
+	}
+
+	refreshed, _ := req.Session().Try(sessionRefreshedKey).(int64)
+	if time.Since(time.Unix(refreshed, 0)) >= time.Duration(gs.Options.MaxAge)*time.Second/2 {
+		req.Session().MarkDirty()
+	}
+}
+
+// saveSession saves the session if it has been changed, and records the time of the save for refreshSession
+func (h *handler) saveSession(ctx context.Context, name string, req *Request, rw http.ResponseWriter) {
+	gs := req.Session().takeDirty()
+	if gs == nil {
+		return
+	}
+
+	ctx, span := trace.StartSpan(ctx, name)
+	defer span.End()
+
+	gs.Values[sessionRefreshedKey] = time.Now().Unix()
+
+	h.removePreviousSession(ctx, req, gs)
+	if err := h.sessionStore.Save(req.Request(), rw, gs); err != nil {
+		h.logger.WithContext(ctx).Warn(err)
+	}
+}
+
+// removePreviousSession removes the session with the id before it has been regenerated from the backend
+func (h *handler) removePreviousSession(ctx context.Context, req *Request, gs *sessions.Session) {
+	previousID := req.Session().takePreviousID()
+	if previousID == "" || gs == nil {
+		return
+	}
+
+	previous := sessions.NewSession(h.sessionStore, h.sessionName)
+	previous.ID = previousID
+	options := sessions.Options{}
+	if gs.Options != nil {
+		options = *gs.Options
+	}
+	options.MaxAge = -1
+	previous.Options = &options
+
+	if err := h.sessionStore.Save(req.Request(), emptyResponseWriter{}, previous); err != nil {
+		h.logger.WithContext(ctx).Warn(err)
+	}
+}
+
 func panicToError(p interface{}) error {
 	if p == nil {
 		return nil
@@ -94,8 +206,6 @@ func (h *handler) ServeHTTP(rw http.ResponseWriter, httpRequest *http.Request) {
 	ctx, span := trace.StartSpan(httpRequest.Context(), "router/ServeHTTP")
 	defer span.End()
 
-	gs := h.getSession(ctx, httpRequest)
-
 	_, span = trace.StartSpan(ctx, "router/matchRequest")
 	controller, params, handler := h.routerRegistry.matchRequest(httpRequest)
 
@@ -110,13 +220,25 @@ func (h *handler) ServeHTTP(rw http.ResponseWriter, httpRequest *http.Request) {
 
 	req := &Request{
 		request: *httpRequest,
-		session: Session{
-			s: gs,
-		},
-		Params: params,
+		Params:  params,
 	}
 	ctx = ContextWithRequest(ContextWithSession(ctx, req.Session()), req)
 
+	withSession := h.sessionStore != nil && (handler == nil || !handler.noSession)
+	if withSession {
+		sessionCtx := ctx
+		req.session.loader = func() *sessions.Session {
+			return h.getSession(sessionCtx, httpRequest)
+		}
+		req.session.onLoad = func(*Session) {
+			h.enforceSessionTimeouts(sessionCtx, req, rw)
+			h.refreshSession(req)
+		}
+	} else {
+		// routes without session get a session which is never persisted
+		req.session.s = sessions.NewSession(nil, h.sessionName)
+	}
+
 	var finishErr error
 	defer func() {
 		// fire finish event
@@ -130,8 +252,14 @@ func (h *handler) ServeHTTP(rw http.ResponseWriter, httpRequest *http.Request) {
 	ctx, span = trace.StartSpan(ctx, "router/request")
 	defer span.End()
 
+	filters := h.filter
+	if handler != nil && len(handler.filters) > 0 {
+		filters = make([]Filter, 0, len(h.filter)+len(handler.filters))
+		filters = append(append(filters, h.filter...), handler.filters...)
+	}
+
 	chain := &FilterChain{
-		filters: h.filter,
+		filters: filters,
 		final: func(ctx context.Context, r *Request, rw http.ResponseWriter) (response Result) {
 			ctx, span := trace.StartSpan(ctx, "router/controller")
 			defer span.End()
@@ -160,12 +288,8 @@ func (h *handler) ServeHTTP(rw http.ResponseWriter, httpRequest *http.Request) {
 
 	result := chain.Next(ctx, req, rw)
 
-	if h.sessionStore != nil {
-		ctx, span := trace.StartSpan(ctx, "router/sessions/save")
-		if err := h.sessionStore.Save(req.Request(), rw, gs); err != nil {
-			h.logger.WithContext(ctx).Warn(err)
-		}
-		span.End()
+	if withSession {
+		h.saveSession(ctx, "router/sessions/save", req, rw)
 	}
 
 	var finalErr error
@@ -182,13 +306,9 @@ func (h *handler) ServeHTTP(rw http.ResponseWriter, httpRequest *http.Request) {
 		span.End()
 	}
 
-	// ensure that the session has been saved in the backend
-	if h.sessionStore != nil {
-		ctx, span := trace.StartSpan(ctx, "router/sessions/persist")
-		if err := h.sessionStore.Save(req.Request(), emptyResponseWriter{}, gs); err != nil {
-			h.logger.WithContext(ctx).Warn(err)
-		}
-		span.End()
+	// ensure that changes to the session during the response have been saved in the backend
+	if withSession {
+		h.saveSession(ctx, "router/sessions/persist", req, emptyResponseWriter{})
 	}
 
 	for _, cb := range chain.postApply {
@@ -1,11 +1,13 @@
 package web
 
 import (
+	"fmt"
 	"net/http"
 	"sort"
 	"strings"
 
 	"flamingo.me/dingo"
+	"flamingo.me/flamingo/v3/framework/config"
 	"github.com/pkg/errors"
 )
 
@@ -26,10 +28,13 @@ type (
 
 	// Handler defines a concrete Controller
 	Handler struct {
-		path     *Path
-		handler  string
-		params   map[string]*param
-		catchall bool
+		path      *Path
+		handler   string
+		params    map[string]*param
+		catchall  bool
+		methods   map[string]struct{}
+		filters   []Filter
+		noSession bool
 	}
 
 	handlerAction struct {
@@ -198,6 +203,11 @@ func (registry *RouterRegistry) GetRoutes() []*Handler {
 	return registry.routes
 }
 
+// GetHandlerNames returns the sorted names of all registered handlers
+func (registry *RouterRegistry) GetHandlerNames() []string {
+	return getSortedMapKeys(registry.handler)
+}
+
 // getHandler returns registered Routes
 func (registry *RouterRegistry) getHandler() map[string]handlerAction {
 	return registry.handler
@@ -208,6 +218,47 @@ func (registry *RouterRegistry) Alias(name, to string) {
 	registry.alias[name] = parseHandler(to)
 }
 
+// configRoute registers a route loaded from the routes config, with its methods, filters and args.
+// Invalid routes are not registered at all, so a route is never served without its filters.
+func (registry *RouterRegistry) configRoute(route config.Route, filters map[string]Filter) error {
+	routeFilters := make([]Filter, 0, len(route.Filters))
+	for _, name := range route.Filters {
+		filter, ok := filters[name]
+		if !ok {
+			return errors.Errorf("route %q uses unknown filter %q", route.Path, name)
+		}
+		routeFilters = append(routeFilters, filter)
+	}
+
+	handler, err := registry.Route(route.Path, route.Controller)
+	if err != nil {
+		return errors.Wrapf(err, "invalid route %q", route.Path)
+	}
+
+	args := make(map[string]string, len(route.Args))
+	for k, v := range route.Args {
+		args[k] = fmt.Sprint(v)
+	}
+	handler.Args(args)
+
+	if len(route.Methods) > 0 {
+		handler.Methods(route.Methods...)
+	}
+
+	if route.NoSession {
+		handler.NoSession()
+	}
+
+	handler.Filters(routeFilters...)
+
+	if route.Name != "" {
+		registry.Alias(route.Name, route.Controller)
+		registry.alias[route.Name].Args(args)
+	}
+
+	return nil
+}
+
 func parseHandler(h string) *Handler {
 	var tmp = strings.SplitN(h, "(", 2)
 	h = tmp[0]
@@ -425,6 +476,9 @@ func (registry *RouterRegistry) matchRequest(req *http.Request) (handlerAction,
 
 	var matchedHandlers matchedHandlers
 	for _, handler := range registry.routes {
+		if !handler.allowsMethod(req.Method) {
+			continue
+		}
 		if match := handler.path.Match(path); match != nil {
 			controller := registry.handler[handler.handler]
 			matchedHandler := &matchedHandler{
@@ -492,6 +546,45 @@ func (handler *Handler) GetHandlerName() string {
 	return handler.handler
 }
 
+// Methods restricts the route to the given HTTP methods
+func (handler *Handler) Methods(methods ...string) *Handler {
+	if handler.methods == nil {
+		handler.methods = make(map[string]struct{}, len(methods))
+	}
+	for _, m := range methods {
+		handler.methods[strings.ToUpper(m)] = struct{}{}
+	}
+	return handler
+}
+
+// Filters adds filters which are only applied to requests matching this route
+func (handler *Handler) Filters(filters ...Filter) *Handler {
+	handler.filters = append(handler.filters, filters...)
+	return handler
+}
+
+// NoSession disables the session for requests matching this route, the session is neither loaded nor saved
+func (handler *Handler) NoSession() *Handler {
+	handler.noSession = true
+	return handler
+}
+
+// Args sets fixed parameters for the route, similar to `controller(key="value")`
+func (handler *Handler) Args(args map[string]string) *Handler {
+	for k, v := range args {
+		handler.params[k] = &param{value: v}
+	}
+	return handler
+}
+
+func (handler *Handler) allowsMethod(method string) bool {
+	if len(handler.methods) == 0 {
+		return true
+	}
+	_, ok := handler.methods[method]
+	return ok
+}
+
 // Normalize enforces a normalization of passed parameters
 func (handler *Handler) Normalize(params ...string) *Handler {
 	if handler.path.normalize == nil {
@@ -46,7 +46,7 @@ func CreateRequest(r *http.Request, s *Session) *Request {
 		req.request = *r
 	}
 	if s != nil {
-		req.session.s = s.s
+		req.session.s = s.load()
 	} else {
 		req.session = *EmptySession()
 	}
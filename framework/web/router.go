@@ -7,6 +7,7 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
 
 	"flamingo.me/flamingo/v3/framework/config"
 	"flamingo.me/flamingo/v3/framework/flamingo"
@@ -27,19 +28,23 @@ type (
 		Absolute(r *Request, to string, params map[string]string) (*url.URL, error)
 	}
 
-	filterProvider func() []Filter
-	routesProvider func() []RoutesModule
+	filterProvider      func() []Filter
+	routeFilterProvider func() map[string]Filter
+	routesProvider      func() []RoutesModule
 
 	Router struct {
-		base           *url.URL
-		eventRouter    flamingo.EventRouter
-		filterProvider filterProvider
-		routesProvider routesProvider
-		logger         flamingo.Logger
-		routerRegistry *RouterRegistry
-		configArea     *config.Area
-		sessionStore   sessions.Store
-		sessionName    string
+		base                *url.URL
+		eventRouter         flamingo.EventRouter
+		filterProvider      filterProvider
+		routeFilterProvider routeFilterProvider
+		routesProvider      routesProvider
+		logger              flamingo.Logger
+		routerRegistry      *RouterRegistry
+		configArea          *config.Area
+		sessionStore        sessions.Store
+		sessionName         string
+		sessionIdle         time.Duration
+		sessionAbsolute     time.Duration
 	}
 )
 
@@ -56,9 +61,15 @@ func (r *Router) Inject(
 		Scheme string `inject:"config:flamingo.router.scheme,optional"`
 		Host   string `inject:"config:flamingo.router.host,optional"`
 		Path   string `inject:"config:flamingo.router.path,optional"`
+		// name of the session cookie
+		SessionName string `inject:"config:session.name,optional"`
+		// session lifetimes in seconds, 0 disables them
+		SessionIdleTimeout     float64 `inject:"config:session.timeout.idle,optional"`
+		SessionAbsoluteTimeout float64 `inject:"config:session.timeout.absolute,optional"`
 	},
 	eventRouter flamingo.EventRouter,
 	filterProvider filterProvider,
+	routeFilterProvider routeFilterProvider,
 	routesProvider routesProvider,
 	logger flamingo.Logger,
 	configArea *config.Area,
@@ -71,11 +82,17 @@ func (r *Router) Inject(
 	}
 	r.eventRouter = eventRouter
 	r.filterProvider = filterProvider
+	r.routeFilterProvider = routeFilterProvider
 	r.routesProvider = routesProvider
 	r.logger = logger
 	r.configArea = configArea
 	r.sessionStore = sessionStore
-	r.sessionName = "flamingo"
+	r.sessionName = cfg.SessionName
+	if r.sessionName == "" {
+		r.sessionName = "flamingo"
+	}
+	r.sessionIdle = time.Duration(cfg.SessionIdleTimeout) * time.Second
+	r.sessionAbsolute = time.Duration(cfg.SessionAbsoluteTimeout) * time.Second
 }
 
 func (r *Router) Handler() http.Handler {
@@ -90,10 +107,14 @@ func (r *Router) Handler() http.Handler {
 	}
 
 	if r.configArea != nil {
+		var routeFilters map[string]Filter
+		if r.routeFilterProvider != nil {
+			routeFilters = r.routeFilterProvider()
+		}
+
 		for _, route := range r.configArea.Routes {
-			r.routerRegistry.Route(route.Path, route.Controller)
-			if route.Name != "" {
-				r.routerRegistry.Alias(route.Name, route.Controller)
+			if err := r.routerRegistry.configRoute(route, routeFilters); err != nil {
+				r.logger.WithField(flamingo.LogKeyCategory, "router").Error("skipping config route: ", err)
 			}
 		}
 	}
@@ -105,13 +126,15 @@ func (r *Router) Handler() http.Handler {
 	}
 
 	return &handler{
-		routerRegistry: r.routerRegistry,
-		filter:         r.filterProvider(),
-		eventRouter:    r.eventRouter,
-		logger:         r.logger,
-		sessionStore:   r.sessionStore,
-		sessionName:    r.sessionName,
-		prefix:         strings.TrimRight(r.base.Path, "/"),
+		routerRegistry:         r.routerRegistry,
+		filter:                 sortFilters(r.filterProvider()),
+		eventRouter:            r.eventRouter,
+		logger:                 r.logger,
+		sessionStore:           r.sessionStore,
+		sessionName:            r.sessionName,
+		sessionIdleTimeout:     r.sessionIdle,
+		sessionAbsoluteTimeout: r.sessionAbsolute,
+		prefix:                 strings.TrimRight(r.base.Path, "/"),
 	}
 }
 
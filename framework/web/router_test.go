@@ -9,6 +9,7 @@ import (
 	"net/url"
 	"testing"
 
+	"flamingo.me/flamingo/v3/framework/config"
 	"flamingo.me/flamingo/v3/framework/flamingo"
 	"github.com/stretchr/testify/assert"
 )
@@ -142,6 +143,43 @@ func TestRouter(t *testing.T) {
 		assert.NoError(t, testReq("UNASSIGNED", "/test"))
 		assert.Equal(t, "HandleAny", method)
 	})
+
+	t.Run("Test Config Routes", func(t *testing.T) {
+		registry := NewRegistry()
+		h.(*handler).routerRegistry = registry
+
+		var filtered bool
+		filters := map[string]Filter{
+			"test": testFilter(func() { filtered = true }),
+		}
+
+		assert.NoError(t, registry.configRoute(config.Route{Path: "/config", Controller: "config.get", Methods: []string{"get"}, Filters: []string{"test"}, Args: map[string]interface{}{"page": 1}}, filters))
+		assert.NoError(t, registry.configRoute(config.Route{Path: "/config", Controller: "config.any", Name: "config"}, filters))
+		assert.Error(t, registry.configRoute(config.Route{Path: "/unknown", Controller: "config.any", Filters: []string{"unknown"}}, filters))
+		assert.Len(t, registry.GetRoutes(), 2, "routes with unknown filters are not registered")
+
+		var page string
+		registry.HandleAny("config.get", func(_ context.Context, r *Request) Result { method = "get"; page = r.Params["page"]; return nil })
+		registry.HandleAny("config.any", func(context.Context, *Request) Result { method = "any"; return nil })
+
+		method, page, filtered = "", "", false
+		assert.NoError(t, testReq(http.MethodGet, "/config"))
+		assert.Equal(t, "get", method)
+		assert.Equal(t, "1", page)
+		assert.True(t, filtered)
+
+		method, page, filtered = "", "", false
+		assert.NoError(t, testReq(http.MethodPost, "/config"))
+		assert.Equal(t, "any", method)
+		assert.False(t, filtered)
+	})
+}
+
+type testFilter func()
+
+func (f testFilter) Filter(ctx context.Context, req *Request, w http.ResponseWriter, fc *FilterChain) Result {
+	f()
+	return fc.Next(ctx, req, w)
 }
 
 func TestRouterTestify(t *testing.T) {
@@ -2,15 +2,25 @@ package web
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"strings"
 	"sync"
 
 	"github.com/gorilla/sessions"
 )
 
-// Session holds the data connected to the current user session
+// Session holds the data connected to the current user session.
+// The session is loaded from the backend on first access, and only saved if it has been changed by Store, Delete,
+// ClearAll, Regenerate or the flash methods. Values which are changed in place, such as a stored map or a pointer,
+// are not detected: store them again or call MarkDirty.
 type Session struct {
-	mu sync.RWMutex
-	s  *sessions.Session
+	mu         sync.RWMutex
+	s          *sessions.Session
+	previousID string
+	dirty      bool
+	loader     func() *sessions.Session
+	onLoad     func(*Session)
 }
 
 const contextSession contextKeyType = "session"
@@ -31,8 +41,61 @@ func SessionFromContext(ctx context.Context) *Session {
 	return session
 }
 
+// load the session from the backend on first access, onLoad is called afterwards without holding the lock
+func (s *Session) load() *sessions.Session {
+	s.mu.Lock()
+	loader, onLoad := s.loader, s.onLoad
+	s.loader, s.onLoad = nil, nil
+	if loader != nil {
+		s.s = loader()
+	}
+	gs := s.s
+	s.mu.Unlock()
+
+	if loader != nil && onLoad != nil {
+		onLoad(s)
+	}
+
+	return gs
+}
+
+// takeDirty returns the gorilla session if it has been changed since the last call
+func (s *Session) takeDirty() *sessions.Session {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.dirty || s.loader != nil {
+		return nil
+	}
+	s.dirty = false
+	return s.s
+}
+
+// loaded returns the gorilla session if it has been loaded
+func (s *Session) loaded() *sessions.Session {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.loader != nil {
+		return nil
+	}
+	return s.s
+}
+
+// MarkDirty marks the session to be saved, e.g. after a stored value has been changed in place
+func (s *Session) MarkDirty() {
+	s.load()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.dirty = true
+}
+
 // Load data by a key
 func (s *Session) Load(key interface{}) (data interface{}, ok bool) {
+	s.load()
+
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
@@ -42,6 +105,8 @@ func (s *Session) Load(key interface{}) (data interface{}, ok bool) {
 
 // Try to load data by a key
 func (s *Session) Try(key interface{}) (data interface{}) {
+	s.load()
+
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
@@ -51,35 +116,83 @@ func (s *Session) Try(key interface{}) (data interface{}) {
 
 // Store data with a key in the Session
 func (s *Session) Store(key interface{}, data interface{}) *Session {
+	s.load()
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	s.s.Values[key] = data
+	s.dirty = true
 
 	return s
 }
 
 // Delete a given key from the session
 func (s *Session) Delete(key interface{}) {
+	s.load()
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	delete(s.s.Values, key)
+	if _, ok := s.s.Values[key]; ok {
+		delete(s.s.Values, key)
+		s.dirty = true
+	}
 }
 
 // ID returns the Session id
 func (s *Session) ID() (id string) {
+	s.load()
+
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
 	return s.s.ID
 }
 
+// Regenerate the session id to prevent session fixation, e.g. after a login, the session values are kept.
+// The session with the previous id is removed from the session backend when the session is saved.
+func (s *Session) Regenerate() {
+	s.load()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.s == nil {
+		return
+	}
+
+	if s.previousID == "" {
+		s.previousID = s.s.ID
+	}
+
+	id := make([]byte, 32)
+	_, _ = rand.Read(id)
+	s.s.ID = strings.TrimRight(base32.StdEncoding.EncodeToString(id), "=")
+	s.dirty = true
+}
+
+// takePreviousID returns the id before the session has been regenerated, if it has not been removed yet
+func (s *Session) takePreviousID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := s.previousID
+	s.previousID = ""
+	return id
+}
+
 // Keys returns an unordered list of session keys
 func (s *Session) Keys() []interface{} {
+	s.load()
+
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
+	if s.s == nil {
+		return nil
+	}
+
 	keys := make([]interface{}, len(s.s.Values))
 	i := 0
 	for k := range s.s.Values {
@@ -91,28 +204,39 @@ func (s *Session) Keys() []interface{} {
 
 // ClearAll removes all values from the session
 func (s *Session) ClearAll() *Session {
+	s.load()
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	s.s.Values = make(map[interface{}]interface{})
+	s.dirty = true
 	return s
 }
 
-
 // Flashes returns a slice of flash messages from the session
 // todo change?
 func (s *Session) Flashes(vars ...string) []interface{} {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	s.load()
 
-	return s.s.Flashes(vars...)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	flashes := s.s.Flashes(vars...)
+	if len(flashes) > 0 {
+		s.dirty = true
+	}
+	return flashes
 }
 
 // AddFlash adds a flash message to the session.
 // todo change?
 func (s *Session) AddFlash(value interface{}, vars ...string) {
+	s.load()
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	s.s.AddFlash(value, vars...)
+	s.dirty = true
 }
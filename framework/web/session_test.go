@@ -0,0 +1,330 @@
+package web
+
+import (
+	"context"
+	"encoding/gob"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"flamingo.me/flamingo/v3/framework/config"
+	"flamingo.me/flamingo/v3/framework/flamingo"
+	"github.com/gorilla/sessions"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingEventRouter struct {
+	events []flamingo.Event
+}
+
+func (r *recordingEventRouter) Dispatch(_ context.Context, event flamingo.Event) {
+	r.events = append(r.events, event)
+}
+
+func newSessionTestHandler(t *testing.T, controller Action) (*handler, *recordingEventRouter, string) {
+	dir, err := ioutil.TempDir("", "sessions")
+	assert.NoError(t, err)
+
+	registry := NewRegistry()
+	_, err = registry.Route("/", "test")
+	assert.NoError(t, err)
+	registry.HandleAny("test", controller)
+
+	events := new(recordingEventRouter)
+	store := sessions.NewFilesystemStore(dir, []byte("secret"))
+
+	return &handler{
+		routerRegistry: registry,
+		eventRouter:    events,
+		logger:         new(flamingo.NullLogger),
+		sessionStore:   store,
+		sessionName:    "flamingo",
+	}, events, dir
+}
+
+func sessionTestRequest(h http.Handler, cookies []*http.Cookie) []*http.Cookie {
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	for _, cookie := range cookies {
+		request.AddCookie(cookie)
+	}
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, request)
+
+	if result := recorder.Result().Cookies(); len(result) > 0 {
+		return result
+	}
+	return cookies
+}
+
+func TestSessionRegenerate(t *testing.T) {
+	var ids []string
+	regenerate := false
+	h, _, dir := newSessionTestHandler(t, func(ctx context.Context, r *Request) Result {
+		if regenerate {
+			r.Session().Regenerate()
+		}
+		r.Session().Store("user", "flamingo")
+		ids = append(ids, r.Session().ID())
+		return nil
+	})
+	defer os.RemoveAll(dir)
+
+	cookies := sessionTestRequest(h, nil)
+	assert.Equal(t, []string{""}, ids, "new sessions get their id when they are saved")
+	ids = nil
+	cookies = sessionTestRequest(h, cookies)
+	assert.FileExists(t, filepath.Join(dir, "session_"+ids[0]))
+
+	regenerate = true
+	cookies = sessionTestRequest(h, cookies)
+	assert.NotEqual(t, ids[0], ids[1])
+	assert.FileExists(t, filepath.Join(dir, "session_"+ids[1]))
+	_, err := os.Stat(filepath.Join(dir, "session_"+ids[0]))
+	assert.True(t, os.IsNotExist(err), "the previous session is removed")
+
+	regenerate = false
+	var user interface{}
+	h.routerRegistry.HandleAny("test", func(ctx context.Context, r *Request) Result {
+		user = r.Session().Try("user")
+		ids = append(ids, r.Session().ID())
+		return nil
+	})
+	sessionTestRequest(h, cookies)
+	assert.Equal(t, ids[1], ids[2])
+	assert.Equal(t, "flamingo", user, "the values are kept")
+}
+
+func TestSessionTimeouts(t *testing.T) {
+	var values []interface{}
+	var seen func(*Session)
+	h, events, dir := newSessionTestHandler(t, func(ctx context.Context, r *Request) Result {
+		values = append(values, r.Session().Try("user"))
+		r.Session().Store("user", "flamingo")
+		if seen != nil {
+			seen(r.Session())
+		}
+		return nil
+	})
+	defer os.RemoveAll(dir)
+
+	h.sessionIdleTimeout = time.Minute
+	h.sessionAbsoluteTimeout = time.Hour
+
+	t.Run("active", func(t *testing.T) {
+		values, events.events = nil, nil
+		cookies := sessionTestRequest(h, nil)
+		sessionTestRequest(h, cookies)
+		assert.Equal(t, []interface{}{nil, "flamingo"}, values)
+		for _, event := range events.events {
+			_, expired := event.(*OnSessionExpiredEvent)
+			assert.False(t, expired)
+		}
+	})
+
+	for reason, key := range map[SessionExpiry]string{SessionExpiredIdle: sessionLastSeenKey, SessionExpiredAbsolute: sessionCreatedKey} {
+		t.Run(string(reason), func(t *testing.T) {
+			values, events.events = nil, nil
+			seen = func(session *Session) {
+				session.Store(key, time.Now().Add(-2*time.Hour).Unix())
+			}
+			cookies := sessionTestRequest(h, nil)
+			seen = nil
+			sessionTestRequest(h, cookies)
+
+			assert.Equal(t, []interface{}{nil, nil}, values, "the session is cleared")
+
+			var expired *OnSessionExpiredEvent
+			for _, event := range events.events {
+				if e, ok := event.(*OnSessionExpiredEvent); ok {
+					expired = e
+				}
+			}
+			if assert.NotNil(t, expired) {
+				assert.Equal(t, reason, expired.Reason)
+			}
+		})
+	}
+}
+
+type countingStore struct {
+	sessions.Store
+	gets, saves int
+}
+
+func (s *countingStore) Get(r *http.Request, name string) (*sessions.Session, error) {
+	s.gets++
+	return s.Store.Get(r, name)
+}
+
+func (s *countingStore) Save(r *http.Request, w http.ResponseWriter, session *sessions.Session) error {
+	s.saves++
+	return s.Store.Save(r, w, session)
+}
+
+func TestSessionLazyLoading(t *testing.T) {
+	var action func(*Session)
+	h, _, dir := newSessionTestHandler(t, func(ctx context.Context, r *Request) Result {
+		action(r.Session())
+		return nil
+	})
+	defer os.RemoveAll(dir)
+
+	_, err := h.routerRegistry.Route("/static", "static")
+	assert.NoError(t, err)
+	h.routerRegistry.HandleAny("static", func(ctx context.Context, r *Request) Result {
+		r.Session().Store("user", "static")
+		return nil
+	})
+	for _, route := range h.routerRegistry.GetRoutes() {
+		if route.GetHandlerName() == "static" {
+			route.NoSession()
+		}
+	}
+
+	store := &countingStore{Store: h.sessionStore}
+	h.sessionStore = store
+
+	action = func(*Session) {}
+	sessionTestRequest(h, nil)
+	assert.Equal(t, 0, store.gets, "the session is not loaded if it is not used")
+	assert.Equal(t, 0, store.saves)
+
+	action = func(s *Session) { s.Store("user", "flamingo") }
+	cookies := sessionTestRequest(h, nil)
+	assert.Equal(t, 1, store.gets)
+	assert.Equal(t, 1, store.saves, "changed sessions are saved once")
+
+	var user interface{}
+	action = func(s *Session) { user = s.Try("user") }
+	sessionTestRequest(h, cookies)
+	assert.Equal(t, "flamingo", user)
+	assert.Equal(t, 2, store.gets)
+	assert.Equal(t, 1, store.saves, "unchanged sessions are not saved")
+
+	action = func(s *Session) { s.Delete("unknown") }
+	sessionTestRequest(h, cookies)
+	assert.Equal(t, 1, store.saves, "deleting unknown keys does not change the session")
+
+	request := httptest.NewRequest(http.MethodGet, "/static", nil)
+	for _, cookie := range cookies {
+		request.AddCookie(cookie)
+	}
+	h.ServeHTTP(httptest.NewRecorder(), request)
+	assert.Equal(t, 3, store.gets, "routes without session do not load it")
+	assert.Equal(t, 1, store.saves)
+
+	action = func(s *Session) { user = s.Try("user") }
+	sessionTestRequest(h, cookies)
+	assert.Equal(t, "flamingo", user, "routes without session do not change it")
+}
+
+func TestSessionNoSessionConfigRoute(t *testing.T) {
+	h, _, dir := newSessionTestHandler(t, func(ctx context.Context, r *Request) Result {
+		r.Session().Store("user", "flamingo")
+		return nil
+	})
+	defer os.RemoveAll(dir)
+
+	h.routerRegistry.HandleAny("static", func(ctx context.Context, r *Request) Result {
+		r.Session().Store("user", "static")
+		return nil
+	})
+	assert.NoError(t, h.routerRegistry.configRoute(config.Route{Path: "/static", Controller: "static", NoSession: true}, nil))
+
+	store := &countingStore{Store: h.sessionStore}
+	h.sessionStore = store
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/static", nil))
+	assert.Equal(t, 0, store.gets, "config routes with noSession do not load the session")
+	assert.Equal(t, 0, store.saves, "config routes with noSession do not save the session")
+
+	sessionTestRequest(h, nil)
+	assert.Equal(t, 1, store.saves, "other routes keep their session")
+}
+
+func TestSessionMarkDirty(t *testing.T) {
+	gob.Register(map[string]string{})
+
+	var action func(*Session)
+	h, _, dir := newSessionTestHandler(t, func(ctx context.Context, r *Request) Result {
+		action(r.Session())
+		return nil
+	})
+	defer os.RemoveAll(dir)
+
+	action = func(s *Session) { s.Store("cart", map[string]string{}) }
+	cookies := sessionTestRequest(h, nil)
+
+	action = func(s *Session) { s.Try("cart").(map[string]string)["sku"] = "changed in place" }
+	sessionTestRequest(h, cookies)
+
+	var cart map[string]string
+	action = func(s *Session) { cart = s.Try("cart").(map[string]string) }
+	sessionTestRequest(h, cookies)
+	assert.Empty(t, cart, "values changed in place are not saved")
+
+	action = func(s *Session) {
+		s.Try("cart").(map[string]string)["sku"] = "changed in place"
+		s.MarkDirty()
+	}
+	sessionTestRequest(h, cookies)
+
+	action = func(s *Session) { cart = s.Try("cart").(map[string]string) }
+	sessionTestRequest(h, cookies)
+	assert.Equal(t, map[string]string{"sku": "changed in place"}, cart, "marked sessions are saved")
+}
+
+func TestSessionRefresh(t *testing.T) {
+	h := new(handler)
+	maxAge := time.Hour
+
+	for name, tt := range map[string]struct {
+		isNew     bool
+		refreshed time.Duration
+		dirty     bool
+	}{
+		"recently saved":              {refreshed: 10 * time.Minute, dirty: false},
+		"less than half max age left": {refreshed: 40 * time.Minute, dirty: true},
+		"never refreshed":             {refreshed: -1, dirty: true},
+		"new session":                 {isNew: true, refreshed: -1, dirty: false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			gs := sessions.NewSession(nil, "flamingo")
+			gs.IsNew = tt.isNew
+			gs.Options = &sessions.Options{MaxAge: int(maxAge.Seconds())}
+			if tt.refreshed >= 0 {
+				gs.Values[sessionRefreshedKey] = time.Now().Add(-tt.refreshed).Unix()
+			}
+
+			req := &Request{session: Session{s: gs}}
+			h.refreshSession(req)
+			assert.Equal(t, tt.dirty, req.Session().takeDirty() != nil)
+		})
+	}
+}
+
+func TestSessionLastSeenResolution(t *testing.T) {
+	h, _, dir := newSessionTestHandler(t, func(ctx context.Context, r *Request) Result {
+		r.Session().Try("user")
+		return nil
+	})
+	defer os.RemoveAll(dir)
+
+	h.sessionIdleTimeout = time.Hour
+	store := &countingStore{Store: h.sessionStore}
+	h.sessionStore = store
+
+	cookies := sessionTestRequest(h, nil)
+	assert.Equal(t, 1, store.saves, "the last seen timestamp of a new session is saved")
+
+	sessionTestRequest(h, cookies)
+	sessionTestRequest(h, cookies)
+	assert.Equal(t, 1, store.saves, "the last seen timestamp is not written on every request")
+
+	assert.Equal(t, 6*time.Second, lastSeenResolution(time.Minute))
+	assert.Equal(t, time.Minute, lastSeenResolution(time.Hour))
+}
@@ -2,7 +2,8 @@ module flamingo.me/flamingo/v3
 
 require (
 	flamingo.me/dingo v0.1.3
-	github.com/BurntSushi/toml v0.3.1 // indirect
+	github.com/BurntSushi/toml v0.3.1
+	github.com/alicebob/miniredis/v2 v2.30.0
 	github.com/boj/redistore v0.0.0-20160128113310-fc113767cd6b
 	github.com/coreos/go-oidc v2.0.0+incompatible
 	github.com/dgrijalva/jwt-go v3.2.0+incompatible
@@ -10,6 +11,7 @@ require (
 	github.com/ghodss/yaml v1.0.0
 	github.com/golang/groupcache v0.0.0-20180513044358-24b0969c4cb7
 	github.com/gomodule/redigo v2.0.0+incompatible
+	github.com/gorilla/securecookie v1.1.1
 	github.com/gorilla/sessions v1.1.3
 	github.com/hashicorp/golang-lru v0.5.0
 	github.com/hashicorp/logutils v0.0.0-20150609070431-0dc08b1671f3 // indirect
@@ -34,6 +36,7 @@ require (
 	go.uber.org/atomic v1.3.2 // indirect
 	go.uber.org/multierr v1.1.0 // indirect
 	go.uber.org/zap v1.9.1
+	golang.org/x/crypto v0.0.0-20180904163835-0709b304e793
 	golang.org/x/oauth2 v0.0.0-20190212230446-3e8b2be13635
 	gopkg.in/square/go-jose.v2 v2.1.9 // indirect
 )